@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"pump_auto/internal/config"
+	"pump_auto/internal/wallet"
+
+	"golang.org/x/term"
+)
+
+// 将 Solana CLI keypair 文件加密为 keystore 文件
+// 用法: go run ./cmd/keystore -in ~/.config/solana/id.json -out wallet.keystore.json
+func main() {
+	in := flag.String("in", "", "Solana CLI keypair 文件路径 (id.json)")
+	out := flag.String("out", "wallet.keystore.json", "输出的 keystore 文件路径")
+	flag.Parse()
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "必须通过 -in 指定 keypair 文件")
+		os.Exit(2)
+	}
+
+	w, err := wallet.LoadKeypairFile(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载keypair失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	passphrase, err := readPassphrase()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取口令失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := wallet.EncryptKeystoreFromWallet(w, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加密失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, data, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "写入keystore失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("已生成keystore: %s (地址 %s)\n", *out, w.PublicKey())
+}
+
+// readPassphrase 优先读取环境变量，否则在终端中输入两次
+func readPassphrase() (string, error) {
+	if v := os.Getenv(config.EnvKeystorePassphrase); v != "" {
+		return v, nil
+	}
+
+	fmt.Fprint(os.Stderr, "输入口令: ")
+	first, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "再次输入口令: ")
+	second, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	return string(first), nil
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"flag"
 	"os"
 	"os/signal"
 	"pump_auto/internal/bot"
+	"pump_auto/internal/chainTx"
 	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/execctor"
 	"pump_auto/internal/queue"
+	"pump_auto/internal/trader"
+	"pump_auto/internal/wallet"
 	"syscall"
 	// "pump_auto/internal/ui" // Placeholder for a more complex CLI menu if needed
 )
 
 func main() {
+	configPath := flag.String("config", "config.json", "配置文件路径")
+	flag.Parse()
+
 	// 设置日志级别
 	// 可以通过环境变量控制日志级别
 	logLevel := os.Getenv("LOG_LEVEL")
@@ -20,12 +29,48 @@ func main() {
 	common.SetLogLevel(logLevel)
 	common.Log.Info("日志系统初始化完成")
 
+	// 加载运行时配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		common.Log.WithError(err).Fatal("加载配置失败")
+	}
+	if err := execctor.CheckStrategies(cfg); err != nil {
+		common.Log.WithError(err).Fatal("加载配置失败")
+	}
+	w, err := wallet.Load(cfg.Wallet.KeypairPath, cfg.Wallet.KeystorePath, cfg.Wallet.Passphrase)
+	if err != nil {
+		common.Log.WithError(err).Fatal("加载钱包失败")
+	}
+	common.Log.Infof("钱包地址: %s", w.PublicKey())
+	if err := chainTx.Init(cfg, w); err != nil {
+		common.Log.WithError(err).Fatal("初始化交易模块失败")
+	}
+	common.Log.Infof("配置加载完成: %s", *configPath)
+
 	// 初始化消息队列
 	queue.InitGlobalQueues()
 	common.Log.Info("消息队列系统已初始化")
 
+	// 选择下单执行器
+	var executor trader.Executor = trader.NewLive()
+	if cfg.Trade.Mode == config.ModePaper {
+		executor = trader.NewPaper(cfg.Paper.InitialSol, nil)
+		common.Log.Infof("模拟盘模式，初始SOL余额: %v", cfg.Paper.InitialSol)
+	}
+
+	// 关闭钱包中遗留的空代币账户，回收租金
+	if cfg.Trade.Mode == config.ModeLive && cfg.Janitor.SweepOnStart {
+		go func() {
+			closed, reclaimed, err := chainTx.SweepTokenAccounts()
+			if err != nil {
+				common.Log.WithError(err).Warn("清理空代币账户失败")
+			}
+			common.Log.Infof("已关闭 %d 个空代币账户，回收租金 %s SOL", closed, reclaimed)
+		}()
+	}
+
 	// 初始化bot
-	sniperBot := bot.NewBot()
+	sniperBot := bot.NewBot(cfg, executor)
 
 	// 启动主要bot逻辑（例如，监听器）
 	go func() {
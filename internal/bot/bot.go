@@ -3,14 +3,21 @@ package bot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"pump_auto/internal/budget"
 	"pump_auto/internal/chainTx"
 	"pump_auto/internal/common"
+	"pump_auto/internal/config"
 	"pump_auto/internal/execctor"
+	"pump_auto/internal/fee"
 	"pump_auto/internal/model"
+	"pump_auto/internal/pump"
+	"pump_auto/internal/trader"
 	"pump_auto/internal/ws"
 	"sync"
 	"time"
@@ -45,20 +52,26 @@ type Bot struct {
 	workerPool    chan struct{}           // 工作池通道，用于限制并发工作线程数
 	workerWg      sync.WaitGroup          // 等待组，用于等待所有工作线程完成
 	tradeExecutor *execctor.TradeExecutor // 交易执行器
-	heldTokens    map[string]chan bool    // 修改为map[string]chan bool，用于存储代币的心跳通道
+	heldTokens    map[string]struct{}     // 持有的代币，超时卖出由交易执行器的策略按时间规则处理
+	cfg           *config.Config          // 运行时配置
+	executor      trader.Executor         // 下单执行器，实盘或模拟盘
+	budget        *budget.Manager         // 买入前的余额和敞口检查
 }
 
 // 创建新的Bot实例
-func NewBot() *Bot {
+func NewBot(cfg *config.Config, executor trader.Executor) *Bot {
 	ctx, cancel := context.WithCancel(context.Background())
 	b := &Bot{
+		cfg:        cfg,
+		executor:   executor,
 		stopChan:   make(chan struct{}),
 		ctx:        ctx,
 		cancelFunc: cancel,
-		workerPool: make(chan struct{}, 1),     // 修改此处，创建容量为2的工作池
-		heldTokens: make(map[string]chan bool), // 初始化持有的代币
+		workerPool: make(chan struct{}, 1),    // 修改此处，创建容量为2的工作池
+		heldTokens: make(map[string]struct{}), // 初始化持有的代币
+		budget:     budget.NewManager(cfg, executor.SolBalance),
 	}
-	b.tradeExecutor = execctor.NewTradeExecutor(b.RemoveHeldToken) // 创建交易执行器并传入回调
+	b.tradeExecutor = execctor.NewTradeExecutor(cfg, executor, b.RemoveHeldToken) // 创建交易执行器并传入回调
 	return b
 }
 
@@ -108,7 +121,7 @@ func (b *Bot) RunListener() error {
 				}
 
 				// 检查是否是超时错误
-				if err.Error() == "i/o timeout" || err.Error() == "read tcp: i/o timeout" {
+				if isTimeout(err) {
 					consecutiveTimeouts++
 					if consecutiveTimeouts >= maxConsecutiveTimeouts {
 						log.Printf("连续超时次数过多: %d/%d, 将重新连接", consecutiveTimeouts, maxConsecutiveTimeouts)
@@ -144,19 +157,17 @@ func (b *Bot) RunListener() error {
 
 				// 如果是交易记录消息(buy或sell)，则转发给交易执行器更新价格
 				if tokenEvent.TxType == "buy" || tokenEvent.TxType == "sell" {
+					// 更新联合曲线快照，供本地组装交易使用
+					pump.ObserveReserves(tokenEvent.Mint, tokenEvent.VSolInBondingCurve, tokenEvent.VTokensInBondingCurve)
+
 					go func(msg []byte) {
 						b.tradeExecutor.ProcessTradeMessage(msg)
 					}(message)
+				}
 
-					// 检查是否是持有的代币的交易消息
-					if ch := b.getTokenChannel(tokenEvent.Mint); ch != nil {
-						select {
-						case ch <- true:
-							// 成功发送心跳
-						case <-b.ctx.Done():
-							// 上下文已取消，忽略
-						}
-					}
+				// 代币迁移事件(txType=migrate)，持仓代币改走迁移后的池子
+				if tokenEvent.TxType == "migrate" {
+					go b.tradeExecutor.OnMigration(tokenEvent.Mint, common.PoolType(tokenEvent.Pool), pump.MigrationFromEvent)
 				}
 
 				// 检查是否是新代币创建事件(txType=create)
@@ -167,7 +178,7 @@ func (b *Bot) RunListener() error {
 					heldTokensCount := len(b.heldTokens)
 					b.mutex.Unlock()
 
-					if heldTokensCount >= common.MAX_HOLD_TOKEN {
+					if heldTokensCount >= b.cfg.Bot.MaxHoldToken {
 						log.Printf("当前已持有 %d 个代币，暂停处理新代币创建事件", heldTokensCount)
 						continue
 					}
@@ -183,6 +194,11 @@ func (b *Bot) RunListener() error {
 						"symbol": tokenEvent.Symbol,
 					}
 
+					// 记录创建者和初始储备，供本地组装交易使用
+					if err := pump.ObserveCreate(tokenEvent.Mint, tokenEvent.TraderPublicKey, tokenEvent.VSolInBondingCurve, tokenEvent.VTokensInBondingCurve); err != nil {
+						log.Printf("记录代币 %s 联合曲线状态失败: %v", tokenEvent.Mint, err)
+					}
+
 					// 在协程中处理，避免阻塞主消息循环
 					go b.processNewToken(tokenData)
 				}
@@ -289,22 +305,22 @@ func (b *Bot) processNewToken(data map[string]interface{}) {
 
 		// 打印筛选结果
 		if result.IsFiltered {
-			log.Printf("代币 %s (%s) 被过滤器拦截，原因: %v", tokenAddress, metadata.Name, result.FilteredBy)
+			reportFiltered(result)
 			return
 		} else {
 			log.Printf("代币 %s (%s) 满足筛选条件，准备购买", tokenAddress, metadata.Name)
 			req := &common.TradeReq{
 				Action:           "buy",
 				Mint:             tokenAddress,
-				Amount:           0.001, // This is SOL amount
+				Amount:           b.cfg.Buy.AmountSol, // This is SOL amount
 				DenominatedInSol: true,
-				Slippage:         10,
-				PriorityFee:      0.0005,
-				Pool:             common.PUMP,
+				Slippage:         b.cfg.Buy.Slippage,
+				PriorityFee:      b.cfg.Buy.PriorityFee,
+				Pool:             b.cfg.Buy.Pool,
 			}
 
 			// metadata.Symbol 应该存在于 model.TokenMetadata 中
-			_, err := b.buyToken(req.Mint, req.Amount, req.DenominatedInSol, req.Slippage, req.PriorityFee, req.Pool)
+			_, err := b.buyToken(req.Mint, model.SOLToLamports(req.Amount), req.Slippage, req.PriorityFee, req.Pool)
 			if err != nil {
 				log.Printf("购买代币 %s 失败,error: %v", tokenAddress, err)
 			}
@@ -317,106 +333,153 @@ func (b *Bot) processNewToken(data map[string]interface{}) {
 	log.Printf("工作线程完成处理代币: %s", tokenAddress)
 }
 
-// 添加新的辅助方法
-func (b *Bot) getTokenChannel(mint string) chan bool {
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
-	return b.heldTokens[mint]
-}
-
-func (b *Bot) waitForTradeAndSellIfTimeout(mint string) {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-b.getTokenChannel(mint):
-			// 收到交易消息，重置计时器
-			ticker.Reset(30 * time.Second)
-		case <-ticker.C:
-			// 每30秒检查一次
-			log.Printf("代币 %s 在30秒内没有收到交易消息，执行卖出", mint)
-			_, err := chainTx.SellToken(mint, 1, "100%", false, 20, 0.0005, common.PUMP)
-			if err != nil {
-				log.Printf("卖出代币 %s 失败: %v", mint, err)
-			}
-			b.RemoveHeldToken(mint)
-			return
-		case <-b.ctx.Done():
-			b.RemoveHeldToken(mint)
-			return
-		}
-	}
-}
-
 // 修改buyToken方法
-func (b *Bot) buyToken(mint string, amount float64, denominatedInSol bool, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
+func (b *Bot) buyToken(mint string, amount model.Lamports, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
 	b.mutex.Lock()
-	if len(b.heldTokens) >= common.MAX_HOLD_TOKEN {
+	if len(b.heldTokens) >= b.cfg.Bot.MaxHoldToken {
 		b.mutex.Unlock()
-		log.Printf("已持有最大数量的代币 ()，无法购买新的代币 %s", mint)
-		return "", fmt.Errorf("已持有最大数量的代币 (2)，无法购买新的代币 %s", mint)
+		log.Printf("已持有最大数量的代币 (%d)，无法购买新的代币 %s", b.cfg.Bot.MaxHoldToken, mint)
+		return "", fmt.Errorf("已持有最大数量的代币 (%d)，无法购买新的代币 %s", b.cfg.Bot.MaxHoldToken, mint)
 	}
 	b.mutex.Unlock()
-	time.Sleep(10 * time.Second)
 
-	var sign string
-	var err error
+	if ws.GetGlobalWS() == nil {
+		return "", fmt.Errorf("WebSocket连接未建立，无法购买代币 %s", mint)
+	}
 
-	// 开始重试循环
-	sign, err = chainTx.BuyToken(mint, amount, denominatedInSol, slippage, priorityFee, pool)
+	// 检查余额和总敞口，拒绝结果转换为过滤结果，与过滤器拦截走同一条处理
+	check := b.budget.Reserve(budget.BuyRequest(b.cfg, mint, amount, slippage, priorityFee))
+	if check.IsRefused {
+		reportFiltered(check.FilterResult())
+		return "", fmt.Errorf("预算检查未通过，无法购买代币 %s: %v (余额=%s 预留=%s 买入后敞口=%s SOL)",
+			mint, check.RefusedBy, check.Balance, check.Reserved, check.Exposure)
+	}
+	time.Sleep(10 * time.Second)
 
+	fill, err := b.executor.Buy(trader.Order{
+		Mint:        mint,
+		Sol:         amount,
+		Slippage:    slippage,
+		PriorityFee: priorityFee,
+		Pool:        pool,
+		Urgency:     fee.UrgencySnipe,
+	})
+	if err != nil && fill != nil {
+		// 交易已上链但解析失败，以链上余额为准
+		fill, err = b.recoverFill(mint, fill, err)
+	}
 	if err != nil {
-		log.Printf("购买代币 %s 失败，已达到最大重试次数: %v", mint, err)
+		b.budget.Release(mint)
+		switch {
+		case errors.Is(err, chainTx.ErrTokenMigrated):
+			log.Printf("代币 %s 已迁移出联合曲线，放弃买入", mint)
+		case errors.Is(err, chainTx.ErrSlippageExceeded):
+			log.Printf("代币 %s 价格变动超出滑点 %d%%，放弃买入", mint, slippage)
+		case errors.Is(err, chainTx.ErrInsufficientSOL):
+			log.Printf("SOL余额不足，无法买入代币 %s: %v", mint, err)
+		default:
+			log.Printf("购买代币 %s 失败: %v", mint, err)
+		}
 		return "", err
 	}
+	b.budget.Commit(mint, fill.Principal)
 
-	wsConn := ws.GetGlobalWS()
-	if wsConn == nil {
-		return "", fmt.Errorf("WebSocket连接未建立，无法购买代币 %s", mint)
-	}
-
-	// 创建心跳通道并存储
+	// 记录持有的代币
 	b.mutex.Lock()
-	b.heldTokens[mint] = make(chan bool, 1)
+	b.heldTokens[mint] = struct{}{}
 	b.mutex.Unlock()
 
-	txSig := solana.MustSignatureFromBase58(sign)
-	outAmount, err := chainTx.ParseTxSign(txSig)
-	if err != nil {
-		log.Printf("获取代币 %s 余额失败: %v", mint, err)
-		return "", fmt.Errorf("获取代币余额失败: %v", err)
-	}
-	TokenBalance, err := chainTx.GetTokenBalance(mint)
+	sign, outAmount := fill.Signature, fill.Tokens
+	TokenBalance, err := b.executor.TokenBalance(mint)
 	if err != nil || outAmount != TokenBalance {
 		log.Printf("获取代币 %s 余额失败: %v,执行卖出", mint, err)
-		_, _ = chainTx.SellToken(mint, 1, "100%", false, 20, 0.0005, common.PUMP)
+		if sellErr := b.sellAll(mint); sellErr != nil {
+			log.Printf("清仓代币 %s 失败: %v", mint, sellErr)
+		}
+		b.RemoveHeldToken(mint)
 		return "", fmt.Errorf("获取代币余额失败: %v,中断该代币的执行", err)
 	}
-	log.Printf("购买后代币 %s 余额: %f", mint, outAmount)
+	log.Printf("购买后代币 %s 余额: %s", mint, outAmount)
 
-	err = ws.SubscribeToTokenTrades([]string{mint})
-	if err == nil {
-		b.mutex.Lock()
-		b.mutex.Unlock()
-		log.Printf("成功购买代币 %s 并添加到持有列表", mint)
+	if err := ws.SubscribeToTokenTrades([]string{mint}); err != nil {
+		// 收不到成交推送就无法跟踪价格和退出，立即清仓
+		log.Printf("订阅代币 %s 交易失败: %v,执行卖出", mint, err)
+		if sellErr := b.sellAll(mint); sellErr != nil {
+			log.Printf("清仓代币 %s 失败: %v", mint, sellErr)
+		}
+		b.RemoveHeldToken(mint)
+		return sign, err
+	}
+	log.Printf("成功购买代币 %s 并添加到持有列表", mint)
+
+	// 入场价按实际成交的SOL计算，而不是请求的买入金额；成交结果缺少花费时才退回请求金额
+	spent := fill.Sol
+	if spent == 0 {
+		spent = amount
+	}
+	b.tradeExecutor.ExpectBuyForToken(mint, spent, outAmount)
+	return sign, nil
+}
 
-		// 'amount' is the SOL amount intended to be spent
-		b.tradeExecutor.ExpectBuyForToken(mint, amount, outAmount)
+// recoverFill 买入交易已上链但解析失败时，用链上代币余额补全成交结果，余额为零或查询失败视为未成交
+func (b *Bot) recoverFill(mint string, fill *trader.Fill, cause error) (*trader.Fill, error) {
+	balance, err := b.executor.TokenBalance(mint)
+	if err != nil {
+		return nil, fmt.Errorf("%w，且查询代币余额失败: %v", cause, err)
+	}
+	if balance.IsZero() {
+		return nil, cause
+	}
+	log.Printf("买入交易 %s 解析失败，按链上余额 %s 记录持仓: %v", fill.Signature, balance, cause)
+	return &trader.Fill{Signature: fill.Signature, Tokens: balance, Sol: fill.Sol}, nil
+}
+
+// reportFiltered 输出被拦截的代币，元数据过滤器和预算检查的拦截结果都经过这里
+func reportFiltered(result *analyzer.FilterResult) {
+	name := ""
+	if result.Metadata != nil {
+		name = result.Metadata.Name
+	}
+	log.Printf("代币 %s (%s) 被过滤器拦截，原因: %v", result.TokenAddress, name, result.FilteredBy)
+}
+
+// isTimeout 判断读取错误是否为超时
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// sellAll 全部卖出，联合曲线已完成时标记迁移并在迁移后的池子重试一次
+func (b *Bot) sellAll(mint string) error {
+	_, err := b.executor.Sell(b.sellAllOrder(mint))
+	if errors.Is(err, chainTx.ErrTokenMigrated) && pump.MarkMigrated(mint, "", pump.MigrationFromCurve) {
+		log.Printf("代币 %s 已迁移出联合曲线，改用 %s 卖出", mint, b.tradeExecutor.SellPool(mint))
+		_, err = b.executor.Sell(b.sellAllOrder(mint))
+	}
+	return err
+}
 
-		// 启动超时检查
-		go b.waitForTradeAndSellIfTimeout(mint)
+// sellAllOrder 按配置的卖出参数全部卖出，用于超时和异常清仓，已迁移的代币走迁移后的池子
+func (b *Bot) sellAllOrder(mint string) trader.Order {
+	return trader.Order{
+		Mint:        mint,
+		SellPercent: "100%",
+		Slippage:    b.cfg.Sell.Slippage,
+		PriorityFee: b.cfg.Sell.PriorityFee,
+		Pool:        b.tradeExecutor.SellPool(mint),
+		Urgency:     fee.UrgencyEmergency,
 	}
-	return sign, err
 }
 
 // RemoveHeldToken 从持有代币列表中移除代币
 func (b *Bot) RemoveHeldToken(tokenAddress string) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
-	if ch, exists := b.heldTokens[tokenAddress]; exists {
-		close(ch) // 关闭通道
+	if _, exists := b.heldTokens[tokenAddress]; exists {
 		delete(b.heldTokens, tokenAddress)
+		pump.ForgetSnapshot(tokenAddress)
+		pump.ForgetMigration(tokenAddress)
+		b.budget.Release(tokenAddress)
 		log.Printf("代币 %s 已从持有列表移除", tokenAddress)
 
 		// 取消WebSocket订阅
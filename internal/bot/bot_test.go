@@ -1,49 +1,75 @@
 package bot
 
 import (
+	"os"
+	"pump_auto/internal/chainTx"
 	"pump_auto/internal/common"
+	"pump_auto/internal/config"
 	"pump_auto/internal/model"
+	"pump_auto/internal/trader"
+	"pump_auto/internal/wallet"
 	"testing"
 )
 
+// envLiveConfig 连接主网的测试使用的配置文件路径，未设置时跳过这些测试
+const envLiveConfig = "PUMP_LIVE_CONFIG"
+
+// liveConfig 按 PUMP_LIVE_CONFIG 加载配置并初始化交易模块，未设置时跳过测试
+func liveConfig(t *testing.T) *config.Config {
+	t.Helper()
+	path := os.Getenv(envLiveConfig)
+	if path == "" {
+		t.Skipf("未设置 %s，跳过连接主网的测试", envLiveConfig)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	w, err := wallet.Load(cfg.Wallet.KeypairPath, cfg.Wallet.KeystorePath, cfg.Wallet.Passphrase)
+	if err != nil {
+		t.Fatalf("加载钱包失败: %v", err)
+	}
+	if err := chainTx.Init(cfg, w); err != nil {
+		t.Fatalf("chainTx.Init() error = %v", err)
+	}
+	return cfg
+}
+
 func TestBot_buyToken(t *testing.T) {
-	b := NewBot()
+	b := NewBot(liveConfig(t), trader.NewLive())
 
 	tests := []struct {
-		name             string
-		mint             string
-		amount           float64
-		denominatedInSol bool
-		slippage         int
-		priorityFee      float64
-		pool             common.PoolType
-		wantErr          bool
+		name        string
+		mint        string
+		amount      float64
+		slippage    int
+		priorityFee float64
+		pool        common.PoolType
+		wantErr     bool
 	}{
 		{
-			name:             "正常买入测试",
-			mint:             "7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump", // SOL主网mint
-			amount:           0.00100,
-			denominatedInSol: true,
-			slippage:         10,
-			priorityFee:      0.0005,
-			pool:             common.PUMP,
-			wantErr:          false,
+			name:        "正常买入测试",
+			mint:        "7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump", // SOL主网mint
+			amount:      0.00100,
+			slippage:    10,
+			priorityFee: 0.0005,
+			pool:        common.PUMP,
+			wantErr:     false,
 		},
 		{
-			name:             "无效mint测试",
-			mint:             "invalid_mint_address",
-			amount:           0.01,
-			denominatedInSol: true,
-			slippage:         10,
-			priorityFee:      0.0005,
-			pool:             common.PUMP,
-			wantErr:          true,
+			name:        "无效mint测试",
+			mint:        "invalid_mint_address",
+			amount:      0.01,
+			slippage:    10,
+			priorityFee: 0.0005,
+			pool:        common.PUMP,
+			wantErr:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sign, err := b.buyToken(tt.mint, tt.amount, tt.denominatedInSol, tt.slippage, tt.priorityFee, tt.pool)
+			sign, err := b.buyToken(tt.mint, model.SOLToLamports(tt.amount), tt.slippage, tt.priorityFee, tt.pool)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buyToken() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -56,6 +82,9 @@ func TestBot_buyToken(t *testing.T) {
 }
 
 func TestFetchMetadata(t *testing.T) {
+	if os.Getenv(envLiveConfig) == "" {
+		t.Skipf("未设置 %s，跳过访问 IPFS 的测试", envLiveConfig)
+	}
 	tests := []struct {
 		name    string
 		uri     string
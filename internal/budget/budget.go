@@ -0,0 +1,181 @@
+package budget
+
+import (
+	"pump_auto/internal/analyzer"
+	"pump_auto/internal/config"
+	"pump_auto/internal/inspect"
+	"pump_auto/internal/model"
+	"sync"
+	"time"
+)
+
+// 拒绝买入的原因，与过滤器名称一样写入 RefusedBy
+const (
+	ReasonBalanceUnknown = "BalanceUnknown" // 查询SOL余额失败
+	ReasonMinBalance     = "MinBalance"     // 买入后余额低于最低保留
+	ReasonMaxExposure    = "MaxExposure"    // 持仓成本总额超过上限
+	ReasonDuplicate      = "AlreadyHeld"    // 该代币已有持仓或正在买入
+)
+
+// BaseFee 每个签名的基础手续费
+const BaseFee model.Lamports = 5_000
+
+// BalanceFunc 查询钱包当前的SOL余额，实盘和模拟盘的执行器都提供该方法
+type BalanceFunc func() (model.Lamports, error)
+
+// Request 一笔待检查的买入
+type Request struct {
+	Mint    string
+	Sol     model.Lamports // 买入本金，计入持仓成本
+	MaxCost model.Lamports // 最多花费的SOL: 含滑点的本金、优先费、基础费和代币账户租金
+}
+
+// Result 预算检查结果，格式与过滤器的 FilterResult 对应
+type Result struct {
+	Mint      string
+	Balance   model.Lamports // 钱包当前SOL余额
+	Reserved  model.Lamports // 买入中的占用和所有持仓(含本笔)的退出预留
+	Exposure  model.Lamports // 本笔买入后的持仓成本总额
+	IsRefused bool
+	RefusedBy []string
+	CheckTime time.Time
+}
+
+// FilterResult 转换为过滤结果，拒绝原因写入 FilteredBy，与过滤器的拦截结果一起处理
+func (r *Result) FilterResult() *analyzer.FilterResult {
+	return &analyzer.FilterResult{
+		TokenAddress: r.Mint,
+		IsFiltered:   r.IsRefused,
+		FilteredBy:   r.RefusedBy,
+		AnalysisTime: r.CheckTime,
+	}
+}
+
+// Manager 跟踪持仓占用的资金，买入前检查余额和总敞口
+type Manager struct {
+	balance     BalanceFunc
+	minBalance  model.Lamports // 买入并预留退出费用后至少保留的余额
+	maxExposure model.Lamports // 持仓成本总额上限，0 表示不限制
+	exitReserve model.Lamports // 每个持仓预留的退出交易费用
+
+	mu        sync.Mutex
+	positions map[string]*position
+}
+
+// position 一个持仓占用的资金
+type position struct {
+	cost    model.Lamports // 计入敞口的本金，成交后改为实际本金
+	pending model.Lamports // 买入确认前占用的最多花费，确认后已从余额中扣除
+}
+
+// NewManager 按配置创建预算管理器
+func NewManager(cfg *config.Config, balance BalanceFunc) *Manager {
+	return &Manager{
+		balance:     balance,
+		minBalance:  model.SOLToLamports(cfg.Budget.MinBalanceSol),
+		maxExposure: model.SOLToLamports(cfg.Budget.MaxExposureSol),
+		exitReserve: model.Lamports(cfg.Budget.ExitTransactions) * TxFee(cfg, cfg.Sell.PriorityFee),
+		positions:   make(map[string]*position),
+	}
+}
+
+// TxFee 按配置估算一笔交易的最高费用: 优先费(开启动态优先费时取上限)、基础费和 jito 小费
+func TxFee(cfg *config.Config, priorityFee float64) model.Lamports {
+	if cfg.Fee.Dynamic && cfg.Fee.MaxSol > priorityFee {
+		priorityFee = cfg.Fee.MaxSol
+	}
+	total := model.SOLToLamports(priorityFee) + BaseFee
+	if cfg.Jito.Enabled {
+		total += model.SOLToLamports(cfg.Jito.TipSol)
+	}
+	return total
+}
+
+// BuyRequest 按配置组装买入请求，最多花费包含滑点、买入交易费用和代币账户租金
+func BuyRequest(cfg *config.Config, mint string, sol model.Lamports, slippage int, priorityFee float64) Request {
+	return Request{
+		Mint:    mint,
+		Sol:     sol,
+		MaxCost: sol*model.Lamports(100+slippage)/100 + TxFee(cfg, priorityFee) + model.Lamports(inspect.AssociatedTokenRent),
+	}
+}
+
+// Reserve 检查买入是否满足余额和敞口限制，通过时占用额度
+// 通过后买入失败或清仓时需调用 Release，买入成交后调用 Commit
+func (m *Manager) Reserve(req Request) *Result {
+	result := &Result{
+		Mint:      req.Mint,
+		RefusedBy: []string{},
+		CheckTime: time.Now(),
+	}
+
+	// 查询余额期间持有锁，避免并发买入同时通过检查
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.positions[req.Mint]; ok {
+		return refuse(result, ReasonDuplicate)
+	}
+	balance, err := m.balance()
+	if err != nil {
+		return refuse(result, ReasonBalanceUnknown)
+	}
+	result.Balance = balance
+
+	var exposure model.Lamports
+	reserved := m.exitReserve * model.Lamports(len(m.positions)+1)
+	for _, p := range m.positions {
+		exposure += p.cost
+		reserved += p.pending
+	}
+	result.Reserved = reserved
+	result.Exposure = exposure + req.Sol
+
+	if balance < reserved+req.MaxCost+m.minBalance {
+		refuse(result, ReasonMinBalance)
+	}
+	if m.maxExposure > 0 && result.Exposure > m.maxExposure {
+		refuse(result, ReasonMaxExposure)
+	}
+	if !result.IsRefused {
+		m.positions[req.Mint] = &position{cost: req.Sol, pending: req.MaxCost}
+	}
+	return result
+}
+
+func refuse(result *Result, reason string) *Result {
+	result.IsRefused = true
+	result.RefusedBy = append(result.RefusedBy, reason)
+	return result
+}
+
+// Commit 买入成交后用实际本金更新持仓成本，principal 与 Reserve 时的 Request.Sol 口径一致，
+// 不含代币账户租金和手续费；为0时保留预估的本金
+func (m *Manager) Commit(mint string, principal model.Lamports) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.positions[mint]; ok {
+		if principal > 0 {
+			p.cost = principal
+		}
+		p.pending = 0
+	}
+}
+
+// Release 释放代币占用的额度
+func (m *Manager) Release(mint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.positions, mint)
+}
+
+// Exposure 当前所有持仓的成本总额
+func (m *Manager) Exposure() model.Lamports {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total model.Lamports
+	for _, p := range m.positions {
+		total += p.cost
+	}
+	return total
+}
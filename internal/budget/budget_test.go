@@ -0,0 +1,155 @@
+package budget
+
+import (
+	"errors"
+	"pump_auto/internal/config"
+	"pump_auto/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestReserve(t *testing.T) {
+	cfg := config.Default()
+	cfg.Sell.PriorityFee = 0.0005
+	cfg.Budget.MinBalanceSol = 0.01
+	cfg.Budget.MaxExposureSol = 0.25
+	cfg.Budget.ExitTransactions = 2
+	exitReserve := 2 * (model.SOLToLamports(0.0005) + BaseFee)
+
+	tests := []struct {
+		name       string
+		balance    model.Lamports
+		balanceErr error
+		held       []Request // 已通过检查的买入
+		committed  bool      // 已通过的买入是否已成交
+		req        Request
+		want       []string
+	}{
+		{
+			name:    "余额充足",
+			balance: model.SOLToLamports(1),
+			req:     Request{Mint: "a", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.12)},
+			want:    []string{},
+		},
+		{
+			name:    "恰好保留最低余额",
+			balance: model.SOLToLamports(0.13) + exitReserve,
+			req:     Request{Mint: "a", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.12)},
+			want:    []string{},
+		},
+		{
+			name:    "退出预留后低于最低余额",
+			balance: model.SOLToLamports(0.13) + exitReserve - 1,
+			req:     Request{Mint: "a", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.12)},
+			want:    []string{ReasonMinBalance},
+		},
+		{
+			name:    "买入中的占用计入预留",
+			balance: model.SOLToLamports(0.3),
+			held:    []Request{{Mint: "a", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.15)}},
+			req:     Request{Mint: "b", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.15)},
+			want:    []string{ReasonMinBalance},
+		},
+		{
+			name:      "成交后只保留退出预留",
+			balance:   model.SOLToLamports(0.3),
+			held:      []Request{{Mint: "a", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.15)}},
+			committed: true,
+			req:       Request{Mint: "b", Sol: model.SOLToLamports(0.1), MaxCost: model.SOLToLamports(0.15)},
+			want:      []string{},
+		},
+		{
+			name:      "超过总敞口",
+			balance:   model.SOLToLamports(10),
+			held:      []Request{{Mint: "a", Sol: model.SOLToLamports(0.1)}, {Mint: "b", Sol: model.SOLToLamports(0.1)}},
+			committed: true,
+			req:       Request{Mint: "c", Sol: model.SOLToLamports(0.1)},
+			want:      []string{ReasonMaxExposure},
+		},
+		{
+			name:    "余额和敞口同时不满足",
+			balance: model.SOLToLamports(0.01),
+			req:     Request{Mint: "a", Sol: model.SOLToLamports(0.3), MaxCost: model.SOLToLamports(0.3)},
+			want:    []string{ReasonMinBalance, ReasonMaxExposure},
+		},
+		{
+			name:    "重复买入",
+			balance: model.SOLToLamports(10),
+			held:    []Request{{Mint: "a", Sol: model.SOLToLamports(0.1)}},
+			req:     Request{Mint: "a", Sol: model.SOLToLamports(0.1)},
+			want:    []string{ReasonDuplicate},
+		},
+		{
+			name:       "余额查询失败",
+			balanceErr: errors.New("rpc unavailable"),
+			req:        Request{Mint: "a", Sol: model.SOLToLamports(0.1)},
+			want:       []string{ReasonBalanceUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance := model.SOLToLamports(100)
+			m := NewManager(cfg, func() (model.Lamports, error) { return balance, nil })
+			for _, req := range tt.held {
+				if result := m.Reserve(req); result.IsRefused {
+					t.Fatalf("已持仓 %s 未通过检查: %v", req.Mint, result.RefusedBy)
+				}
+				if tt.committed {
+					m.Commit(req.Mint, 0)
+				}
+			}
+			m.balance = func() (model.Lamports, error) { return tt.balance, tt.balanceErr }
+
+			result := m.Reserve(tt.req)
+			if !reflect.DeepEqual(result.RefusedBy, tt.want) || result.IsRefused != (len(tt.want) > 0) {
+				t.Errorf("Reserve() = %v (IsRefused=%v), want %v", result.RefusedBy, result.IsRefused, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	cfg := config.Default()
+	cfg.Budget.MaxExposureSol = 0.1
+	m := NewManager(cfg, func() (model.Lamports, error) { return model.SOLToLamports(10), nil })
+
+	req := Request{Mint: "a", Sol: model.SOLToLamports(0.1)}
+	if result := m.Reserve(req); result.IsRefused {
+		t.Fatalf("Reserve() 被拒绝: %v", result.RefusedBy)
+	}
+	m.Commit("a", model.SOLToLamports(0.09))
+	if got := m.Exposure(); got != model.SOLToLamports(0.09) {
+		t.Errorf("成交后 Exposure() = %s, want 0.09", got)
+	}
+	if result := m.Reserve(Request{Mint: "b", Sol: model.SOLToLamports(0.1)}); !result.IsRefused {
+		t.Errorf("持仓未释放时应超过总敞口")
+	}
+	m.Release("a")
+	if result := m.Reserve(Request{Mint: "b", Sol: model.SOLToLamports(0.1)}); result.IsRefused {
+		t.Errorf("释放后 Reserve() 被拒绝: %v", result.RefusedBy)
+	}
+}
+
+func TestFilterResult(t *testing.T) {
+	cfg := config.Default()
+	cfg.Budget.MinBalanceSol = 1
+	m := NewManager(cfg, func() (model.Lamports, error) { return model.SOLToLamports(0.5), nil })
+
+	result := m.Reserve(Request{Mint: "a", Sol: model.SOLToLamports(0.1)}).FilterResult()
+	if result.TokenAddress != "a" || !result.IsFiltered || !reflect.DeepEqual(result.FilteredBy, []string{ReasonMinBalance}) {
+		t.Errorf("FilterResult() = %+v", result)
+	}
+}
+
+func TestBuyRequest(t *testing.T) {
+	cfg := config.Default()
+	cfg.Jito.Enabled = true
+	cfg.Jito.TipSol = 0.0001
+
+	req := BuyRequest(cfg, "a", model.SOLToLamports(0.1), 10, 0.0005)
+	want := model.SOLToLamports(0.11) + model.SOLToLamports(0.0005) + BaseFee + model.SOLToLamports(0.0001) + 2_039_280
+	if req.Sol != model.SOLToLamports(0.1) || req.MaxCost != want {
+		t.Errorf("BuyRequest() = %+v, want MaxCost %s", req, want)
+	}
+}
@@ -0,0 +1,203 @@
+package candle
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Trade 参与聚合的一笔成交
+type Trade struct {
+	Time   time.Time
+	Price  float64 // 成交后的价格
+	Sol    float64 // 成交的SOL数量
+	Tokens float64 // 成交的代币数量
+	IsBuy  bool
+	Trader string // 交易地址，为空时不计入独立地址数
+}
+
+// Candle 一个周期内的成交汇总，没有成交的周期开高低收都等于上一根的收盘价
+type Candle struct {
+	Start       time.Time // 周期开始时间
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64 // SOL 成交额
+	TokenVolume float64 // 代币成交量
+	Buys        int     // 买入笔数
+	Sells       int     // 卖出笔数
+	Traders     int     // 不同交易地址数
+
+	traders map[string]struct{} // 只有最新一根保留，收盘后释放
+}
+
+// apply 将成交计入K线
+func (c *Candle) apply(t Trade) {
+	if t.Price > c.High {
+		c.High = t.Price
+	}
+	if t.Price < c.Low {
+		c.Low = t.Price
+	}
+	c.Close = t.Price
+	c.Volume += t.Sol
+	c.TokenVolume += t.Tokens
+	if t.IsBuy {
+		c.Buys++
+	} else {
+		c.Sells++
+	}
+	if t.Trader != "" {
+		if _, ok := c.traders[t.Trader]; !ok {
+			c.traders[t.Trader] = struct{}{}
+			c.Traders++
+		}
+	}
+}
+
+// series 一个代币一个周期的K线，保存在固定容量的环形缓冲区中，满了以后覆盖最早的一根
+type series struct {
+	interval time.Duration
+	candles  []Candle
+	head     int // 最早一根的位置
+	count    int
+}
+
+func newSeries(interval time.Duration, capacity int) *series {
+	return &series{interval: interval, candles: make([]Candle, capacity)}
+}
+
+func (s *series) last() *Candle {
+	return &s.candles[(s.head+s.count-1)%len(s.candles)]
+}
+
+func (s *series) push(c Candle) {
+	if s.count < len(s.candles) {
+		s.candles[(s.head+s.count)%len(s.candles)] = c
+		s.count++
+		return
+	}
+	s.candles[s.head] = c
+	s.head = (s.head + 1) % len(s.candles)
+}
+
+// add 将成交计入所在周期，早于最新一根的乱序成交并入最新一根
+func (s *series) add(t Trade) {
+	start := t.Time.Truncate(s.interval)
+	if s.count > 0 {
+		last := s.last()
+		if !start.After(last.Start) {
+			last.apply(t)
+			return
+		}
+		last.traders = nil
+
+		// 中间没有成交的周期补平盘K线，最多补满缓冲区
+		prevStart, prevClose := last.Start, last.Close
+		gaps := int(start.Sub(prevStart)/s.interval) - 1
+		if gaps > len(s.candles) {
+			gaps = len(s.candles)
+		}
+		for i := gaps; i >= 1; i-- {
+			s.push(Candle{
+				Start: start.Add(-time.Duration(i) * s.interval),
+				Open:  prevClose,
+				High:  prevClose,
+				Low:   prevClose,
+				Close: prevClose,
+			})
+		}
+	}
+
+	c := Candle{
+		Start:   start,
+		Open:    t.Price,
+		High:    t.Price,
+		Low:     t.Price,
+		traders: make(map[string]struct{}),
+	}
+	c.apply(t)
+	s.push(c)
+}
+
+// latest 按时间顺序返回最近 n 根K线，n <= 0 时返回全部
+func (s *series) latest(n int) []Candle {
+	if n <= 0 || n > s.count {
+		n = s.count
+	}
+	out := make([]Candle, n)
+	for i := range out {
+		out[i] = s.candles[(s.head+s.count-n+i)%len(s.candles)]
+		out[i].traders = nil
+	}
+	return out
+}
+
+// Aggregator 按代币和周期将成交聚合为K线，每个代币每个周期最多保留 capacity 根
+type Aggregator struct {
+	intervals []time.Duration
+	capacity  int
+
+	mu     sync.RWMutex
+	tokens map[string][]*series // 与 intervals 一一对应
+}
+
+// NewAggregator 创建K线聚合器，intervals 为空或 capacity <= 0 时不聚合
+func NewAggregator(intervals []time.Duration, capacity int) *Aggregator {
+	sorted := append([]time.Duration(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if capacity <= 0 {
+		sorted = nil
+	}
+	return &Aggregator{
+		intervals: sorted,
+		capacity:  capacity,
+		tokens:    make(map[string][]*series),
+	}
+}
+
+// Intervals 返回聚合的周期，从短到长排列
+func (a *Aggregator) Intervals() []time.Duration {
+	return append([]time.Duration(nil), a.intervals...)
+}
+
+// Add 将代币的一笔成交计入各周期的K线
+func (a *Aggregator) Add(mint string, t Trade) {
+	if len(a.intervals) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	all, ok := a.tokens[mint]
+	if !ok {
+		all = make([]*series, len(a.intervals))
+		for i, interval := range a.intervals {
+			all[i] = newSeries(interval, a.capacity)
+		}
+		a.tokens[mint] = all
+	}
+	for _, s := range all {
+		s.add(t)
+	}
+}
+
+// Candles 按时间顺序返回代币在该周期最近 n 根K线(n <= 0 时返回全部)，最后一根可能尚未收盘
+// 最后一笔成交之后的周期不补K线；没有该代币或周期的数据时返回 nil
+func (a *Aggregator) Candles(mint string, interval time.Duration, n int) []Candle {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, s := range a.tokens[mint] {
+		if s.interval == interval {
+			return s.latest(n)
+		}
+	}
+	return nil
+}
+
+// Forget 丢弃代币的K线，停止跟踪代币时调用
+func (a *Aggregator) Forget(mint string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.tokens, mint)
+}
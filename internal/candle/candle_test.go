@@ -0,0 +1,139 @@
+package candle
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trade := func(offset time.Duration, price, sol float64, buy bool, trader string) Trade {
+		return Trade{Time: base.Add(offset), Price: price, Sol: sol, Tokens: sol / price, IsBuy: buy, Trader: trader}
+	}
+
+	tests := []struct {
+		name     string
+		capacity int
+		trades   []Trade
+		interval time.Duration
+		want     []Candle
+	}{
+		{
+			name:     "同一周期合并",
+			capacity: 10,
+			trades: []Trade{
+				trade(100*time.Millisecond, 1.0, 1, true, "a"),
+				trade(300*time.Millisecond, 1.5, 2, true, "b"),
+				trade(600*time.Millisecond, 0.8, 1, false, "a"),
+				trade(900*time.Millisecond, 1.2, 0.5, false, "c"),
+			},
+			interval: time.Second,
+			want: []Candle{
+				{Start: base, Open: 1.0, High: 1.5, Low: 0.8, Close: 1.2, Volume: 4.5, Buys: 2, Sells: 2, Traders: 3},
+			},
+		},
+		{
+			name:     "没有成交的周期补平盘",
+			capacity: 10,
+			trades: []Trade{
+				trade(0, 1.0, 1, true, "a"),
+				trade(3500*time.Millisecond, 2.0, 1, true, "a"),
+			},
+			interval: time.Second,
+			want: []Candle{
+				{Start: base, Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0, Volume: 1, Buys: 1, Traders: 1},
+				{Start: base.Add(time.Second), Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0},
+				{Start: base.Add(2 * time.Second), Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0},
+				{Start: base.Add(3 * time.Second), Open: 2.0, High: 2.0, Low: 2.0, Close: 2.0, Volume: 1, Buys: 1, Traders: 1},
+			},
+		},
+		{
+			name:     "较长周期",
+			capacity: 10,
+			trades: []Trade{
+				trade(time.Second, 1.0, 1, true, "a"),
+				trade(4*time.Second, 3.0, 1, true, "b"),
+				trade(6*time.Second, 2.0, 1, false, "a"),
+			},
+			interval: 5 * time.Second,
+			want: []Candle{
+				{Start: base, Open: 1.0, High: 3.0, Low: 1.0, Close: 3.0, Volume: 2, Buys: 2, Traders: 2},
+				{Start: base.Add(5 * time.Second), Open: 2.0, High: 2.0, Low: 2.0, Close: 2.0, Volume: 1, Sells: 1, Traders: 1},
+			},
+		},
+		{
+			name:     "超过容量覆盖最早的K线",
+			capacity: 2,
+			trades: []Trade{
+				trade(0, 1.0, 1, true, ""),
+				trade(time.Second, 2.0, 1, true, ""),
+				trade(10*time.Second, 3.0, 1, false, ""),
+			},
+			interval: time.Second,
+			want: []Candle{
+				{Start: base.Add(9 * time.Second), Open: 2.0, High: 2.0, Low: 2.0, Close: 2.0},
+				{Start: base.Add(10 * time.Second), Open: 3.0, High: 3.0, Low: 3.0, Close: 3.0, Volume: 1, Sells: 1},
+			},
+		},
+		{
+			name:     "乱序成交并入最新一根",
+			capacity: 10,
+			trades: []Trade{
+				trade(2*time.Second, 1.0, 1, true, "a"),
+				trade(time.Second, 0.5, 1, false, "b"),
+			},
+			interval: time.Second,
+			want: []Candle{
+				{Start: base.Add(2 * time.Second), Open: 1.0, High: 1.0, Low: 0.5, Close: 0.5, Volume: 2, Buys: 1, Sells: 1, Traders: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAggregator([]time.Duration{5 * time.Second, time.Second}, tt.capacity)
+			for _, tr := range tt.trades {
+				a.Add("mint", tr)
+			}
+			got := a.Candles("mint", tt.interval, 0)
+			if len(got) != len(tt.want) {
+				t.Fatalf("K线数量 = %d, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, c := range got {
+				c.TokenVolume = 0
+				if !reflect.DeepEqual(c, tt.want[i]) {
+					t.Errorf("第 %d 根 = %+v, want %+v", i, c, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCandles(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewAggregator([]time.Duration{time.Second}, 10)
+	for i := 0; i < 5; i++ {
+		a.Add("mint", Trade{Time: base.Add(time.Duration(i) * time.Second), Price: float64(i + 1), Sol: 1, IsBuy: true})
+	}
+
+	if got := a.Candles("mint", time.Second, 2); len(got) != 2 || got[0].Close != 4 || got[1].Close != 5 {
+		t.Errorf("Candles(n=2) = %+v, want 最近两根", got)
+	}
+	if got := a.Candles("mint", time.Minute, 0); got != nil {
+		t.Errorf("未聚合的周期 Candles() = %+v, want nil", got)
+	}
+	if got := a.Candles("other", time.Second, 0); got != nil {
+		t.Errorf("未跟踪的代币 Candles() = %+v, want nil", got)
+	}
+	a.Forget("mint")
+	if got := a.Candles("mint", time.Second, 0); got != nil {
+		t.Errorf("Forget() 后 Candles() = %+v, want nil", got)
+	}
+
+	disabled := NewAggregator(nil, 10)
+	disabled.Add("mint", Trade{Time: base, Price: 1})
+	if got := disabled.Candles("mint", time.Second, 0); got != nil {
+		t.Errorf("未配置周期时 Candles() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,94 @@
+package chainTx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pump_auto/internal/config"
+	"pump_auto/internal/jito"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+var bundlePollInterval = 500 * time.Millisecond
+
+// bundle 发送配置，由 Init 注入，bundler 为 nil 时直接通过RPC发送
+var (
+	bundler       *jito.Client
+	tipAccount    solana.PublicKey
+	tipLamports   uint64
+	bundleTimeout time.Duration
+)
+
+// initBundler 开启 jito 时创建 block engine 客户端
+func initBundler(cfg *config.Config) {
+	bundler = nil
+	if !cfg.Jito.Enabled {
+		return
+	}
+	bundler = jito.New(cfg.Jito.Endpoint)
+	tipAccount = solana.PublicKey{}
+	if cfg.Jito.TipAccount != "" {
+		tipAccount = solana.MustPublicKeyFromBase58(cfg.Jito.TipAccount)
+	}
+	tipLamports = solToLamports(cfg.Jito.TipSol)
+	bundleTimeout = cfg.Jito.StatusTimeout.Std()
+}
+
+// submit 发送已签名的交易
+// 开启 bundle 时先连同小费交易提交到 block engine 并等待上链，失败或超时后回退到RPC发送同一笔交易(签名相同，不会重复成交)
+func submit(tx *solana.Transaction) error {
+	if bundler != nil {
+		err := sendBundle(tx)
+		if err == nil {
+			return nil
+		}
+		log.Printf("bundle 发送未成功，回退到RPC发送: %v", err)
+	}
+	_, err := chainClient.SendTransaction(tx)
+	return err
+}
+
+// sendBundle 将交易和小费转账组成 bundle 提交，等待到上链或 bundleTimeout
+func sendBundle(tx *solana.Transaction) error {
+	tip, err := tipTransaction(tx.Message.RecentBlockhash)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bundleTimeout)
+	defer cancel()
+
+	bundleID, err := bundler.SendBundle(ctx, tx, tip)
+	if err != nil {
+		return fmt.Errorf("提交 bundle 失败: %w", err)
+	}
+	log.Printf("bundle 已提交: id=%s 交易=%s 小费=%d lamports", bundleID, tx.Signatures[0], tipLamports)
+	slot, err := bundler.Wait(ctx, bundleID, bundlePollInterval)
+	if err != nil {
+		return err
+	}
+	log.Printf("bundle %s 已上链: slot=%d", bundleID, slot)
+	return nil
+}
+
+// tipTransaction 使用与交易相同的区块哈希组装并签名小费转账，bundle 中排在交易之后，交易失败时小费不会支付
+func tipTransaction(blockhash solana.Hash) (*solana.Transaction, error) {
+	account := tipAccount
+	if account.IsZero() {
+		account = jito.RandomTipAccount()
+	}
+	payer := signer.PublicKey()
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{jito.TipInstruction(payer, account, tipLamports)},
+		blockhash,
+		solana.TransactionPayer(payer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("组装小费交易失败: %w", err)
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
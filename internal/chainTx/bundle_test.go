@@ -0,0 +1,101 @@
+package chainTx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pump_auto/internal/jito"
+	solclient "pump_auto/internal/solana"
+	"pump_auto/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// stubBlockEngine 本地 block engine，所有 bundle 都返回 status；记录每个 bundle 的交易数量
+func stubBlockEngine(t *testing.T, status jito.BundleStatus, bundleSizes *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "sendBundle":
+			var txs []string
+			_ = json.Unmarshal(req.Params[0], &txs)
+			*bundleSizes = append(*bundleSizes, len(txs))
+			result = "b1"
+		case "getInflightBundleStatuses":
+			result = map[string]interface{}{"value": []interface{}{map[string]interface{}{"bundle_id": "b1", "status": status, "landed_slot": 1}}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func TestSubmitBundle(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	w, err := wallet.FromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FromPrivateKey() error = %v", err)
+	}
+	signer = w
+	tipLamports = jito.MinTipLamports
+	bundleTimeout = 200 * time.Millisecond
+	bundlePollInterval = 5 * time.Millisecond
+	defer func() { bundler, chainClient = nil, nil; bundlePollInterval = 500 * time.Millisecond }()
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("x"))},
+		solana.Hash{1}, solana.TransactionPayer(key.PublicKey()),
+	)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		status    jito.BundleStatus
+		down      bool
+		wantSends int
+	}{
+		{name: "bundle上链不再走RPC", status: jito.StatusLanded, wantSends: 0},
+		{name: "bundle失败回退RPC", status: jito.StatusFailed, wantSends: 1},
+		{name: "bundle一直未上链回退RPC", status: jito.StatusPending, wantSends: 1},
+		{name: "block engine不可用回退RPC", down: true, wantSends: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sizes []int
+			engine := stubBlockEngine(t, tt.status, &sizes)
+			if tt.down {
+				engine.Close()
+			} else {
+				defer engine.Close()
+			}
+			bundler = jito.New(engine.URL)
+			server := fakeRPC(t, nil)
+			defer server.Close()
+			chainClient = solclient.New(server.URL, context.Background())
+
+			if err := submit(tx); err != nil {
+				t.Fatalf("submit() error = %v", err)
+			}
+			if sends != tt.wantSends {
+				t.Errorf("RPC发送 %d 次，期望 %d 次", sends, tt.wantSends)
+			}
+			if !tt.down && (len(sizes) != 1 || sizes[0] != 2) {
+				t.Errorf("bundle 应包含交易和小费转账两笔交易，实际为 %v", sizes)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package chainTx
+
+import (
+	"context"
+	"fmt"
+	solclient "pump_auto/internal/solana"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// confirmTimeout 等待确认的最长时间，略长于区块哈希的有效期
+const confirmTimeout = 90 * time.Second
+
+// trackSignature 发送成功后登记签名，lastValidBlockHeight 来自交易使用的区块哈希
+func trackSignature(sig solana.Signature, lastValidBlockHeight uint64) {
+	if confirmer != nil {
+		confirmer.Track(sig, lastValidBlockHeight)
+	}
+}
+
+// Confirmation 返回签名的确认 future，未登记的签名按超时判断过期
+func Confirmation(sign string) (*solclient.Pending, error) {
+	if confirmer == nil {
+		return nil, fmt.Errorf("交易模块未初始化，请先调用 chainTx.Init")
+	}
+	sig, err := solana.SignatureFromBase58(sign)
+	if err != nil {
+		return nil, fmt.Errorf("无效的交易签名 %s: %v", sign, err)
+	}
+	if p, ok := confirmer.Lookup(sig); ok {
+		return p, nil
+	}
+	return confirmer.Track(sig, 0), nil
+}
+
+// WaitConfirmation 等待交易上链，执行失败时返回可识别原因的 *TradeError，区块哈希过期时返回 ErrBlockhashExpired
+func WaitConfirmation(sign string) (solclient.Confirmation, error) {
+	p, err := Confirmation(sign)
+	if err != nil {
+		return solclient.Confirmation{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), confirmTimeout)
+	defer cancel()
+	result, err := p.Wait(ctx)
+	if result.Status == solclient.StatusFailed {
+		err = fmt.Errorf("交易 %s: %w", result.Signature, decodeChainError(result.Err, nil))
+	}
+	return result, err
+}
@@ -0,0 +1,198 @@
+package chainTx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pump_auto/internal/curve"
+	solclient "pump_auto/internal/solana"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// 交易失败的原因，可通过 errors.Is 判断
+var (
+	ErrInvalidRequest     = errors.New("交易请求无效")
+	ErrSlippageExceeded   = errors.New("成交价格超出滑点限制")
+	ErrInsufficientSOL    = errors.New("SOL余额不足")
+	ErrInsufficientTokens = errors.New("代币余额不足")
+	ErrBlockhashExpired   = solclient.ErrBlockhashExpired
+	ErrTransactionFailed  = solclient.ErrTransactionFailed
+	ErrAPIRejected        = errors.New("交易API拒绝请求")
+	ErrAPIUnavailable     = errors.New("交易API暂时不可用")
+	ErrTokenMigrated      = curve.ErrCurveComplete
+)
+
+// 错误来源
+const (
+	SourceAPI        = "api"        // pumpportal HTTP 响应
+	SourceSimulation = "simulation" // 发送前的模拟(预检)
+	SourceChain      = "chain"      // 已上链交易的执行结果
+)
+
+// TradeError 交易失败的详细信息
+type TradeError struct {
+	Kind   error    // 上面定义的原因之一，无法识别时为 nil
+	Source string   // 错误来源
+	Code   int      // HTTP 状态码或 pump 程序自定义错误码
+	Logs   []string // 程序日志，来自模拟或链上执行
+	Err    error    // 原始错误
+}
+
+func (e *TradeError) Error() string {
+	if e.Kind == nil {
+		return fmt.Sprintf("[%s] %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("%v [%s]: %v", e.Kind, e.Source, e.Err)
+}
+
+func (e *TradeError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// pump 程序自定义错误码
+const (
+	pumpErrTooMuchSolRequired   = 6002
+	pumpErrTooLittleSolReceived = 6003
+	pumpErrBondingCurveComplete = 6005
+)
+
+var (
+	customErrorPattern   = regexp.MustCompile(`(?i)custom program error: (0x[0-9a-f]+)`)
+	customErrorKeyed     = regexp.MustCompile(`(?i)"?custom"?:\s*(\d+)`)
+	tokenProgramFailure  = regexp.MustCompile(`Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA failed: custom program error: 0x1\b`)
+	insufficientLamports = regexp.MustCompile(`(?i)insufficient lamports|insufficientfundsforfee|insufficientfundsforrent|insufficient funds for (fee|rent)|AccountNotFound|no record of a prior credit`)
+)
+
+// classify 根据错误描述和程序日志识别失败原因，返回原因和 pump 错误码
+func classify(text string, logs []string) (error, int) {
+	all := text + "\n" + strings.Join(logs, "\n")
+
+	code := 0
+	if m := customErrorPattern.FindAllStringSubmatch(all, -1); len(m) > 0 {
+		if v, err := strconv.ParseInt(m[len(m)-1][1], 0, 64); err == nil {
+			code = int(v)
+		}
+	} else if m := customErrorKeyed.FindStringSubmatch(all); m != nil {
+		code, _ = strconv.Atoi(m[1])
+	}
+
+	switch {
+	case code == pumpErrTooMuchSolRequired || code == pumpErrTooLittleSolReceived,
+		strings.Contains(all, "TooMuchSolRequired"), strings.Contains(all, "TooLittleSolReceived"),
+		strings.Contains(strings.ToLower(all), "slippage"):
+		return ErrSlippageExceeded, code
+	case code == pumpErrBondingCurveComplete, strings.Contains(all, "BondingCurveComplete"):
+		return ErrTokenMigrated, code
+	case strings.Contains(all, "BlockhashNotFound"), strings.Contains(strings.ToLower(all), "blockhash not found"):
+		return ErrBlockhashExpired, code
+	case tokenProgramFailure.MatchString(all):
+		return ErrInsufficientTokens, code
+	case insufficientLamports.MatchString(all), code == 1:
+		return ErrInsufficientSOL, code
+	}
+	return nil, code
+}
+
+// decodeRPCError 解析发送交易时的RPC错误，预检失败时错误数据中带有模拟日志
+func decodeRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	text := err.Error()
+	var logs []string
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		text = rpcErr.Message
+		if data, ok := rpcErr.Data.(map[string]interface{}); ok {
+			logs = stringSlice(data["logs"])
+			if txErr, ok := data["err"]; ok && txErr != nil {
+				text += " " + describe(txErr)
+			}
+		}
+	}
+	kind, code := classify(text, logs)
+	return &TradeError{Kind: kind, Source: SourceSimulation, Code: code, Logs: logs, Err: err}
+}
+
+// DecodeLogs 从模拟或链上执行的程序日志和错误中识别失败原因
+func DecodeLogs(txErr interface{}, logs []string) error {
+	if txErr == nil {
+		return nil
+	}
+	kind, code := classify(describe(txErr), logs)
+	return &TradeError{Kind: kind, Source: SourceSimulation, Code: code, Logs: logs, Err: fmt.Errorf("%s", describe(txErr))}
+}
+
+// decodeChainError 解析已上链交易的执行错误(meta.err / 签名状态中的 err)
+func decodeChainError(txErr interface{}, logs []string) error {
+	if txErr == nil {
+		return nil
+	}
+	kind, code := classify(describe(txErr), logs)
+	return &TradeError{Kind: kind, Source: SourceChain, Code: code, Logs: logs, Err: fmt.Errorf("%w: %s", ErrTransactionFailed, describe(txErr))}
+}
+
+// decodeAPIError 解析 pumpportal 返回的非200响应
+// 4xx 视为请求被拒绝(不可重试)，429 和 5xx 视为暂时不可用
+func decodeAPIError(status int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		if payload.Error != "" {
+			message = payload.Error
+		} else if payload.Message != "" {
+			message = payload.Message
+		}
+	}
+
+	kind, _ := classify(message, nil)
+	lower := strings.ToLower(message)
+	switch {
+	case kind != nil:
+	case strings.Contains(lower, "insufficient") || strings.Contains(lower, "not enough"):
+		kind = ErrInsufficientSOL
+	case strings.Contains(lower, "migrat") || strings.Contains(lower, "complete"):
+		kind = ErrTokenMigrated
+	case status == 429 || status >= 500:
+		kind = ErrAPIUnavailable
+	default:
+		kind = ErrAPIRejected
+	}
+	return &TradeError{Kind: kind, Source: SourceAPI, Code: status, Err: fmt.Errorf("HTTP %d: %s", status, message)}
+}
+
+// describe 将 JSON 解析出的交易错误转成文本，保留 Custom 错误码
+func describe(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func stringSlice(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package chainTx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+func TestDecodeErrors(t *testing.T) {
+	preflight := func(txErr interface{}, logs ...string) error {
+		data := map[string]interface{}{"err": txErr, "logs": []interface{}{}}
+		for _, l := range logs {
+			data["logs"] = append(data["logs"].([]interface{}), l)
+		}
+		return &jsonrpc.RPCError{Code: -32002, Message: "Transaction simulation failed: Error processing Instruction 3: custom program error", Data: data}
+	}
+	custom := func(code int) interface{} {
+		return map[string]interface{}{"InstructionError": []interface{}{3, map[string]interface{}{"Custom": code}}}
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		want     error
+		wantCode int
+	}{
+		{
+			name:     "预检买入滑点",
+			err:      decodeRPCError(preflight(custom(6002), "Program log: AnchorError occurred. Error Code: TooMuchSolRequired. Error Number: 6002.")),
+			want:     ErrSlippageExceeded,
+			wantCode: 6002,
+		},
+		{
+			name:     "预检代币已迁移",
+			err:      decodeRPCError(preflight(custom(6005), "Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P failed: custom program error: 0x1775")),
+			want:     ErrTokenMigrated,
+			wantCode: 6005,
+		},
+		{
+			name: "预检SOL不足",
+			err:  decodeRPCError(preflight(custom(1), "Transfer: insufficient lamports 1000, need 2039280", "Program 11111111111111111111111111111111 failed: custom program error: 0x1")),
+			want: ErrInsufficientSOL, wantCode: 1,
+		},
+		{
+			name: "预检代币不足",
+			err:  decodeRPCError(preflight(custom(1), "Program log: Error: insufficient funds", "Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA failed: custom program error: 0x1")),
+			want: ErrInsufficientTokens, wantCode: 1,
+		},
+		{
+			name: "区块哈希不存在",
+			err:  decodeRPCError(&jsonrpc.RPCError{Code: -32002, Message: "Transaction simulation failed: Blockhash not found", Data: map[string]interface{}{"err": "BlockhashNotFound"}}),
+			want: ErrBlockhashExpired,
+		},
+		{
+			name:     "链上卖出滑点",
+			err:      decodeChainError(custom(6003), nil),
+			want:     ErrSlippageExceeded,
+			wantCode: 6003,
+		},
+		{
+			name: "链上执行失败",
+			err:  decodeChainError(custom(6003), nil),
+			want: ErrTransactionFailed, wantCode: 6003,
+		},
+		{
+			name: "API拒绝",
+			err:  decodeAPIError(400, []byte(`{"error":"Invalid mint"}`)),
+			want: ErrAPIRejected, wantCode: 400,
+		},
+		{
+			name: "API限流",
+			err:  decodeAPIError(429, []byte("Too Many Requests")),
+			want: ErrAPIUnavailable, wantCode: 429,
+		},
+		{
+			name: "API提示代币已迁移",
+			err:  decodeAPIError(400, []byte("Token has migrated, bonding curve complete")),
+			want: ErrTokenMigrated, wantCode: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("发送交易失败: %w", tt.err)
+			if !errors.Is(wrapped, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", wrapped, tt.want)
+			}
+			var tradeErr *TradeError
+			if !errors.As(wrapped, &tradeErr) {
+				t.Fatalf("应返回 *TradeError，实际 %T", tt.err)
+			}
+			if tradeErr.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", tradeErr.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package chainTx
+
+import (
+	"context"
+	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/fee"
+	"pump_auto/internal/pump"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	feeOracle     *fee.Oracle
+	stopFeeOracle context.CancelFunc
+)
+
+// initFeeOracle 开启动态优先费时启动采样
+// 优先费按写锁账户统计: 始终采样每笔 pump 买卖都会写入的手续费接收账户，持仓期间再加上代币的联合曲线；
+// 全局配置账户在买卖中只读，程序ID不会被写锁，采样它们得不到竞争情况
+func initFeeOracle(cfg *config.Config) {
+	if stopFeeOracle != nil {
+		stopFeeOracle()
+		feeOracle, stopFeeOracle = nil, nil
+	}
+	if !cfg.Fee.Dynamic {
+		return
+	}
+	limit := cfg.Trade.ComputeUnitLimit
+	if limit == 0 {
+		limit = pump.DefaultComputeUnitLimit
+	}
+	feeOracle = fee.NewOracle(chainClient, fee.Config{
+		MinSol:           cfg.Fee.MinSol,
+		MaxSol:           cfg.Fee.MaxSol,
+		ComputeUnitLimit: limit,
+	}, pump.FeeRecipient)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopFeeOracle = cancel
+	go feeOracle.Run(ctx, cfg.Fee.SampleInterval.Std())
+}
+
+// PriorityFee 按紧急程度返回优先费(SOL)，未开启动态优先费时返回 fallback(配置的固定值)
+func PriorityFee(urgency fee.Urgency, fallback float64) float64 {
+	if feeOracle == nil || urgency == "" {
+		return fallback
+	}
+	estimate := feeOracle.Fee(urgency, fallback)
+	common.Log.WithFields(logrus.Fields{
+		"urgency":  urgency,
+		"estimate": estimate,
+		"fallback": fallback,
+	}).Debug("动态优先费")
+	return estimate
+}
+
+// watchCurve 持仓期间采样该代币联合曲线上的优先费
+func watchCurve(mint string, watch bool) {
+	if feeOracle == nil {
+		return
+	}
+	mintKey, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return
+	}
+	bondingCurve, err := pump.DeriveBondingCurve(mintKey)
+	if err != nil {
+		return
+	}
+	if watch {
+		feeOracle.Watch(bondingCurve)
+	} else {
+		feeOracle.Unwatch(bondingCurve)
+	}
+}
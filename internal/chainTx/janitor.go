@@ -0,0 +1,272 @@
+package chainTx
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"pump_auto/internal/config"
+	"pump_auto/internal/model"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// 代币账户布局和指令编号，SPL Token 与 Token-2022 相同
+const (
+	tokenAccountLen       = 165
+	tokenAccountFrozen    = 2
+	tokenInstructionBurn  = 8
+	tokenInstructionClose = 9
+
+	maxClosePerTx = 20 // 每笔清理交易关闭的账户数，受交易大小限制
+)
+
+// 代币账户清理配置，由 Init 注入
+var (
+	closeAccounts bool
+	dustTokens    float64
+)
+
+func initJanitor(cfg *config.Config) {
+	closeAccounts = cfg.Janitor.CloseAccounts
+	dustTokens = cfg.Janitor.DustTokens
+}
+
+// tokenAccount 钱包持有的一个代币账户
+type tokenAccount struct {
+	Address        solana.PublicKey
+	Program        solana.PublicKey // 账户所属的代币程序
+	Mint           solana.PublicKey
+	Owner          solana.PublicKey
+	Amount         uint64
+	Frozen         bool
+	CloseAuthority *solana.PublicKey // 为 nil 时由 Owner 关闭
+	Lamports       uint64            // 关闭后退回的租金
+}
+
+// decodeTokenAccount 解析代币账户数据，Token-2022 的扩展数据位于基础布局之后，这里不需要
+func decodeTokenAccount(address, program solana.PublicKey, lamports uint64, data []byte) (tokenAccount, error) {
+	if len(data) < tokenAccountLen {
+		return tokenAccount{}, fmt.Errorf("代币账户 %s 数据长度 %d", address, len(data))
+	}
+	return tokenAccount{
+		Address:        address,
+		Program:        program,
+		Mint:           solana.PublicKeyFromBytes(data[0:32]),
+		Owner:          solana.PublicKeyFromBytes(data[32:64]),
+		Amount:         binary.LittleEndian.Uint64(data[64:72]),
+		Frozen:         data[108] == tokenAccountFrozen,
+		CloseAuthority: optionalKey(data[129:165]),
+		Lamports:       lamports,
+	}, nil
+}
+
+// optionalKey 解析 COption<Pubkey>: 4 字节标记 + 32 字节公钥
+func optionalKey(data []byte) *solana.PublicKey {
+	if binary.LittleEndian.Uint32(data[0:4]) == 0 {
+		return nil
+	}
+	key := solana.PublicKeyFromBytes(data[4:36])
+	return &key
+}
+
+// closableBy 钱包能否关闭该账户: 冻结的账户无法关闭，设置了其他关闭权限的账户也不归钱包处理
+func (a tokenAccount) closableBy(wallet solana.PublicKey) bool {
+	if a.Frozen {
+		return false
+	}
+	return a.CloseAuthority == nil || a.CloseAuthority.Equals(wallet)
+}
+
+// walletTokenAccounts 查询钱包的代币账户
+// mint 不为空时只查询该代币的账户，否则查询两个代币程序下的全部账户
+func walletTokenAccounts(mint *solana.PublicKey) ([]tokenAccount, error) {
+	owner := signer.PublicKey()
+	var filters []*rpc.GetTokenAccountsConfig
+	if mint != nil {
+		filters = append(filters, &rpc.GetTokenAccountsConfig{Mint: mint})
+	} else {
+		for _, program := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+			filters = append(filters, &rpc.GetTokenAccountsConfig{ProgramId: program.ToPointer()})
+		}
+	}
+
+	var accounts []tokenAccount
+	for _, filter := range filters {
+		var out *rpc.GetTokenAccountsResult
+		err := chainClient.Do(func(ctx context.Context, client *rpc.Client) (err error) {
+			out, err = client.GetTokenAccountsByOwner(ctx, owner, filter, &rpc.GetTokenAccountsOpts{
+				Commitment: commitment,
+				Encoding:   solana.EncodingBase64,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取代币账户失败: %w", err)
+		}
+		for _, keyed := range out.Value {
+			if keyed == nil || keyed.Account.Data == nil {
+				continue
+			}
+			account, err := decodeTokenAccount(keyed.Pubkey, keyed.Account.Owner, keyed.Account.Lamports, keyed.Account.Data.GetBinary())
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// burnInstruction 销毁账户中的全部代币
+func burnInstruction(a tokenAccount, wallet solana.PublicKey) solana.Instruction {
+	data := make([]byte, 9)
+	data[0] = tokenInstructionBurn
+	binary.LittleEndian.PutUint64(data[1:], a.Amount)
+	return solana.NewInstruction(a.Program, solana.AccountMetaSlice{
+		solana.Meta(a.Address).WRITE(),
+		solana.Meta(a.Mint).WRITE(),
+		solana.Meta(wallet).SIGNER(),
+	}, data)
+}
+
+// closeInstruction 关闭账户，租金退回钱包
+func closeInstruction(a tokenAccount, wallet solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(a.Program, solana.AccountMetaSlice{
+		solana.Meta(a.Address).WRITE(),
+		solana.Meta(wallet).WRITE(),
+		solana.Meta(wallet).SIGNER(),
+	}, []byte{tokenInstructionClose})
+}
+
+// reclaimInstructions 组装关闭单个账户的指令，余额不超过 dust(最小单位)时先销毁
+func reclaimInstructions(a tokenAccount, wallet solana.PublicKey, dust uint64) ([]solana.Instruction, error) {
+	if !a.closableBy(wallet) {
+		return nil, fmt.Errorf("%w: 代币账户 %s 已冻结或关闭权限不属于钱包", ErrInvalidRequest, a.Address)
+	}
+	if a.Amount == 0 {
+		return []solana.Instruction{closeInstruction(a, wallet)}, nil
+	}
+	if a.Amount > dust {
+		return nil, fmt.Errorf("%w: 代币账户 %s 仍有 %d 个最小单位的余额，超过零头上限 %d", ErrInsufficientTokens, a.Address, a.Amount, dust)
+	}
+	return []solana.Instruction{burnInstruction(a, wallet), closeInstruction(a, wallet)}, nil
+}
+
+// reclaimPlan 组装可关闭账户的指令，冻结、关闭权限不属于钱包或余额超过零头上限的账户会被跳过
+// 返回的账户数和租金只统计会被关闭的账户
+func reclaimPlan(accounts []tokenAccount, wallet solana.PublicKey, dust uint64) ([]solana.Instruction, int, model.Lamports) {
+	var instructions []solana.Instruction
+	closing := 0
+	var reclaimed model.Lamports
+	for _, account := range accounts {
+		ixs, err := reclaimInstructions(account, wallet, dust)
+		if err != nil {
+			log.Printf("跳过代币账户 %s: %v", account.Address, err)
+			continue
+		}
+		instructions = append(instructions, ixs...)
+		closing++
+		reclaimed += model.Lamports(account.Lamports)
+	}
+	return instructions, closing, reclaimed
+}
+
+// sendCleanup 使用缓存的区块哈希签名并通过RPC发送清理交易，等待确认
+// 清理交易不抢时效，不走 bundle，也就不用支付小费
+func sendCleanup(instructions []solana.Instruction) (solana.Signature, error) {
+	recent, err := blockhashes.Get()
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	tx, err := solana.NewTransaction(instructions, recent.Hash, solana.TransactionPayer(signer.PublicKey()))
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("组装清理交易失败: %w", err)
+	}
+	if err := signer.SignTransaction(tx); err != nil {
+		return solana.Signature{}, err
+	}
+	sig := tx.Signatures[0]
+	trackSignature(sig, recent.LastValidBlockHeight)
+	if _, err := chainClient.SendTransaction(tx); err != nil {
+		return sig, fmt.Errorf("发送清理交易失败: %w", decodeRPCError(err))
+	}
+	if _, err := WaitConfirmation(sig.String()); err != nil {
+		return sig, err
+	}
+	return sig, nil
+}
+
+// ReclaimRent 全部卖出确认后关闭代币账户回收租金
+// 剩余数量不超过 janitor.dustTokens 的零头先销毁再关闭，无法关闭的账户被跳过；未开启 janitor.closeAccounts 时不做任何事
+func ReclaimRent(mint string) (model.Lamports, error) {
+	if !closeAccounts {
+		return 0, nil
+	}
+	info, err := MintInfo(mint)
+	if err != nil {
+		return 0, err
+	}
+	accounts, err := walletTokenAccounts(&info.Mint)
+	if err != nil {
+		return 0, err
+	}
+
+	instructions, closing, reclaimed := reclaimPlan(accounts, signer.PublicKey(), info.RawAmount(dustTokens))
+	if len(instructions) == 0 {
+		return 0, nil
+	}
+
+	sig, err := sendCleanup(instructions)
+	if err != nil {
+		return 0, fmt.Errorf("关闭代币 %s 的账户失败: %w", mint, err)
+	}
+	log.Printf("已关闭代币 %s 的 %d 个账户，回收租金 %s SOL: https://solscan.io/tx/%s", mint, closing, reclaimed, sig)
+	return reclaimed, nil
+}
+
+// SweepTokenAccounts 关闭钱包中所有余额为0的代币账户，返回关闭的账户数和回收的租金
+// 冻结的账户和关闭权限不属于钱包的账户会被跳过；已关闭的批次在出错时仍计入结果
+func SweepTokenAccounts() (int, model.Lamports, error) {
+	if signer == nil {
+		return 0, 0, fmt.Errorf("交易模块未初始化，请先调用 chainTx.Init")
+	}
+	accounts, err := walletTokenAccounts(nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	wallet := signer.PublicKey()
+	var empty []tokenAccount
+	for _, account := range accounts {
+		if account.Amount == 0 && account.closableBy(wallet) {
+			empty = append(empty, account)
+		}
+	}
+
+	closed := 0
+	var reclaimed model.Lamports
+	for len(empty) > 0 {
+		batch := empty
+		if len(batch) > maxClosePerTx {
+			batch = batch[:maxClosePerTx]
+		}
+		empty = empty[len(batch):]
+
+		instructions := make([]solana.Instruction, 0, len(batch))
+		var lamports model.Lamports
+		for _, account := range batch {
+			instructions = append(instructions, closeInstruction(account, wallet))
+			lamports += model.Lamports(account.Lamports)
+		}
+		sig, err := sendCleanup(instructions)
+		if err != nil {
+			return closed, reclaimed, fmt.Errorf("关闭空代币账户失败: %w", err)
+		}
+		closed += len(batch)
+		reclaimed += lamports
+		log.Printf("已关闭 %d 个空代币账户，回收租金 %s SOL: https://solscan.io/tx/%s", len(batch), lamports, sig)
+	}
+	return closed, reclaimed, nil
+}
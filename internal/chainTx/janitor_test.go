@@ -0,0 +1,213 @@
+package chainTx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	solclient "pump_auto/internal/solana"
+	"pump_auto/internal/wallet"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// tokenAccountData 按代币账户布局编码数据，closeAuthority 为零值时表示未设置
+func tokenAccountData(mint, owner solana.PublicKey, amount uint64, frozen bool, closeAuthority solana.PublicKey) []byte {
+	data := make([]byte, tokenAccountLen)
+	copy(data[0:32], mint[:])
+	copy(data[32:64], owner[:])
+	binary.LittleEndian.PutUint64(data[64:72], amount)
+	data[108] = 1
+	if frozen {
+		data[108] = tokenAccountFrozen
+	}
+	if !closeAuthority.IsZero() {
+		binary.LittleEndian.PutUint32(data[129:133], 1)
+		copy(data[133:165], closeAuthority[:])
+	}
+	return data
+}
+
+func TestReclaimInstructions(t *testing.T) {
+	wallet := solana.NewWallet().PublicKey()
+	other := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+
+	tests := []struct {
+		name      string
+		program   solana.PublicKey
+		data      []byte
+		dust      uint64
+		wantErr   error
+		wantInstr []byte // 各指令的编号
+	}{
+		{
+			name:      "空账户直接关闭",
+			program:   solana.TokenProgramID,
+			data:      tokenAccountData(mint, wallet, 0, false, solana.PublicKey{}),
+			wantInstr: []byte{tokenInstructionClose},
+		},
+		{
+			name:      "零头先销毁",
+			program:   solana.Token2022ProgramID,
+			data:      tokenAccountData(mint, wallet, 7, false, solana.PublicKey{}),
+			dust:      10,
+			wantInstr: []byte{tokenInstructionBurn, tokenInstructionClose},
+		},
+		{
+			name:    "余额超过零头上限",
+			program: solana.TokenProgramID,
+			data:    tokenAccountData(mint, wallet, 11, false, solana.PublicKey{}),
+			dust:    10,
+			wantErr: ErrInsufficientTokens,
+		},
+		{
+			name:    "账户已冻结",
+			program: solana.TokenProgramID,
+			data:    tokenAccountData(mint, wallet, 0, true, solana.PublicKey{}),
+			wantErr: ErrInvalidRequest,
+		},
+		{
+			name:    "关闭权限属于其他地址",
+			program: solana.TokenProgramID,
+			data:    tokenAccountData(mint, wallet, 0, false, other),
+			wantErr: ErrInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := solana.NewWallet().PublicKey()
+			account, err := decodeTokenAccount(address, tt.program, 2_039_280, tt.data)
+			if err != nil {
+				t.Fatalf("decodeTokenAccount() error = %v", err)
+			}
+			instructions, err := reclaimInstructions(account, wallet, tt.dust)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reclaimInstructions() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(instructions) != len(tt.wantInstr) {
+				t.Fatalf("指令数量 = %d, want %d", len(instructions), len(tt.wantInstr))
+			}
+			for i, instruction := range instructions {
+				data, _ := instruction.Data()
+				if data[0] != tt.wantInstr[i] || !instruction.ProgramID().Equals(tt.program) {
+					t.Errorf("第 %d 条指令 = %d (程序 %s), want %d (程序 %s)", i, data[0], instruction.ProgramID(), tt.wantInstr[i], tt.program)
+				}
+				if accounts := instruction.Accounts(); !accounts[0].PublicKey.Equals(address) || !accounts[2].IsSigner {
+					t.Errorf("第 %d 条指令账户 = %v", i, accounts)
+				}
+			}
+		})
+	}
+}
+
+func TestReclaimPlan(t *testing.T) {
+	wallet := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	decode := func(data []byte, lamports uint64) tokenAccount {
+		account, err := decodeTokenAccount(solana.NewWallet().PublicKey(), solana.TokenProgramID, lamports, data)
+		if err != nil {
+			t.Fatalf("decodeTokenAccount() error = %v", err)
+		}
+		return account
+	}
+	accounts := []tokenAccount{
+		decode(tokenAccountData(mint, wallet, 0, false, solana.PublicKey{}), 2_039_280),
+		decode(tokenAccountData(mint, wallet, 0, true, solana.PublicKey{}), 1_000_000),
+		decode(tokenAccountData(mint, wallet, 50, false, solana.PublicKey{}), 1_000_000),
+		decode(tokenAccountData(mint, wallet, 5, false, solana.PublicKey{}), 2_074_080),
+	}
+
+	instructions, closing, reclaimed := reclaimPlan(accounts, wallet, 10)
+	if len(instructions) != 3 {
+		t.Errorf("指令数量 = %d, want 3 (关闭 + 销毁 + 关闭)", len(instructions))
+	}
+	if closing != 2 {
+		t.Errorf("关闭的账户数 = %d, want 2", closing)
+	}
+	if reclaimed != 2_039_280+2_074_080 {
+		t.Errorf("回收的租金 = %d, want %d", reclaimed, 2_039_280+2_074_080)
+	}
+}
+
+func TestWalletTokenAccounts(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	w, err := wallet.FromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FromPrivateKey() error = %v", err)
+	}
+	signer = w
+	defer func() { signer, chainClient = nil, nil }()
+
+	owner := key.PublicKey()
+	empty := solana.NewWallet().PublicKey()
+	holding := solana.NewWallet().PublicKey()
+	token2022 := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+
+	keyed := func(address, program solana.PublicKey, amount uint64) map[string]interface{} {
+		return map[string]interface{}{
+			"pubkey": address.String(),
+			"account": map[string]interface{}{
+				"data":       []string{base64.StdEncoding.EncodeToString(tokenAccountData(mint, owner, amount, false, solana.PublicKey{})), "base64"},
+				"executable": false,
+				"lamports":   2_039_280,
+				"owner":      program.String(),
+				"rentEpoch":  0,
+			},
+		}
+	}
+	byProgram := map[string][]interface{}{
+		solana.TokenProgramID.String():     {keyed(empty, solana.TokenProgramID, 0), keyed(holding, solana.TokenProgramID, 5)},
+		solana.Token2022ProgramID.String(): {keyed(token2022, solana.Token2022ProgramID, 0)},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{}       `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "getTokenAccountsByOwner" || len(req.Params) < 2 {
+			t.Errorf("未预期的RPC调用: %s (%v)", req.Method, err)
+			return
+		}
+		var filter struct {
+			ProgramID string `json:"programId"`
+		}
+		_ = json.Unmarshal(req.Params[1], &filter)
+		result := map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": byProgram[filter.ProgramID]}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer server.Close()
+	chainClient = solclient.New(server.URL, context.Background())
+
+	accounts, err := walletTokenAccounts(nil)
+	if err != nil {
+		t.Fatalf("walletTokenAccounts() error = %v", err)
+	}
+	if len(accounts) != 3 {
+		t.Fatalf("账户数量 = %d, want 3", len(accounts))
+	}
+	want := map[solana.PublicKey]struct {
+		program solana.PublicKey
+		amount  uint64
+	}{
+		empty:     {solana.TokenProgramID, 0},
+		holding:   {solana.TokenProgramID, 5},
+		token2022: {solana.Token2022ProgramID, 0},
+	}
+	for _, account := range accounts {
+		w, ok := want[account.Address]
+		if !ok || !account.Program.Equals(w.program) || account.Amount != w.amount || !account.Mint.Equals(mint) || account.Lamports != 2_039_280 {
+			t.Errorf("账户 = %+v", account)
+		}
+		if !account.closableBy(owner) {
+			t.Errorf("账户 %s 应可由钱包关闭", account.Address)
+		}
+	}
+}
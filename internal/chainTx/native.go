@@ -0,0 +1,142 @@
+package chainTx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pump_auto/internal/common"
+	"pump_auto/internal/curve"
+	"pump_auto/internal/model"
+	"pump_auto/internal/pump"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// executeNativeTrade 本地组装 pump.fun 买卖交易，区块哈希来自缓存
+func executeNativeTrade(action common.TradeAction, mint string, sol model.Lamports, tokens model.TokenAmount, sellPercent string, slippage int, priorityFee float64, pool common.PoolType) (*tradeAttempt, error) {
+	if pool != common.PUMP {
+		return nil, fmt.Errorf("%w: native 模式只支持 pump 联合曲线，当前池类型: %s", ErrInvalidRequest, pool)
+	}
+
+	mintKey, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的代币地址: %v", ErrInvalidRequest, err)
+	}
+
+	// 本地组装的指令使用 SPL Token 程序，Token-2022 代币交给 pumpportal 构建
+	info, err := mints.Get(mintKey)
+	if err != nil {
+		return nil, fmt.Errorf("获取代币信息失败: %w", err)
+	}
+	if info.Token2022() {
+		return nil, fmt.Errorf("%w: native 模式不支持 Token-2022 代币 %s", ErrInvalidRequest, mint)
+	}
+
+	bondingCurve, err := loadBondingCurve(mintKey, action)
+	if err != nil {
+		return nil, err
+	}
+
+	params := pump.TradeParams{
+		Mint:             mintKey,
+		Creator:          bondingCurve.Creator,
+		User:             signer.PublicKey(),
+		ComputeUnitLimit: computeUnitLimit,
+		ComputeUnitPrice: pump.PriorityFeeToMicroLamports(priorityFee, computeUnitLimit),
+	}
+
+	var quote curve.Quote
+	switch action {
+	case common.BUY:
+		quote, err = bondingCurve.BuyQuote(uint64(sol))
+		if err != nil {
+			return nil, fmt.Errorf("计算买入报价失败: %w", err)
+		}
+		params.TokenAmount = quote.TokenAmount
+		params.SolLimit = quote.WithSlippage(true, slippage)
+	case common.SELL:
+		if sellPercent == "100%" {
+			tokens, err = GetTokenBalance(mint)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if tokens.Decimals != info.Decimals {
+			return nil, fmt.Errorf("%w: 卖出数量精度 %d 与代币精度 %d 不一致", ErrInvalidRequest, tokens.Decimals, info.Decimals)
+		}
+		quote, err = bondingCurve.SellQuote(tokens.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("计算卖出报价失败: %w", err)
+		}
+		params.TokenAmount = quote.TokenAmount
+		params.SolLimit = quote.WithSlippage(false, slippage)
+	default:
+		return nil, fmt.Errorf("%w: 未知的交易动作: %s", ErrInvalidRequest, action)
+	}
+
+	if params.TokenAmount == 0 {
+		return nil, fmt.Errorf("%w: 计算得到的代币数量为0，取消交易", ErrInvalidRequest)
+	}
+
+	recent, err := blockhashes.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *solana.Transaction
+	if action == common.BUY {
+		tx, err = pump.BuildBuyTransaction(params, recent.Hash)
+	} else {
+		tx, err = pump.BuildSellTransaction(params, recent.Hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("本地组装交易: action=%s mint=%s tokenAmount=%d solLimit=%d 价格影响=%.4f%%",
+		action, mint, params.TokenAmount, params.SolLimit, quote.PriceImpact*100)
+
+	if err := signer.SignTransaction(tx); err != nil {
+		return nil, err
+	}
+
+	if shouldSimulate(action) {
+		if _, err := preflight(tx, action, mintKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return sendAttempt(tx, recent.LastValidBlockHeight)
+}
+
+// 推送快照由浮点储备还原且无法反映 complete 标志，只在足够新时用于买入
+const snapshotMaxAge = 3 * time.Second
+
+// loadBondingCurve 读取联合曲线状态
+// 买入时使用足够新、且曲线未售罄的推送快照，避免额外的RPC请求
+// 其余情况(包括所有卖出)读取链上账户，得到精确储备和 complete 标志
+func loadBondingCurve(mint solana.PublicKey, action common.TradeAction) (*curve.BondingCurve, error) {
+	if action == common.BUY {
+		if snap, ok := pump.GetSnapshot(mint.String()); ok && time.Since(snap.UpdatedAt) <= snapshotMaxAge {
+			bondingCurve := curve.FromVirtualReserves(snap.VirtualSolReserves, snap.VirtualTokenReserves, snap.Creator)
+			if bondingCurve.RealTokenReserves > 0 {
+				return bondingCurve, nil
+			}
+		}
+	}
+
+	var bondingCurve *curve.BondingCurve
+	err := chainClient.Do(func(ctx context.Context, client *rpc.Client) (err error) {
+		bondingCurve, err = curve.Fetch(ctx, client, mint)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if bondingCurve.Creator.IsZero() {
+		return nil, fmt.Errorf("联合曲线账户缺少创建者信息，无法推导 creator_vault")
+	}
+	return bondingCurve, nil
+}
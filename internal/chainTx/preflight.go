@@ -0,0 +1,208 @@
+package chainTx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"pump_auto/internal/common"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// 计算预算程序的指令编号
+const (
+	computeBudgetSetUnitLimit byte = 2
+	computeBudgetSetUnitPrice byte = 3
+)
+
+const (
+	computeUnitMarginPercent = 20     // 在模拟消耗的基础上预留的余量
+	minComputeUnitLimit      = 30_000 // 收紧后的最小上限
+	maxSimulations           = 256    // 保留的预检结果数量
+)
+
+// Simulation 一笔交易的预检结果
+type Simulation struct {
+	Action        common.TradeAction
+	Mint          string
+	UnitsConsumed uint64
+	UnitLimit     uint32 // 调整后的计算单元上限，未调整时为原值
+	TokenDelta    int64  // 预计钱包代币余额变化(最小单位)，买入为正
+	SolDelta      int64  // 预计钱包SOL余额变化(lamports)
+	Logs          []string
+}
+
+var (
+	simulationsMu sync.Mutex
+	simulations   = make(map[solana.Signature]*Simulation)
+	simulationLog []solana.Signature
+)
+
+// Simulated 返回交易发送前的预检结果，未预检的交易返回 false
+func Simulated(sign string) (*Simulation, bool) {
+	sig, err := solana.SignatureFromBase58(sign)
+	if err != nil {
+		return nil, false
+	}
+	simulationsMu.Lock()
+	defer simulationsMu.Unlock()
+	sim, ok := simulations[sig]
+	return sim, ok
+}
+
+func recordSimulation(sig solana.Signature, sim *Simulation) {
+	simulationsMu.Lock()
+	defer simulationsMu.Unlock()
+	simulations[sig] = sim
+	simulationLog = append(simulationLog, sig)
+	if len(simulationLog) > maxSimulations {
+		delete(simulations, simulationLog[0])
+		simulationLog = simulationLog[1:]
+	}
+}
+
+// shouldSimulate 按动作判断是否需要预检
+func shouldSimulate(action common.TradeAction) bool {
+	if chainClient == nil {
+		return false
+	}
+	if action == common.BUY {
+		return simulateBuy
+	}
+	return simulateSell
+}
+
+// preflight 模拟已签名的交易，模拟失败时返回可识别原因的 *TradeError 并放弃发送
+// 模拟成功时记录预计的余额变化，并按实际消耗收紧计算单元上限(需要重新签名)
+func preflight(tx *solana.Transaction, action common.TradeAction, mint solana.PublicKey) (*Simulation, error) {
+	user := signer.PublicKey()
+	info, err := MintInfo(mint.String())
+	if err != nil {
+		return nil, err
+	}
+	ata, err := info.AssociatedAccount(user)
+	if err != nil {
+		return nil, err
+	}
+	watched := []solana.PublicKey{user, ata}
+
+	before, err := chainClient.GetMultipleAccounts(watched...)
+	if err != nil {
+		return nil, fmt.Errorf("获取预检账户状态失败: %v", err)
+	}
+	out, err := chainClient.SimulateTransactionWithAccounts(tx, watched)
+	if err != nil {
+		return nil, fmt.Errorf("模拟交易失败: %w", decodeRPCError(err))
+	}
+	if out.Value == nil {
+		return nil, fmt.Errorf("模拟交易没有返回结果")
+	}
+	result := out.Value
+	if result.Err != nil {
+		for _, line := range result.Logs {
+			log.Printf("模拟日志: %s", line)
+		}
+		return nil, fmt.Errorf("预检未通过，取消发送: %w", DecodeLogs(result.Err, result.Logs))
+	}
+
+	sim := &Simulation{
+		Action: action,
+		Mint:   mint.String(),
+		Logs:   result.Logs,
+	}
+	if result.UnitsConsumed != nil {
+		sim.UnitsConsumed = *result.UnitsConsumed
+	}
+	if len(result.Accounts) == len(watched) && before != nil && len(before.Value) == len(watched) {
+		sim.SolDelta = int64(lamportsOf(result.Accounts[0])) - int64(lamportsOf(before.Value[0]))
+		sim.TokenDelta = int64(tokenAmountOf(result.Accounts[1])) - int64(tokenAmountOf(before.Value[1]))
+	}
+
+	sim.UnitLimit = currentUnitLimit(tx)
+	if tuneComputeUnits && sim.UnitsConsumed > 0 {
+		if tuned, ok := tuneComputeBudget(tx, sim.UnitsConsumed); ok {
+			sim.UnitLimit = tuned
+			if err := signer.SignTransaction(tx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	log.Printf("预检通过: action=%s mint=%s 消耗计算单元=%d 上限=%d 预计代币变化=%d 预计SOL变化=%d lamports",
+		action, mint, sim.UnitsConsumed, sim.UnitLimit, sim.TokenDelta, sim.SolDelta)
+	recordSimulation(tx.Signatures[0], sim)
+	return sim, nil
+}
+
+func lamportsOf(account *rpc.Account) uint64 {
+	if account == nil {
+		return 0
+	}
+	return account.Lamports
+}
+
+// tokenAmountOf 读取 SPL 代币账户的余额字段(偏移64的u64)，账户不存在时为0
+func tokenAmountOf(account *rpc.Account) uint64 {
+	if account == nil || account.Data == nil {
+		return 0
+	}
+	data := account.Data.GetBinary()
+	if len(data) < 72 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(data[64:72])
+}
+
+// computeBudgetInstructions 返回交易中设置计算单元上限和单价的指令
+func computeBudgetInstructions(tx *solana.Transaction) (limit, price *solana.CompiledInstruction) {
+	for i := range tx.Message.Instructions {
+		inst := &tx.Message.Instructions[i]
+		program, err := tx.Message.Program(inst.ProgramIDIndex)
+		if err != nil || !program.Equals(computebudget.ProgramID) || len(inst.Data) == 0 {
+			continue
+		}
+		switch {
+		case inst.Data[0] == computeBudgetSetUnitLimit && len(inst.Data) >= 5:
+			limit = inst
+		case inst.Data[0] == computeBudgetSetUnitPrice && len(inst.Data) >= 9:
+			price = inst
+		}
+	}
+	return limit, price
+}
+
+func currentUnitLimit(tx *solana.Transaction) uint32 {
+	limit, _ := computeBudgetInstructions(tx)
+	if limit == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(limit.Data[1:5])
+}
+
+// tuneComputeBudget 将计算单元上限收紧到 消耗*(1+余量)，同时提高单价使优先费总额不变
+// 只会收紧不会放宽；交易中没有上限指令时不调整
+func tuneComputeBudget(tx *solana.Transaction, unitsConsumed uint64) (uint32, bool) {
+	limitInst, priceInst := computeBudgetInstructions(tx)
+	if limitInst == nil {
+		return 0, false
+	}
+	current := binary.LittleEndian.Uint32(limitInst.Data[1:5])
+	tuned := unitsConsumed * (100 + computeUnitMarginPercent) / 100
+	if tuned < minComputeUnitLimit {
+		tuned = minComputeUnitLimit
+	}
+	if tuned >= uint64(current) {
+		return current, false
+	}
+
+	if priceInst != nil {
+		price := binary.LittleEndian.Uint64(priceInst.Data[1:9])
+		// 优先费 = 上限 * 单价 / 1e6，保持总额不变
+		binary.LittleEndian.PutUint64(priceInst.Data[1:9], price*uint64(current)/tuned)
+	}
+	binary.LittleEndian.PutUint32(limitInst.Data[1:5], uint32(tuned))
+	return uint32(tuned), true
+}
@@ -0,0 +1,160 @@
+package chainTx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pump_auto/internal/common"
+	"pump_auto/internal/mintinfo"
+	"pump_auto/internal/pump"
+	solclient "pump_auto/internal/solana"
+	"pump_auto/internal/wallet"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestTuneComputeBudget(t *testing.T) {
+	user, _ := solana.NewRandomPrivateKey()
+	params := pump.TradeParams{
+		Mint:             solana.MustPublicKeyFromBase58("7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump"),
+		Creator:          user.PublicKey(),
+		User:             user.PublicKey(),
+		TokenAmount:      1,
+		SolLimit:         1,
+		ComputeUnitLimit: 120_000,
+		ComputeUnitPrice: 5_000,
+	}
+	tests := []struct {
+		name      string
+		consumed  uint64
+		wantLimit uint32
+		wantOK    bool
+	}{
+		{name: "收紧上限", consumed: 50_000, wantLimit: 60_000, wantOK: true},
+		{name: "不低于最小上限", consumed: 1_000, wantLimit: minComputeUnitLimit, wantOK: true},
+		{name: "消耗接近上限时不调整", consumed: 110_000, wantLimit: 120_000, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := pump.BuildBuyTransaction(params, solana.Hash{})
+			if err != nil {
+				t.Fatalf("BuildBuyTransaction() error = %v", err)
+			}
+			got, ok := tuneComputeBudget(tx, tt.consumed)
+			if got != tt.wantLimit || ok != tt.wantOK {
+				t.Fatalf("tuneComputeBudget() = %d, %v, want %d, %v", got, ok, tt.wantLimit, tt.wantOK)
+			}
+			_, priceInst := computeBudgetInstructions(tx)
+			price := binary.LittleEndian.Uint64(priceInst.Data[1:9])
+			if fee := uint64(currentUnitLimit(tx)) * price; fee > 120_000*5_000 || fee < 120_000*5_000*99/100 {
+				t.Errorf("优先费总额应保持不变: 上限=%d 单价=%d", currentUnitLimit(tx), price)
+			}
+		})
+	}
+}
+
+// simulationRPC 模拟 getMultipleAccounts 和 simulateTransaction
+func simulationRPC(t *testing.T, simErr interface{}, logs []string, tokensAfter uint64) *httptest.Server {
+	tokenAccount := func(amount uint64) map[string]interface{} {
+		data := make([]byte, 165)
+		binary.LittleEndian.PutUint64(data[64:], amount)
+		return map[string]interface{}{
+			"lamports": 2_039_280, "owner": solana.TokenProgramID.String(), "executable": false, "rentEpoch": 0,
+			"data": []string{base64.StdEncoding.EncodeToString(data), "base64"},
+		}
+	}
+	wallet := func(lamports uint64) map[string]interface{} {
+		return map[string]interface{}{
+			"lamports": lamports, "owner": solana.SystemProgramID.String(), "executable": false, "rentEpoch": 0,
+			"data": []string{"", "base64"},
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var result interface{}
+		switch req.Method {
+		case "getMultipleAccounts":
+			result = map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": []interface{}{wallet(1_000_000_000), nil}}
+		case "simulateTransaction":
+			result = map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": map[string]interface{}{
+				"err": simErr, "logs": logs, "unitsConsumed": 50_000,
+				"accounts": []interface{}{wallet(1_000_000_000 - 12_039_280), tokenAccount(tokensAfter)},
+			}}
+		default:
+			t.Errorf("未预期的RPC调用: %s", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func TestPreflight(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	w, err := wallet.FromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FromPrivateKey() error = %v", err)
+	}
+	signer = w
+	tuneComputeUnits = true
+	defer func() { signer, chainClient, mints, tuneComputeUnits = nil, nil, nil, false }()
+
+	mint := solana.MustPublicKeyFromBase58("7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump")
+	mints = mintinfo.NewCache(nil)
+	mints.Put(&mintinfo.Info{Mint: mint, Program: solana.TokenProgramID, Decimals: 6})
+	newTx := func(t *testing.T) *solana.Transaction {
+		tx, err := pump.BuildBuyTransaction(pump.TradeParams{
+			Mint: mint, Creator: key.PublicKey(), User: key.PublicKey(),
+			TokenAmount: 1, SolLimit: 1, ComputeUnitLimit: 120_000, ComputeUnitPrice: 5_000,
+		}, solana.Hash{})
+		if err != nil {
+			t.Fatalf("BuildBuyTransaction() error = %v", err)
+		}
+		if err := signer.SignTransaction(tx); err != nil {
+			t.Fatalf("SignTransaction() error = %v", err)
+		}
+		return tx
+	}
+
+	t.Run("模拟通过", func(t *testing.T) {
+		server := simulationRPC(t, nil, []string{"Program log: Instruction: Buy"}, 4_900_000)
+		defer server.Close()
+		chainClient = solclient.New(server.URL, context.Background())
+
+		tx := newTx(t)
+		before := tx.Signatures[0]
+		sim, err := preflight(tx, common.BUY, mint)
+		if err != nil {
+			t.Fatalf("preflight() error = %v", err)
+		}
+		if sim.TokenDelta != 4_900_000 || sim.SolDelta != -12_039_280 || sim.UnitLimit != 60_000 {
+			t.Errorf("preflight() = %+v", sim)
+		}
+		if tx.Signatures[0] == before {
+			t.Errorf("调整计算单元后应重新签名")
+		}
+		if got, ok := Simulated(tx.Signatures[0].String()); !ok || got != sim {
+			t.Errorf("Simulated() 应返回记录的预检结果")
+		}
+	})
+
+	t.Run("模拟失败取消发送", func(t *testing.T) {
+		server := simulationRPC(t,
+			map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": 6002}}},
+			[]string{"Program log: AnchorError occurred. Error Code: TooMuchSolRequired. Error Number: 6002."}, 0)
+		defer server.Close()
+		chainClient = solclient.New(server.URL, context.Background())
+
+		_, err := preflight(newTx(t), common.BUY, mint)
+		if !errors.Is(err, ErrSlippageExceeded) {
+			t.Fatalf("preflight() error = %v, want ErrSlippageExceeded", err)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package chainTx
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pump_auto/internal/inspect"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const maxTradeRetries = 15 // 最多重试15次
+
+var tradeRetryInterval = 2 * time.Second
+
+// errStatusUnknown 查询之前尝试的状态失败，此时无法确认是否已上链，不能重新组装
+var errStatusUnknown = errors.New("之前交易的状态未知")
+
+// tradeAttempt 一次已签名的交易尝试，签名在发送前就已确定
+type tradeAttempt struct {
+	tx                   *solana.Transaction
+	signature            solana.Signature
+	lastValidBlockHeight uint64
+	failed               bool // 已上链但执行失败，不会再成功
+}
+
+// sendAttempt 发送已签名的交易
+// 无论发送是否报错都登记签名: 超时的请求可能已经被节点转发并上链
+func sendAttempt(tx *solana.Transaction, lastValidBlockHeight uint64) (*tradeAttempt, error) {
+	attempt := &tradeAttempt{
+		tx:                   tx,
+		signature:            tx.Signatures[0],
+		lastValidBlockHeight: lastValidBlockHeight,
+	}
+	trackSignature(attempt.signature, lastValidBlockHeight)
+
+	if err := submit(tx); err != nil {
+		return attempt, fmt.Errorf("发送交易失败: %w", decodeRPCError(err))
+	}
+	log.Printf("交易发送成功: https://solscan.io/tx/%s", attempt.signature)
+	return attempt, nil
+}
+
+// tradeWithRetry 重试交易，保证同一笔买卖最多上链一次
+// 每次重试前先检查之前的尝试是否已上链；上一笔交易的区块哈希仍有效时原样重发(签名相同，链上去重)，
+// 只有在之前的尝试全部失败或过期后才重新组装；遇到不可重试的错误立即返回
+func tradeWithRetry(label string, execute func() (*tradeAttempt, error)) (string, error) {
+	var attempts []*tradeAttempt
+	var err error
+
+	for i := 0; i < maxTradeRetries; i++ {
+		var height uint64
+		if i > 0 {
+			time.Sleep(tradeRetryInterval)
+			// 先取区块高度再查状态: 高度已超过有效期而状态为空的交易不可能再上链
+			height = blockHeight()
+			landed, checkErr := landedAttempt(attempts)
+			if landed != nil {
+				log.Printf("%s: 之前的交易 %s 已上链，不再重试", label, landed.signature)
+				return landed.signature.String(), nil
+			}
+			if checkErr != nil && !retryable(checkErr) {
+				return "", fmt.Errorf("%s失败: %w", label, checkErr)
+			}
+			// 状态未知时只允许原样重发，继续查询直到状态明确或重试次数用尽
+			if errors.Is(checkErr, errStatusUnknown) && liveAttempt(attempts, height) == nil {
+				err = checkErr
+				log.Printf("第 %d 次%s: %v，等待%v后重新查询...", i+1, label, checkErr, tradeRetryInterval)
+				continue
+			}
+		}
+
+		var attempt *tradeAttempt
+		if last := liveAttempt(attempts, height); last != nil {
+			log.Printf("%s: 区块哈希仍有效，重发交易 %s", label, last.signature)
+			attempt, err = resend(last)
+		} else {
+			attempt, err = execute()
+			if attempt != nil {
+				attempts = append(attempts, attempt)
+			}
+		}
+		if err == nil {
+			return attempt.signature.String(), nil
+		}
+		if !retryable(err) {
+			return "", fmt.Errorf("%s失败: %w", label, err)
+		}
+		log.Printf("第 %d 次%s失败: %v，等待%v后重试...", i+1, label, err, tradeRetryInterval)
+	}
+
+	landed, checkErr := landedAttempt(attempts)
+	if landed != nil {
+		return landed.signature.String(), nil
+	}
+	if errors.Is(checkErr, errStatusUnknown) {
+		err = checkErr
+	}
+	return "", fmt.Errorf("%s失败，已达到最大重试次数: %w", label, err)
+}
+
+// landedAttempt 查询之前的尝试，返回成功上链的一笔
+// 上链但执行失败的尝试被标记为 failed，失败原因不可重试时一并返回；查询失败时返回 errStatusUnknown
+func landedAttempt(attempts []*tradeAttempt) (*tradeAttempt, error) {
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+	signatures := make([]solana.Signature, len(attempts))
+	for i, a := range attempts {
+		signatures[i] = a.signature
+	}
+	out, err := chainClient.GetSignatureStatuses(false, signatures...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errStatusUnknown, err)
+	}
+	var failure error
+	for i, status := range out.Value {
+		if status == nil || i >= len(attempts) {
+			continue
+		}
+		if status.Err == nil {
+			return attempts[i], nil
+		}
+		attempts[i].failed = true
+		failure = fmt.Errorf("交易 %s: %w", attempts[i].signature, decodeChainError(status.Err, nil))
+	}
+	return nil, failure
+}
+
+// liveAttempt 返回区块哈希仍然有效、可以原样重发的最后一笔尝试
+func liveAttempt(attempts []*tradeAttempt, height uint64) *tradeAttempt {
+	if len(attempts) == 0 {
+		return nil
+	}
+	last := attempts[len(attempts)-1]
+	if last.failed || last.tx == nil {
+		return nil
+	}
+	// 高度未知时也原样重发: 签名相同不会重复成交，哈希过期只会发送失败
+	if height != 0 && height >= last.lastValidBlockHeight {
+		return nil
+	}
+	return last
+}
+
+// blockHeight 获取当前区块高度，优先读取区块哈希缓存，失败时返回0
+func blockHeight() uint64 {
+	if blockhashes != nil {
+		if height, ok := blockhashes.BlockHeight(); ok {
+			return height
+		}
+	}
+	height, err := chainClient.GetBlockHeight(rpc.CommitmentConfirmed)
+	if err != nil {
+		log.Printf("获取区块高度失败: %v", err)
+		return 0
+	}
+	return height
+}
+
+// resend 原样重发已签名的交易，节点提示已处理说明交易已经上链
+func resend(attempt *tradeAttempt) (*tradeAttempt, error) {
+	if err := submit(attempt.tx); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "already been processed") {
+			return attempt, nil
+		}
+		return attempt, fmt.Errorf("重发交易失败: %w", decodeRPCError(err))
+	}
+	return attempt, nil
+}
+
+// retryable 判断错误是否值得重试: 请求无效、滑点、余额不足、代币已迁移、API拒绝和签名前校验失败都不重试
+func retryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	var violation *inspect.Violation
+	if errors.As(err, &violation) {
+		return false
+	}
+	for _, terminal := range []error{ErrInvalidRequest, ErrSlippageExceeded, ErrInsufficientSOL, ErrInsufficientTokens, ErrTokenMigrated, ErrAPIRejected} {
+		if errors.Is(err, terminal) {
+			return false
+		}
+	}
+	return true
+}
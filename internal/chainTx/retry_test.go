@@ -0,0 +1,192 @@
+package chainTx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pump_auto/internal/curve"
+	"pump_auto/internal/inspect"
+	solclient "pump_auto/internal/solana"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "网络超时", err: fmt.Errorf("发送交易失败: context deadline exceeded"), want: true},
+		{name: "区块哈希过期", err: fmt.Errorf("发送交易失败: %w", ErrBlockhashExpired), want: true},
+		{name: "API暂时不可用", err: decodeAPIError(502, []byte("bad gateway")), want: true},
+		{name: "滑点", err: fmt.Errorf("发送交易失败: %w", decodeRPCError(errors.New("custom program error: 0x1772"))), want: false},
+		{name: "SOL不足", err: &TradeError{Kind: ErrInsufficientSOL, Err: errors.New("x")}, want: false},
+		{name: "请求无效", err: fmt.Errorf("%w: 未知的交易动作", ErrInvalidRequest), want: false},
+		{name: "曲线已完成", err: fmt.Errorf("计算买入报价失败: %w", curve.ErrCurveComplete), want: false},
+		{name: "交易校验失败", err: &inspect.Violation{Reason: inspect.ErrMintMismatch, Detail: "x"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// sends fakeRPC 收到的 sendTransaction 次数
+var sends int
+
+// failStatuses 为 true 时 fakeRPC 的 getSignatureStatuses 返回错误
+var failStatuses bool
+
+// fakeRPC 只实现重试逻辑用到的 getSignatureStatuses、getBlockHeight 和 sendTransaction
+func fakeRPC(t *testing.T, landed map[string]interface{}) *httptest.Server {
+	sends = 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{}     `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "getBlockHeight":
+			result = 100
+		case "sendTransaction":
+			sends++
+			result = solana.Signature{9}.String()
+		case "getSignatureStatuses":
+			if failStatuses {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32005, "message": "node is behind"}})
+				return
+			}
+			var params [][]string
+			_ = json.Unmarshal(req.Params, &params)
+			values := []interface{}{}
+			for _, sig := range params[0] {
+				values = append(values, landed[sig])
+			}
+			result = map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": values}
+		default:
+			t.Errorf("未预期的RPC调用: %s", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func TestTradeWithRetry(t *testing.T) {
+	tradeRetryInterval = time.Millisecond
+	defer func() { tradeRetryInterval, chainClient = 2*time.Second, nil }()
+
+	sentSig := solana.Signature{1}
+	key, _ := solana.NewRandomPrivateKey()
+	signed, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("x"))},
+		solana.Hash{}, solana.TransactionPayer(key.PublicKey()),
+	)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	if _, err := signed.Sign(func(solana.PublicKey) *solana.PrivateKey { return &key }); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		landed    map[string]interface{}
+		errs      []error
+		lastValid uint64
+		statusErr bool
+		wantSig   string
+		wantErr   bool
+		wantCalls int
+		wantSends int
+	}{
+		{
+			name:      "发送超时但已上链",
+			landed:    map[string]interface{}{sentSig.String(): map[string]interface{}{"slot": 5, "err": nil, "confirmationStatus": "confirmed"}},
+			errs:      []error{errors.New("发送交易失败: context deadline exceeded")},
+			wantSig:   sentSig.String(),
+			wantCalls: 1,
+		},
+		{
+			name:      "未上链时重新组装",
+			errs:      []error{errors.New("发送交易失败: context deadline exceeded"), nil},
+			wantSig:   solana.Signature{2}.String(),
+			wantCalls: 2,
+		},
+		{
+			name:      "区块哈希有效时原样重发",
+			errs:      []error{errors.New("发送交易失败: context deadline exceeded")},
+			lastValid: 200,
+			wantSig:   solana.Signature{1}.String(),
+			wantCalls: 1,
+			wantSends: 1,
+		},
+		{
+			name:      "状态查询失败时不重新组装",
+			errs:      []error{errors.New("发送交易失败: context deadline exceeded"), nil},
+			statusErr: true,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "滑点立即停止",
+			errs:      []error{decodeRPCError(errors.New("custom program error: 0x1772"))},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "之前的尝试因余额不足失败",
+			landed: map[string]interface{}{sentSig.String(): map[string]interface{}{
+				"slot": 5, "err": map[string]interface{}{"InsufficientFundsForFee": nil}, "confirmationStatus": "confirmed",
+			}},
+			errs:      []error{errors.New("发送交易失败: context deadline exceeded"), nil},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeRPC(t, tt.landed)
+			defer server.Close()
+			failStatuses = tt.statusErr
+			defer func() { failStatuses = false }()
+			chainClient = solclient.New(server.URL, context.Background())
+
+			calls := 0
+			sig, err := tradeWithRetry("测试交易", func() (*tradeAttempt, error) {
+				calls++
+				// 当前高度为100，lastValidBlockHeight 更低时不会原样重发
+				lastValid := tt.lastValid
+				if lastValid == 0 {
+					lastValid = 50
+				}
+				return &tradeAttempt{tx: signed, signature: solana.Signature{byte(calls)}, lastValidBlockHeight: lastValid}, tt.errs[calls-1]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tradeWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sig != tt.wantSig {
+				t.Errorf("tradeWithRetry() = %s, want %s", sig, tt.wantSig)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("组装交易 %d 次，期望 %d 次", calls, tt.wantCalls)
+			}
+			if sends != tt.wantSends {
+				t.Errorf("原样重发 %d 次，期望 %d 次", sends, tt.wantSends)
+			}
+		})
+	}
+}
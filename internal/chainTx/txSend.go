@@ -2,7 +2,6 @@ package chainTx
 
 import (
 	"context"
-	"encoding/binary"
 	"fmt"
 	"io"
 	"log"
@@ -10,103 +9,150 @@ import (
 	"net/http"
 	"net/url"
 	"pump_auto/internal/common"
-	"strconv"
+	"pump_auto/internal/config"
+	"pump_auto/internal/inspect"
+	"pump_auto/internal/mintinfo"
+	"pump_auto/internal/model"
+	solclient "pump_auto/internal/solana"
+	"pump_auto/internal/txparse"
+	"pump_auto/internal/wallet"
 	"strings"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
 	bin "github.com/gagliardetto/binary"
 
 	"github.com/gagliardetto/solana-go"
-	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
 )
 
+// 运行时配置，由 Init 注入
+var (
+	signer           wallet.Signer
+	engine           string
+	computeUnitLimit uint32
+	inspectPortalTx  bool
+	portalFeeBps     uint64
+	commitment       rpc.CommitmentType
+	simulateBuy      bool
+	simulateSell     bool
+	tuneComputeUnits bool
+	chainClient      *solclient.Client
+	blockhashes      *solclient.BlockhashCache
+	confirmer        *solclient.Confirmer
+	mints            *mintinfo.Cache
+)
 
-const PUBLIC_KEY = "5zUyGNwtCCyYthLqUzYEDhYhfRrK9eHNf4DQ4KhQEirm"
+// Init 使用运行时配置和钱包初始化交易模块，必须在发起交易前调用
+func Init(cfg *config.Config, w wallet.Signer) error {
+	if w == nil {
+		return fmt.Errorf("钱包不能为空")
+	}
+	signer = w
+	engine = cfg.Trade.Engine
+	computeUnitLimit = cfg.Trade.ComputeUnitLimit
+	inspectPortalTx = cfg.Trade.InspectPortalTx
+	portalFeeBps = cfg.Trade.PortalFeeBps
+	commitment = rpc.CommitmentType(cfg.RPC.Commitment)
+	if confirmer != nil {
+		confirmer.Close()
+	}
+	if blockhashes != nil {
+		blockhashes.Close()
+	}
+	if chainClient != nil {
+		chainClient.Close()
+	}
+	simulateBuy = cfg.Trade.SimulateBuy
+	simulateSell = cfg.Trade.SimulateSell
+	tuneComputeUnits = cfg.Trade.TuneComputeUnits
+	chainClient = solclient.NewPool(context.Background(), rpcEndpoints(cfg.RPC)...)
+	blockhashes = solclient.NewBlockhashCache(chainClient, solclient.DefaultBlockhashInterval)
+	confirmer = solclient.NewConfirmer(chainClient, blockhashes, cfg.RPC.WebsocketURL(), commitment)
+	mints = mintinfo.NewCache(chainClient)
+	initFeeOracle(cfg)
+	initBundler(cfg)
+	initJanitor(cfg)
+	return nil
+}
 
-// params
-type TradeRequest struct {
-	PublicKey        string             `json:"publicKey"`
-	Action           common.TradeAction `json:"action"`
-	Mint             string             `json:"mint"`
-	Amount           float64            `json:"amount"`
-	DenominatedInSol string             `json:"denominatedInSol"`
-	Slippage         int                `json:"slippage"`
-	PriorityFee      float64            `json:"priorityFee"`
-	Pool             common.PoolType    `json:"pool"`
+// rpcEndpoints 返回主节点和额外节点
+func rpcEndpoints(cfg config.RPCConfig) []solclient.Endpoint {
+	timeout := cfg.RequestTimeout.Std()
+	endpoints := []solclient.Endpoint{{URL: cfg.URL, RateLimit: cfg.RateLimit, Timeout: timeout}}
+	for _, e := range cfg.Endpoints {
+		endpoints = append(endpoints, solclient.Endpoint{URL: e.URL, RateLimit: e.RateLimit, Timeout: timeout})
+	}
+	return endpoints
 }
-type TradeRequestPercent struct {
+
+// TradeRequest pumpportal trade-local 接口的请求参数
+type TradeRequest struct {
 	PublicKey        string             `json:"publicKey"`
 	Action           common.TradeAction `json:"action"`
 	Mint             string             `json:"mint"`
-	Amount           string             `json:"amount"`
+	Amount           string             `json:"amount"` // 精确的十进制数量或百分比，如 "100%"
 	DenominatedInSol string             `json:"denominatedInSol"`
 	Slippage         int                `json:"slippage"`
 	PriorityFee      float64            `json:"priorityFee"`
 	Pool             common.PoolType    `json:"pool"`
 }
 
-func ExecuteTrade(action common.TradeAction, mint string, amount float64, sellPercent string, denominatedInSol bool, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
-	// 解析私钥（只解析一次）
-	privateKey, err := solana.PrivateKeyFromBase58(PRIVATE_KEY)
+// ExecuteTrade 组装、签名并发送一笔交易，返回交易签名
+// 买入时花费 sol，卖出时卖出 tokens，sellPercent 为 "100%" 时卖出全部余额
+func ExecuteTrade(action common.TradeAction, mint string, sol model.Lamports, tokens model.TokenAmount, sellPercent string, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
+	attempt, err := executeTrade(action, mint, sol, tokens, sellPercent, slippage, priorityFee, pool)
 	if err != nil {
-		return "", fmt.Errorf("解析私钥失败: %v", err)
+		return "", err
 	}
+	return attempt.signature.String(), nil
+}
 
-	// 使用已定义的公钥
-	publicKey, err := solana.PublicKeyFromBase58(PUBLIC_KEY)
+// executeTrade 返回已签名的交易，发送失败时 attempt 仍然有效，交易可能已经上链
+func executeTrade(action common.TradeAction, mint string, sol model.Lamports, tokens model.TokenAmount, sellPercent string, slippage int, priorityFee float64, pool common.PoolType) (*tradeAttempt, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("%w: 交易模块未初始化，请先调用 chainTx.Init", ErrInvalidRequest)
+	}
+	// native 只能组装联合曲线指令，迁移后的AMM池交易仍由 pumpportal 构建
+	if engine == config.EngineNative && pool == common.PUMP {
+		return executeNativeTrade(action, mint, sol, tokens, sellPercent, slippage, priorityFee, pool)
+	}
+	mintKey, err := solana.PublicKeyFromBase58(mint)
 	if err != nil {
-		return "", fmt.Errorf("解析公钥失败: %v", err)
-	}
-
-	var request interface{}
-	if sellPercent == "100%" && denominatedInSol == false {
-		request = &TradeRequestPercent{
-			PublicKey:        publicKey.String(),
-			Action:           action,
-			Mint:             mint,
-			Amount:           sellPercent,
-			DenominatedInSol: fmt.Sprintf("%t", denominatedInSol),
-			Slippage:         slippage,
-			PriorityFee:      priorityFee,
-			Pool:             pool,
-		}
-	} else {
-		request = &TradeRequest{
-			PublicKey:        publicKey.String(),
-			Action:           action,
-			Mint:             mint,
-			Amount:           amount,
-			DenominatedInSol: fmt.Sprintf("%t", denominatedInSol),
-			Slippage:         slippage,
-			PriorityFee:      priorityFee,
-			Pool:             pool,
-		}
+		return nil, fmt.Errorf("%w: 无效的代币地址: %v", ErrInvalidRequest, err)
+	}
+
+	// 数量按最小单位精确格式化，不经过浮点数
+	r := &TradeRequest{
+		PublicKey:        signer.PublicKey().String(),
+		Action:           action,
+		Mint:             mint,
+		DenominatedInSol: "false",
+		Slippage:         slippage,
+		PriorityFee:      priorityFee,
+		Pool:             pool,
+	}
+	switch {
+	case action == common.BUY:
+		r.Amount = sol.String()
+		r.DenominatedInSol = "true"
+	case sellPercent == "100%":
+		r.Amount = sellPercent
+	case tokens.IsZero():
+		return nil, fmt.Errorf("%w: 卖出数量为0", ErrInvalidRequest)
+	default:
+		r.Amount = tokens.String()
 	}
 
 	// 准备交易请求
 	data := url.Values{}
-	switch r := request.(type) {
-	case *TradeRequest:
-		data.Set("publicKey", r.PublicKey)
-		data.Set("action", string(r.Action))
-		data.Set("mint", r.Mint)
-		data.Set("amount", fmt.Sprintf("%f", r.Amount))
-		data.Set("denominatedInSol", r.DenominatedInSol)
-		data.Set("slippage", fmt.Sprintf("%d", r.Slippage))
-		data.Set("priorityFee", fmt.Sprintf("%f", r.PriorityFee))
-		data.Set("pool", string(r.Pool))
-	case *TradeRequestPercent:
-		data.Set("publicKey", r.PublicKey)
-		data.Set("action", string(r.Action))
-		data.Set("mint", r.Mint)
-		data.Set("amount", r.Amount)
-		data.Set("denominatedInSol", r.DenominatedInSol)
-		data.Set("slippage", fmt.Sprintf("%d", r.Slippage))
-		data.Set("priorityFee", fmt.Sprintf("%f", r.PriorityFee))
-		data.Set("pool", string(r.Pool))
-	}
+	data.Set("publicKey", r.PublicKey)
+	data.Set("action", string(r.Action))
+	data.Set("mint", r.Mint)
+	data.Set("amount", r.Amount)
+	data.Set("denominatedInSol", r.DenominatedInSol)
+	data.Set("slippage", fmt.Sprintf("%d", r.Slippage))
+	data.Set("priorityFee", fmt.Sprintf("%f", r.PriorityFee))
+	data.Set("pool", string(r.Pool))
 
 	// 打印请求数据
 	log.Printf("发送交易请求数据:")
@@ -126,7 +172,7 @@ func ExecuteTrade(action common.TradeAction, mint string, amount float64, sellPe
 		strings.NewReader(data.Encode()),
 	)
 	if err != nil {
-		return "", fmt.Errorf("发送交易请求失败: %v", err)
+		return nil, fmt.Errorf("发送交易请求失败: %v", err)
 	}
 	defer resp.Body.Close()
 
@@ -135,16 +181,30 @@ func ExecuteTrade(action common.TradeAction, mint string, amount float64, sellPe
 
 	transactionBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %v", err)
+		return nil, fmt.Errorf("读取响应失败: %v", err)
 	}
 
 	// 添加API响应日志
 	log.Printf("API响应内容: %s", string(transactionBytes))
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeAPIError(resp.StatusCode, transactionBytes)
+	}
 
 	// 解析并签名交易
 	tx, err := solana.TransactionFromBytes(transactionBytes)
 	if err != nil {
-		return "", fmt.Errorf("解析交易失败: %v", err)
+		return nil, fmt.Errorf("解析交易失败: %v", err)
+	}
+
+	// 签名前校验交易内容与请求一致，校验规则只覆盖联合曲线交易(auto 在迁移前也走联合曲线)
+	if inspectPortalTx {
+		if pool == common.PUMP || pool == common.AUTO {
+			if err := inspectPortalTransaction(tx, action, mint, sol, tokens, sellPercent, slippage, priorityFee); err != nil {
+				return nil, err
+			}
+		} else {
+			log.Printf("交易池 %s 不是联合曲线，跳过 pumpportal 交易内容校验", pool)
+		}
 	}
 
 	// // 添加详细日志
@@ -158,127 +218,166 @@ func ExecuteTrade(action common.TradeAction, mint string, amount float64, sellPe
 	// 	log.Printf("- 账户 %d: %s", i, acc.String())
 	// }
 
-	// 获取最新区块哈希
-	client := rpc.New(RPC_URL)
-	recent, err := client.GetLatestBlockhash(context.Background(), rpc.CommitmentFinalized)
+	// 使用缓存的最新区块哈希
+	recent, err := blockhashes.Get()
 	if err != nil {
-		return "", fmt.Errorf("获取区块哈希失败: %v", err)
+		return nil, err
 	}
-
-	// 更新区块哈希
-	tx.Message.RecentBlockhash = recent.Value.Blockhash
+	tx.Message.RecentBlockhash = recent.Hash
 
 	// 签名交易
-	_, err = tx.Sign(
-		func(pubkey solana.PublicKey) *solana.PrivateKey {
-			if pubkey.Equals(publicKey) {
-				return &privateKey
-			}
-			return nil
-		},
-	)
-	if err != nil {
-		return "", fmt.Errorf("签名交易失败: %v", err)
+	if err := signer.SignTransaction(tx); err != nil {
+		return nil, err
 	}
 
-	// 发送交易
-	txSign, err := client.SendTransaction(context.Background(), tx)
-	if err != nil {
-		return "", fmt.Errorf("发送交易失败: %v", err)
+	// 按动作预检，模拟失败时不发送
+	if shouldSimulate(action) {
+		if _, err := preflight(tx, action, mintKey); err != nil {
+			return nil, err
+		}
 	}
 
-	log.Printf("交易发送成功: https://solscan.io/tx/%s", txSign.String())
-	return txSign.String(), nil
+	// 发送交易
+	return sendAttempt(tx, recent.LastValidBlockHeight)
 }
 
-func BuyToken(mint string, amount float64, denominatedInSol bool, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
-	fmt.Printf("mint:%s,amout:%f,pool:%s", mint, amount, pool)
-
-	// 设置轮询参数
-	maxRetries := 15 // 最多重试15次
-	retryInterval := 2 * time.Second
+// inspectPortalTransaction 校验 pumpportal 返回的交易只包含预期的程序、代币、数量和SOL花费
+// 代币数量和卖出的最少获得按本地读取的联合曲线报价核对，读取失败时不签名
+func inspectPortalTransaction(tx *solana.Transaction, action common.TradeAction, mint string, sol model.Lamports, tokens model.TokenAmount, sellPercent string, slippage int, priorityFee float64) error {
+	mintKey, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return fmt.Errorf("%w: 无效的代币地址: %v", ErrInvalidRequest, err)
+	}
 
-	var sign string
-	var err error
+	// 优先费和(买入时)带滑点的花费上限；服务费由 FeeAllowanceBps 按比例放行
+	want := inspect.Expectation{
+		Action:          action,
+		Mint:            mintKey,
+		User:            signer.PublicKey(),
+		MaxSolOut:       solToLamports(priorityFee),
+		FeeAllowanceBps: portalFeeBps,
+	}
 
-	// 开始重试循环
-	for i := 0; i < maxRetries; i++ {
-		sign, err = ExecuteTrade(common.BUY, mint, amount, "", true, slippage, priorityFee, pool)
-		if err == nil {
-			return sign, nil // 如果成功，直接返回
+	bondingCurve, err := loadBondingCurve(mintKey, action)
+	if err != nil {
+		return fmt.Errorf("读取联合曲线失败，无法校验交易: %w", err)
+	}
+	switch action {
+	case common.BUY:
+		want.MaxSolOut += uint64(sol)*uint64(100+slippage)/100 + inspect.AssociatedTokenRent
+		quote, err := bondingCurve.BuyQuote(uint64(sol))
+		if err != nil {
+			return fmt.Errorf("计算买入报价失败: %w", err)
 		}
-
-		log.Printf("第 %d 次购买代币 %s 失败: %v，等待2秒后重试...", i+1, mint, err)
-		time.Sleep(retryInterval)
+		if slippage < 100 {
+			want.Tokens = quote.TokenAmount * uint64(100-slippage) / 100
+		}
+	case common.SELL:
+		if sellPercent == "100%" {
+			if tokens, err = GetTokenBalance(mint); err != nil {
+				return err
+			}
+		}
+		quote, err := bondingCurve.SellQuote(tokens.Raw)
+		if err != nil {
+			return fmt.Errorf("计算卖出报价失败: %w", err)
+		}
+		want.Tokens = tokens.Raw
+		want.MinSolOut = quote.WithSlippage(false, slippage)
 	}
 
-	return "", fmt.Errorf("购买代币 %s 失败，已达到最大重试次数: %v", mint, err)
+	report, err := inspect.Inspect(tx, want)
+	if err != nil {
+		log.Printf("拒绝签名 pumpportal 返回的交易: %v", err)
+		return err
+	}
+	log.Printf("交易校验通过: 代币数量=%d SOL上限/下限=%d 最多花费=%d lamports", report.PumpAmount, report.PumpSolLimit, report.SolOut)
+	return nil
 }
 
-func SellToken(mint string, amount float64, sellPercent string, denominatedInSol bool, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
-	// 设置轮询参数
-	maxRetries := 15 // 最多重试15次
-	retryInterval := 2 * time.Second
-
-	var sign string
-	var err error
-
-	// 开始重试循环
-	for i := 0; i < maxRetries; i++ {
-		sign, err = ExecuteTrade(common.SELL, mint, amount, sellPercent, false, slippage, priorityFee, pool)
-		if err == nil {
-			return sign, nil // 如果成功，直接返回
-		}
-
-		log.Printf("第 %d 次出售代币 %s 失败: %v，等待2秒后重试...", i+1, mint, err)
-		time.Sleep(retryInterval)
+func solToLamports(sol float64) uint64 {
+	if sol <= 0 {
+		return 0
 	}
+	return uint64(math.Round(sol * float64(solana.LAMPORTS_PER_SOL)))
+}
 
-	return "", fmt.Errorf("出售代币 %s 失败，已达到最大重试次数: %v", mint, err)
+// BuyToken 买入代币，失败时重试，已上链的尝试不会重复买入
+func BuyToken(mint string, sol model.Lamports, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
+	log.Printf("mint:%s,amout:%s,pool:%s", mint, sol, pool)
+	sign, err := tradeWithRetry(fmt.Sprintf("购买代币 %s", mint), func() (*tradeAttempt, error) {
+		return executeTrade(common.BUY, mint, sol, model.TokenAmount{}, "", slippage, priorityFee, pool)
+	})
+	if err == nil {
+		watchCurve(mint, true)
+	}
+	return sign, err
 }
 
-// GetTokenDecimal 获取代币的精度
-func GetTokenDecimal(mint string) (uint8, error) {
-	client := rpc.New(RPC_URL)
+// SellToken 卖出代币，失败时重试，已上链的尝试不会重复卖出
+func SellToken(mint string, tokens model.TokenAmount, sellPercent string, slippage int, priorityFee float64, pool common.PoolType) (string, error) {
+	sign, err := tradeWithRetry(fmt.Sprintf("出售代币 %s", mint), func() (*tradeAttempt, error) {
+		return executeTrade(common.SELL, mint, 0, tokens, sellPercent, slippage, priorityFee, pool)
+	})
+	if err == nil && sellPercent == "100%" {
+		watchCurve(mint, false)
+	}
+	return sign, err
+}
 
-	// 将mint地址转换为PublicKey
+// MintInfo 返回代币的精度、代币程序、权限和转账手续费等信息，结果会被缓存
+func MintInfo(mint string) (*mintinfo.Info, error) {
+	if mints == nil {
+		return nil, fmt.Errorf("交易模块未初始化，请先调用 chainTx.Init")
+	}
 	mintPubkey, err := solana.PublicKeyFromBase58(mint)
 	if err != nil {
-		return 0, fmt.Errorf("无效的代币地址: %v", err)
+		return nil, fmt.Errorf("无效的代币地址: %v", err)
 	}
-
-	// 使用 GetAccountDataInto 直接获取代币信息
-	var mintInfo token.Mint
-	err = client.GetAccountDataInto(
-		context.Background(),
-		mintPubkey,
-		&mintInfo,
-	)
+	info, err := mints.Get(mintPubkey)
 	if err != nil {
-		return 0, fmt.Errorf("获取代币信息失败: %v", err)
+		return nil, fmt.Errorf("获取代币信息失败: %w", err)
 	}
-
-	// 打印代币信息用于调试
-	log.Printf("代币信息: 精度=%d, 供应量=%d", mintInfo.Decimals, mintInfo.Supply)
-
-	return mintInfo.Decimals, nil
+	return info, nil
 }
 
-// GetTokenBalance 获取用户对特定代币的余额
-func GetTokenBalance(mint string) (float64, error) {
-	client := rpc.New(RPC_URL)
+// GetTokenDecimal 返回代币精度
+func GetTokenDecimal(mint string) (uint8, error) {
+	info, err := MintInfo(mint)
+	if err != nil {
+		return 0, err
+	}
+	return info.Decimals, nil
+}
 
-	// 使用已定义的公钥
-	publicKey, err := solana.PublicKeyFromBase58(PUBLIC_KEY)
+// GetSolBalance 获取钱包的SOL余额
+func GetSolBalance() (model.Lamports, error) {
+	if signer == nil {
+		return 0, fmt.Errorf("交易模块未初始化，请先调用 chainTx.Init")
+	}
+	var out *rpc.GetBalanceResult
+	err := chainClient.Do(func(ctx context.Context, client *rpc.Client) (err error) {
+		out, err = client.GetBalance(ctx, signer.PublicKey(), rpc.CommitmentConfirmed)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("解析公钥失败: %v", err)
+		return 0, fmt.Errorf("获取SOL余额失败: %v", err)
+	}
+	return model.Lamports(out.Value), nil
+}
+
+// GetTokenBalance 获取用户对特定代币的余额，按配置的确认级别读取钱包在该代币下全部账户的余额之和
+func GetTokenBalance(mint string) (model.TokenAmount, error) {
+	if signer == nil {
+		return model.TokenAmount{}, fmt.Errorf("交易模块未初始化，请先调用 chainTx.Init")
 	}
 
-	// 将mint地址转换为PublicKey
-	mintPubkey, err := solana.PublicKeyFromBase58(mint)
+	// 精度和代币程序以 Mint 账户为准
+	info, err := MintInfo(mint)
 	if err != nil {
-		return 0, fmt.Errorf("无效的代币地址: %v", err)
+		return model.TokenAmount{}, err
 	}
+	mintPubkey := info.Mint
 
 	// 设置轮询参数
 	maxRetries := 15 // 最多等待30秒
@@ -286,61 +385,44 @@ func GetTokenBalance(mint string) (float64, error) {
 
 	for i := 0; i < maxRetries; i++ {
 		// 获取用户的代币账户
-		tokenAccounts, err := client.GetTokenAccountsByOwner(
-			context.Background(),
-			publicKey,
-			&rpc.GetTokenAccountsConfig{
-				Mint: &mintPubkey,
-			},
-			&rpc.GetTokenAccountsOpts{
-				Encoding: solana.EncodingBase64,
-			},
-		)
+		accounts, err := walletTokenAccounts(&mintPubkey)
 		if err != nil {
 			log.Printf("第 %d 次获取代币账户失败: %v，等待1秒后重试...", i+1, err)
 			time.Sleep(retryInterval)
 			continue
 		}
 
-		if len(tokenAccounts.Value) == 0 {
+		if len(accounts) == 0 {
 			log.Printf("第 %d 次检查：用户没有代币 %s 的账户，等待1秒后重试...", i+1, mint)
 			time.Sleep(retryInterval)
 			continue
 		}
 
-		tokenVault := solana.MustPublicKeyFromBase58(tokenAccounts.Value[0].Pubkey.String())
-		// 获取代币账户余额
-		balance, err := client.GetTokenAccountBalance(
-			context.Background(),
-			tokenVault,
-			rpc.CommitmentFinalized,
-		)
-		if err != nil {
-			log.Printf("第 %d 次获取代币余额失败: %v，等待1秒后重试...", i+1, err)
-			time.Sleep(retryInterval)
-			continue
-		}
-
-		// 将余额字符串转换为 float64
-		result, err := strconv.ParseFloat(balance.Value.Amount, 64)
-		if err != nil {
-			log.Printf("第 %d 次转换余额失败: %v，等待1秒后重试...", i+1, err)
-			time.Sleep(retryInterval)
-			continue
+		// 同一代币可能有多个账户(如 ATA 之外的账户)，余额取全部账户之和
+		var raw uint64
+		for _, account := range accounts {
+			raw += account.Amount
 		}
+		result := model.NewTokenAmount(raw, info.Decimals)
 
-		// 除以代币的decimal（6）
-		result = result / math.Pow10(6)
-
-		log.Printf("代币 %s 余额: %f", mint, result)
+		log.Printf("代币 %s 余额: %s", mint, result)
 		return result, nil
 	}
 
-	return 0, fmt.Errorf("获取代币 %s 余额超时，已重试 %d 次", mint, maxRetries)
+	return model.TokenAmount{}, fmt.Errorf("获取代币 %s 余额超时，已重试 %d 次", mint, maxRetries)
 }
-func ParseTxSign(txSig solana.Signature) (float64, error) {
-	client := rpc.New(RPC_URL)
+
+// ParseTx 查询交易并解析其中钱包的 pump 成交结果，指令可以位于任意位置(包括 CPI)
+func ParseTx(txSig solana.Signature) (*txparse.Result, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("交易模块未初始化，请先调用 chainTx.Init")
+	}
 	var maxVersion uint64 = 0
+	// getTransaction 不支持 processed
+	txCommitment := commitment
+	if txCommitment == rpc.CommitmentProcessed {
+		txCommitment = rpc.CommitmentConfirmed
+	}
 
 	// 设置轮询参数
 	maxRetries := 20 // 60秒 / 3秒 = 20次
@@ -351,110 +433,61 @@ func ParseTxSign(txSig solana.Signature) (float64, error) {
 
 	// 开始轮询查询
 	for i := 0; i < maxRetries; i++ {
-		out, err = client.GetTransaction(
-			context.Background(),
-			txSig,
-			&rpc.GetTransactionOpts{
-				Encoding:                       solana.EncodingBase64,
-				MaxSupportedTransactionVersion: &maxVersion,
-			},
-		)
-		if err == nil {
+		err = chainClient.Do(func(ctx context.Context, client *rpc.Client) (err error) {
+			out, err = client.GetTransaction(
+				ctx,
+				txSig,
+				&rpc.GetTransactionOpts{
+					Encoding:                       solana.EncodingBase64,
+					Commitment:                     txCommitment,
+					MaxSupportedTransactionVersion: &maxVersion,
+				},
+			)
+			return err
+		})
+		if err == nil && out != nil && out.Meta != nil && out.Transaction != nil {
 			break // 如果查询成功，跳出循环
 		}
+		if err == nil {
+			err = fmt.Errorf("交易 %s 尚无元数据", txSig)
+		}
 
-		fmt.Printf("第 %d 次查询交易失败: %v，等待3秒后重试...\n", i+1, err)
+		log.Printf("第 %d 次查询交易失败: %v，等待3秒后重试...", i+1, err)
 		time.Sleep(retryInterval)
 	}
-
 	if err != nil {
-		fmt.Printf("查询交易失败，已达到最大重试次数: %v\n", err)
-		return 0, err
+		return nil, fmt.Errorf("查询交易失败，已达到最大重试次数: %w", err)
 	}
 
 	// 解码交易
 	decodedTx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(out.Transaction.GetBinary()))
 	if err != nil {
-		fmt.Printf("解码交易失败: %v\n", err)
-		return 0, err
+		return nil, fmt.Errorf("解码交易失败: %w", err)
 	}
 
-	// 获取交易元数据
-	meta, err := client.GetTransaction(
-		context.Background(),
-		txSig,
-		&rpc.GetTransactionOpts{
-			Encoding:                       solana.EncodingBase64,
-			MaxSupportedTransactionVersion: &maxVersion,
-		},
-	)
+	result, err := txparse.Parse(decodedTx, out.Meta, signer.PublicKey(), solana.PublicKey{})
 	if err != nil {
-		fmt.Printf("获取交易元数据失败: %v\n", err)
-		return 0, err
+		return nil, err
 	}
-
-	// 检查元数据
-	if meta.Meta == nil {
-		fmt.Println("交易元数据为空")
-		return 0, err
+	if result.Failed {
+		return result, fmt.Errorf("交易 %s: %w", txSig, decodeChainError(result.Err, out.Meta.LogMessages))
 	}
-
-	// 查找第4条指令的内嵌指令（索引 3）
-	var innerInstructions []solana.CompiledInstruction
-	for _, inner := range meta.Meta.InnerInstructions {
-		if inner.Index == 3 { // 第4条指令的内嵌指令
-			innerInstructions = inner.Instructions
-			break
+	// 交易元数据中没有该代币的余额记录时只能假定 pump 的精度，以 Mint 账户为准
+	if mints != nil && !result.Mint.IsZero() {
+		if info, err := mints.Get(result.Mint); err == nil {
+			result.Decimals = info.Decimals
 		}
 	}
+	return result, nil
+}
 
-	if len(innerInstructions) == 0 {
-		fmt.Println("第4条指令没有内嵌指令")
-		return 0, err
-	}
-
-	if len(innerInstructions) < 1 {
-		fmt.Printf("内嵌指令数量不足，期望至少 1 条，实际 %d 条\n", len(innerInstructions))
-		return 0, err
-	}
-
-	// 提取第1条内嵌指令（索引 0）
-	targetInnerInstruction := innerInstructions[0]
-	fmt.Println("第4条指令的第1条内嵌指令:")
-	spew.Dump(targetInnerInstruction)
-
-	// 检查账户数量
-	if len(targetInnerInstruction.Accounts) < 5 {
-		fmt.Printf("内嵌指令账户数量不足，期望至少 5 个，实际 %d 个\n", len(targetInnerInstruction.Accounts))
-		return 0, err
-	}
-	// 映射账户到 PumpfunBuyInstruction
-	pumpfunInstruction := common.PumpfunBuyInstruction{
-		Mint: decodedTx.Message.AccountKeys[targetInnerInstruction.Accounts[2]],
-		User: decodedTx.Message.AccountKeys[targetInnerInstruction.Accounts[6]],
-	}
-
-	// 解析指令数据
-	data := targetInnerInstruction.Data
-	if len(data) < 16 { // 确保数据长度足够
-		fmt.Printf("指令数据长度不足，期望至少 16 字节，实际 %d 字节\n", len(data))
-		return 0, err
-	}
-
-	// 解析 AmountOut
-	buyInstruction := common.BuyInstruction{
-		Amount:     binary.LittleEndian.Uint64(data[8:16]),
-		MaxSolCost: binary.LittleEndian.Uint64(data[16:24]),
+// ParseTxSign 解析买入交易，返回实际获得的代币数量
+func ParseTxSign(txSig solana.Signature) (model.TokenAmount, error) {
+	result, err := ParseTx(txSig)
+	if err != nil {
+		return model.TokenAmount{}, err
 	}
-	pumpfunInstruction.Input = &buyInstruction
-
-	// 打印解析结果
-	fmt.Println("解析的 PumpfunBuyInstruction（内嵌指令）:")
-	spew.Dump(pumpfunInstruction)
-
-	// 格式化输出
-	fmt.Printf("Token: %s\n", pumpfunInstruction.Mint)
-	fmt.Printf("User Wallet: %s\n", pumpfunInstruction.User)
-	fmt.Printf("AmountOut: %d (%.6f tokens, 精度 6)\n", buyInstruction.Amount, float64(buyInstruction.Amount)/1_000_000)
-	return float64(buyInstruction.Amount) / 1_000_000, nil
+	received := model.NewTokenAmount(result.TokensReceived(), result.Decimals)
+	log.Printf("交易 %s: %s 代币 %s，获得 %d (%s)", txSig, result.Action, result.Mint, received.Raw, received)
+	return received, nil
 }
@@ -1,48 +1,75 @@
 package chainTx
 
 import (
+	"os"
 	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/model"
+	"pump_auto/internal/pump"
+	"pump_auto/internal/wallet"
 	"testing"
 
 	"github.com/gagliardetto/solana-go"
 )
 
+// envLiveConfig 连接主网的测试使用的配置文件路径，未设置时跳过这些测试
+// 买入和卖出用例会用配置中的钱包发送真实交易
+const envLiveConfig = "PUMP_LIVE_CONFIG"
+
+// initLive 按 PUMP_LIVE_CONFIG 指定的配置初始化交易模块，未设置时跳过测试
+func initLive(t *testing.T) {
+	t.Helper()
+	path := os.Getenv(envLiveConfig)
+	if path == "" {
+		t.Skipf("未设置 %s，跳过连接主网的测试", envLiveConfig)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	w, err := wallet.Load(cfg.Wallet.KeypairPath, cfg.Wallet.KeystorePath, cfg.Wallet.Passphrase)
+	if err != nil {
+		t.Fatalf("加载钱包失败: %v", err)
+	}
+	if err := Init(cfg, w); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+}
+
 func TestBuyToken(t *testing.T) {
+	initLive(t)
 	tests := []struct {
-		name             string
-		mint             string
-		amount           float64
-		denominatedInSol bool
-		slippage         int
-		priorityFee      float64
-		pool             common.PoolType
-		wantErr          bool
+		name        string
+		mint        string
+		amount      float64
+		slippage    int
+		priorityFee float64
+		pool        common.PoolType
+		wantErr     bool
 	}{
 		{
-			name:             "正常买入测试",
-			mint:             "32bKPLHRThqX7r67AvEJD1wccTmeKaWcMwofTDkBpump", // SOL代币地址
-			amount:           0.010000,
-			denominatedInSol: true,
-			slippage:         10,
-			priorityFee:      0.0005,
-			pool:             "pump",
-			wantErr:          false,
+			name:        "正常买入测试",
+			mint:        "32bKPLHRThqX7r67AvEJD1wccTmeKaWcMwofTDkBpump", // SOL代币地址
+			amount:      0.010000,
+			slippage:    10,
+			priorityFee: 0.0005,
+			pool:        "pump",
+			wantErr:     false,
 		},
 		{
-			name:             "无效代币地址测试",
-			mint:             "invalid_mint_address",
-			amount:           0.1,
-			denominatedInSol: true,
-			slippage:         1,
-			priorityFee:      0.000005,
-			pool:             common.RAYDIUM,
-			wantErr:          true,
+			name:        "无效代币地址测试",
+			mint:        "invalid_mint_address",
+			amount:      0.1,
+			slippage:    1,
+			priorityFee: 0.000005,
+			pool:        common.RAYDIUM,
+			wantErr:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			txHash, err := BuyToken(tt.mint, tt.amount, tt.denominatedInSol, tt.slippage, tt.priorityFee, tt.pool)
+			txHash, err := BuyToken(tt.mint, model.SOLToLamports(tt.amount), tt.slippage, tt.priorityFee, tt.pool)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BuyToken() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -55,44 +82,42 @@ func TestBuyToken(t *testing.T) {
 }
 
 func TestSellToken(t *testing.T) {
+	initLive(t)
 	tests := []struct {
-		name             string
-		mint             string
-		amount           float64
-		sellPercent      string
-		denominatedInSol bool
-		slippage         int
-		priorityFee      float64
-		pool             common.PoolType
-		wantErr          bool
+		name        string
+		mint        string
+		amount      float64
+		sellPercent string
+		slippage    int
+		priorityFee float64
+		pool        common.PoolType
+		wantErr     bool
 	}{
 		{
-			name:             "正常卖出测试",
-			mint:             "2kicCkhMhte7k2eqGgWkfPhCbV1EnSnYktxq8HtGpump", // SOL代币地址
-			amount:           1,
-			sellPercent:      "100%",
-			denominatedInSol: false,
-			slippage:         10,
-			priorityFee:      0.000005,
-			pool:             common.PUMP,
-			wantErr:          false,
+			name:        "正常卖出测试",
+			mint:        "2kicCkhMhte7k2eqGgWkfPhCbV1EnSnYktxq8HtGpump", // SOL代币地址
+			amount:      1,
+			sellPercent: "100%",
+			slippage:    10,
+			priorityFee: 0.000005,
+			pool:        common.PUMP,
+			wantErr:     false,
 		},
 		{
-			name:             "无效代币地址测试",
-			mint:             "invalid_mint_address",
-			amount:           0.1,
-			sellPercent:      "100",
-			denominatedInSol: true,
-			slippage:         1,
-			priorityFee:      0.000005,
-			pool:             common.RAYDIUM,
-			wantErr:          true,
+			name:        "无效代币地址测试",
+			mint:        "invalid_mint_address",
+			amount:      0.1,
+			sellPercent: "100",
+			slippage:    1,
+			priorityFee: 0.000005,
+			pool:        common.RAYDIUM,
+			wantErr:     true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			txHash, err := SellToken(tt.mint, tt.amount, tt.sellPercent, tt.denominatedInSol, tt.slippage, tt.priorityFee, tt.pool)
+			txHash, err := SellToken(tt.mint, model.UIToTokenAmount(tt.amount, pump.TokenDecimals), tt.sellPercent, tt.slippage, tt.priorityFee, tt.pool)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("SellToken() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -105,6 +130,7 @@ func TestSellToken(t *testing.T) {
 }
 
 func TestGetTokenBalance(t *testing.T) {
+	initLive(t)
 	tests := []struct {
 		name    string
 		mint    string
@@ -138,16 +164,14 @@ func TestGetTokenBalance(t *testing.T) {
 				t.Errorf("GetTokenBalance() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !tt.wantErr && got < 0 {
-				t.Error("GetTokenBalance() 返回的余额不能为负数")
-			}
 			// 打印余额信息用于调试
-			t.Logf("代币 %s 的余额为: %f", tt.mint, got)
+			t.Logf("代币 %s 的余额为: %s", tt.mint, got)
 		})
 	}
 }
 
 func TestGetTokenDecimal(t *testing.T) {
+	initLive(t)
 	tests := []struct {
 		name    string
 		mint    string
@@ -191,6 +215,7 @@ func TestGetTokenDecimal(t *testing.T) {
 }
 
 func TestParseTxSign(t *testing.T) {
+	initLive(t)
 	tests := []struct {
 		name    string
 		txHash  string
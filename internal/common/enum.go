@@ -18,5 +18,4 @@ const (
 	BONK         PoolType = "bonk"
 )
 
-const MAX_HOLD_TOKEN = 3
 const PRECISION = 16
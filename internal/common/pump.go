@@ -23,3 +23,26 @@ type BuyInstruction struct {
 	Amount     uint64
 	MaxSolCost uint64
 }
+
+// PumpfunSellInstruction 定义 Pumpfun 卖出指令涉及的账户
+type PumpfunSellInstruction struct {
+	Global                 solana.PublicKey
+	FeeReceipt             solana.PublicKey
+	Mint                   solana.PublicKey
+	BondingCurve           solana.PublicKey
+	AssociatedBondingCurve solana.PublicKey
+	AssociatedUser         solana.PublicKey
+	User                   solana.PublicKey
+	SystemProgram          solana.PublicKey
+	CreatorVault           solana.PublicKey
+	TokenProgram           solana.PublicKey
+	EventAuthority         solana.PublicKey
+	Program                solana.PublicKey
+	Input                  *SellInstruction
+}
+
+// SellInstruction 定义卖出指令的输入数据
+type SellInstruction struct {
+	Amount       uint64
+	MinSolOutput uint64
+}
@@ -0,0 +1,570 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"pump_auto/internal/common"
+	"pump_auto/internal/jito"
+	"pump_auto/internal/pump"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Config 运行时配置，启动时从配置文件加载并可被环境变量覆盖
+type Config struct {
+	RPC      RPCConfig      `json:"rpc"`
+	Wallet   WalletConfig   `json:"wallet"`
+	Buy      BuyConfig      `json:"buy"`
+	Sell     SellConfig     `json:"sell"`
+	Trade    TradeConfig    `json:"trade"`
+	Bot      BotConfig      `json:"bot"`
+	Paper    PaperConfig    `json:"paper"`
+	Fee      FeeConfig      `json:"fee"`
+	Jito     JitoConfig     `json:"jito"`
+	Janitor  JanitorConfig  `json:"janitor"`
+	Budget   BudgetConfig   `json:"budget"`
+	Candle   CandleConfig   `json:"candle"`
+	Strategy StrategyConfig `json:"strategy"`
+}
+
+// RPCConfig Solana RPC 节点配置
+type RPCConfig struct {
+	URL            string        `json:"url"`            // RPC 节点地址
+	RateLimit      float64       `json:"rateLimit"`      // url 节点每秒请求数上限，0 表示不限速
+	Endpoints      []RPCEndpoint `json:"endpoints"`      // 额外的RPC节点，交易广播到所有节点，读请求发往健康度最好的节点
+	WSURL          string        `json:"wsUrl"`          // websocket 地址，用于签名订阅，为空时由 url 推导
+	Commitment     string        `json:"commitment"`     // 交易确认级别: processed / confirmed / finalized
+	RequestTimeout Duration      `json:"requestTimeout"` // 单次RPC请求的超时时间，超时计为节点故障并切换到下一个节点
+}
+
+// RPCEndpoint 额外的RPC节点
+type RPCEndpoint struct {
+	URL       string  `json:"url"`       // 节点地址
+	RateLimit float64 `json:"rateLimit"` // 每秒请求数上限，0 表示不限速
+}
+
+// WebsocketURL 返回签名订阅使用的 websocket 地址
+func (r RPCConfig) WebsocketURL() string {
+	if r.WSURL != "" {
+		return r.WSURL
+	}
+	switch {
+	case strings.HasPrefix(r.URL, "https://"):
+		return "wss://" + strings.TrimPrefix(r.URL, "https://")
+	case strings.HasPrefix(r.URL, "http://"):
+		return "ws://" + strings.TrimPrefix(r.URL, "http://")
+	default:
+		return ""
+	}
+}
+
+// WalletConfig 交易钱包配置，KeypairPath 与 KeystorePath 二选一
+type WalletConfig struct {
+	KeypairPath  string `json:"keypairPath"`  // Solana CLI 生成的 id.json
+	KeystorePath string `json:"keystorePath"` // 口令加密的 keystore 文件
+	Passphrase   string `json:"-"`            // keystore 口令，只能通过环境变量注入
+}
+
+// BuyConfig 买入参数
+type BuyConfig struct {
+	AmountSol   float64         `json:"amountSol"`   // 每次买入花费的SOL数量
+	Slippage    int             `json:"slippage"`    // 买入滑点百分比
+	PriorityFee float64         `json:"priorityFee"` // 买入优先费(SOL)
+	Pool        common.PoolType `json:"pool"`        // 交易池类型
+}
+
+// SellConfig 卖出参数
+type SellConfig struct {
+	Slippage     int             `json:"slippage"`     // 卖出滑点百分比
+	PriorityFee  float64         `json:"priorityFee"`  // 卖出优先费(SOL)
+	Pool         common.PoolType `json:"pool"`         // 卖出使用的交易池类型
+	MigratedPool common.PoolType `json:"migratedPool"` // 代币迁移出联合曲线后改用的池子
+	OnMigration  string          `json:"onMigration"`  // 迁移时的处理: hold 继续按策略持有，sell 立即在新池子全部卖出
+}
+
+// 迁移处理方式
+const (
+	MigrationHold = "hold"
+	MigrationSell = "sell"
+)
+
+// 交易构建方式
+const (
+	EnginePortal = "portal" // 通过 pumpportal trade-local 接口获取交易
+	EngineNative = "native" // 本地组装 pump.fun 指令
+)
+
+// 交易模式
+const (
+	ModeLive  = "live"  // 链上真实交易
+	ModePaper = "paper" // 模拟盘，按联合曲线报价虚拟成交
+)
+
+// TradeConfig 交易构建参数
+type TradeConfig struct {
+	Mode             string `json:"mode"`             // live 或 paper
+	Engine           string `json:"engine"`           // portal 或 native
+	ComputeUnitLimit uint32 `json:"computeUnitLimit"` // native 模式下的计算单元上限
+	InspectPortalTx  bool   `json:"inspectPortalTx"`  // 签名前校验 pumpportal 返回的交易
+	PortalFeeBps     uint64 `json:"portalFeeBps"`     // 校验时额外允许的 pumpportal 服务费(万分比)
+	SimulateBuy      bool   `json:"simulateBuy"`      // 买入发送前先模拟，狙击时可关闭以降低延迟
+	SimulateSell     bool   `json:"simulateSell"`     // 卖出发送前先模拟
+	TuneComputeUnits bool   `json:"tuneComputeUnits"` // 按模拟消耗的计算单元收紧上限，优先费总额不变
+}
+
+// FeeConfig 动态优先费配置，关闭时使用 buy/sell 中的固定优先费
+type FeeConfig struct {
+	Dynamic        bool     `json:"dynamic"`        // 按近期优先费分位数估算
+	MinSol         float64  `json:"minSol"`         // 单笔优先费下限(SOL)
+	MaxSol         float64  `json:"maxSol"`         // 单笔优先费上限(SOL)
+	SampleInterval Duration `json:"sampleInterval"` // 采样间隔
+}
+
+// JitoConfig bundle 发送配置，开启后交易连同小费转账以 bundle 提交到 block engine，未上链时回退到RPC发送
+type JitoConfig struct {
+	Enabled       bool     `json:"enabled"`       // 通过 block engine 发送交易
+	Endpoint      string   `json:"endpoint"`      // block engine 地址，测试时可指向本地桩服务
+	TipAccount    string   `json:"tipAccount"`    // 小费账户，为空时随机使用一个主网小费账户
+	TipSol        float64  `json:"tipSol"`        // 每个 bundle 的小费(SOL)
+	StatusTimeout Duration `json:"statusTimeout"` // 等待 bundle 上链的时长，超时后回退到RPC发送
+}
+
+// JanitorConfig 代币账户清理，全部卖出后关闭代币账户回收租金(每个约 0.002 SOL)，仅实盘生效
+type JanitorConfig struct {
+	CloseAccounts bool    `json:"closeAccounts"` // 全部卖出确认后关闭该代币的账户
+	DustTokens    float64 `json:"dustTokens"`    // 关闭前可直接销毁的剩余代币数量上限，0 表示只关闭空账户
+	SweepOnStart  bool    `json:"sweepOnStart"`  // 启动时关闭钱包中所有余额为0的代币账户
+}
+
+// BudgetConfig 买入前的资金检查，为每个持仓预留退出交易的费用
+type BudgetConfig struct {
+	MinBalanceSol    float64 `json:"minBalanceSol"`    // 买入并预留退出费用后钱包至少保留的SOL
+	MaxExposureSol   float64 `json:"maxExposureSol"`   // 所有持仓的买入成本上限，0 表示不限制
+	ExitTransactions int     `json:"exitTransactions"` // 每个持仓预留费用的卖出交易笔数(分批止盈和清仓)
+}
+
+// CandleConfig 持仓代币的K线聚合，供策略查看近期走势
+type CandleConfig struct {
+	Intervals []Duration `json:"intervals"` // K线周期，为空时不聚合
+	Capacity  int        `json:"capacity"`  // 每个代币每个周期保留的K线数量
+}
+
+// StrategyConfig 卖出策略，profiles 中按名称定义策略，每个新持仓选择其中一套
+type StrategyConfig struct {
+	Profile  string                     `json:"profile"`  // 新持仓默认使用的策略
+	Rotate   []string                   `json:"rotate"`   // 新持仓依次轮流使用的策略，为空时都使用 profile
+	Profiles map[string]StrategyProfile `json:"profiles"` // 按名称定义的策略
+}
+
+// StrategyLadder 内置策略: 固定止损、移动止损和止盈阶梯
+const StrategyLadder = "ladder"
+
+// StrategyProfile 一套卖出策略参数
+type StrategyProfile struct {
+	Strategy     string              `json:"strategy"`     // 已注册的策略实现名称，为空时使用 ladder
+	TakeProfit   []TakeProfitSetting `json:"takeProfit"`   // 止盈阶梯，按涨幅从低到高排列
+	TrailingStop []StopLossSetting   `json:"trailingStop"` // 移动止损档位，按激活涨幅从低到高排列，可为空
+	TimeExit     TimeExitConfig      `json:"timeExit"`     // 基于持仓时间和成交间隔的出场规则
+}
+
+// TimeExitConfig 基于时间的出场规则，由执行器按买入时间和最新成交时间定时检查，时长为0的规则不启用
+type TimeExitConfig struct {
+	MaxHold            Duration `json:"maxHold"`            // 最长持仓时间，到期全部卖出
+	Inactivity         Duration `json:"inactivity"`         // 无成交多久后全部卖出，为0时使用 bot.inactivityTimeout
+	InactivityFactor   float64  `json:"inactivityFactor"`   // 大于0时无成交超时取近期平均成交间隔的该倍数，不超过 inactivity
+	InactivityMin      Duration `json:"inactivityMin"`      // 按成交频率缩放后的超时下限
+	ProfitDeadline     Duration `json:"profitDeadline"`     // 买入满该时长时涨幅未达到 profitTargetPct 则全部卖出
+	ProfitTargetPct    float64  `json:"profitTargetPct"`    // 期限到达时要求的涨幅百分比
+	TakeProfitHalfLife Duration `json:"takeProfitHalfLife"` // 止盈涨幅目标随持仓时间减半的周期
+	TakeProfitFloorPct float64  `json:"takeProfitFloorPct"` // 衰减后的止盈涨幅目标不低于原目标的该百分比
+}
+
+// TakeProfitSetting 止盈阶梯的一级: 价格涨幅达到 PriceIncreasePct 后累计卖出买入量的 SellPct
+// 例如 {100, 50} 表示 2x 时累计卖出一半，{900, 100} 表示 10x 时全部卖出
+type TakeProfitSetting struct {
+	PriceIncreasePct float64 `json:"priceIncreasePct"` // 相对买入价的涨幅百分比
+	SellPct          float64 `json:"sellPct"`          // 累计卖出的买入量百分比
+}
+
+// StopLossSetting 移动止损的一档: 最高价涨幅超过 ActivationPct 后激活，
+// 价格从最高价回落 TriggerPct 时卖出剩余持仓的 SellPct，每档对每个持仓只触发一次
+type StopLossSetting struct {
+	ActivationPct float64 `json:"activationPct"` // 激活止损的涨幅百分比(相对买入价)
+	TriggerPct    float64 `json:"triggerPct"`    // 触发止损的跌幅百分比(相对最高价)
+	SellPct       float64 `json:"sellPct"`       // 卖出剩余持仓的百分比
+}
+
+// DefaultProfile 默认策略名称
+const DefaultProfile = "default"
+
+// Active 返回新持仓默认使用的策略，Validate 保证其存在
+func (s StrategyConfig) Active() StrategyProfile {
+	return s.Profiles[s.Profile]
+}
+
+// StrategyName 返回策略实现名称
+func (p StrategyProfile) StrategyName() string {
+	if p.Strategy == "" {
+		return StrategyLadder
+	}
+	return p.Strategy
+}
+
+// defaultTakeProfit 原先硬编码的止盈阶梯: 涨幅每 10% 累计卖出 10%，翻倍时全部卖出
+func defaultTakeProfit() []TakeProfitSetting {
+	levels := make([]TakeProfitSetting, 0, 10)
+	for pct := 10.0; pct <= 100; pct += 10 {
+		levels = append(levels, TakeProfitSetting{PriceIncreasePct: pct, SellPct: pct})
+	}
+	return levels
+}
+
+// validateTakeProfit 校验止盈阶梯: 涨幅和累计卖出比例都严格递增，卖出比例不超过100%
+func validateTakeProfit(name string, levels []TakeProfitSetting) error {
+	if len(levels) == 0 {
+		return fmt.Errorf("策略 %s 缺少 takeProfit 止盈阶梯", name)
+	}
+	for i, level := range levels {
+		if level.PriceIncreasePct <= 0 {
+			return fmt.Errorf("策略 %s 第 %d 级止盈 priceIncreasePct 必须大于0，当前为 %v", name, i+1, level.PriceIncreasePct)
+		}
+		if level.SellPct <= 0 || level.SellPct > 100 {
+			return fmt.Errorf("策略 %s 第 %d 级止盈 sellPct 必须在 (0, 100] 之间，当前为 %v", name, i+1, level.SellPct)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := levels[i-1]
+		if level.PriceIncreasePct <= prev.PriceIncreasePct || level.SellPct <= prev.SellPct {
+			return fmt.Errorf("策略 %s 第 %d 级止盈 {%v, %v} 的涨幅和卖出比例必须都大于上一级 {%v, %v}",
+				name, i+1, level.PriceIncreasePct, level.SellPct, prev.PriceIncreasePct, prev.SellPct)
+		}
+	}
+	return nil
+}
+
+// validateTrailingStop 校验移动止损档位: 激活涨幅严格递增，回落和卖出比例在有效范围内
+func validateTrailingStop(name string, tiers []StopLossSetting) error {
+	for i, tier := range tiers {
+		if tier.ActivationPct <= 0 {
+			return fmt.Errorf("策略 %s 第 %d 档移动止损 activationPct 必须大于0，当前为 %v", name, i+1, tier.ActivationPct)
+		}
+		if tier.TriggerPct <= 0 || tier.TriggerPct >= 100 {
+			return fmt.Errorf("策略 %s 第 %d 档移动止损 triggerPct 必须在 (0, 100) 之间，当前为 %v", name, i+1, tier.TriggerPct)
+		}
+		if tier.SellPct <= 0 || tier.SellPct > 100 {
+			return fmt.Errorf("策略 %s 第 %d 档移动止损 sellPct 必须在 (0, 100] 之间，当前为 %v", name, i+1, tier.SellPct)
+		}
+		if i > 0 && tier.ActivationPct <= tiers[i-1].ActivationPct {
+			return fmt.Errorf("策略 %s 第 %d 档移动止损 activationPct %v 必须大于上一档 %v", name, i+1, tier.ActivationPct, tiers[i-1].ActivationPct)
+		}
+	}
+	return nil
+}
+
+// validateTimeExit 校验时间出场规则
+func validateTimeExit(name string, t TimeExitConfig) error {
+	if t.MaxHold < 0 || t.Inactivity < 0 || t.InactivityMin < 0 || t.ProfitDeadline < 0 || t.TakeProfitHalfLife < 0 {
+		return fmt.Errorf("策略 %s 的 timeExit 时长不能为负数", name)
+	}
+	if t.InactivityFactor < 0 {
+		return fmt.Errorf("策略 %s 的 timeExit.inactivityFactor 不能为负数，当前为 %v", name, t.InactivityFactor)
+	}
+	if t.Inactivity > 0 && t.InactivityMin > t.Inactivity {
+		return fmt.Errorf("策略 %s 的 timeExit.inactivityMin 不能大于 inactivity", name)
+	}
+	if t.ProfitDeadline > 0 && t.ProfitTargetPct <= 0 {
+		return fmt.Errorf("策略 %s 配置了 timeExit.profitDeadline 时 profitTargetPct 必须大于0", name)
+	}
+	if t.TakeProfitFloorPct < 0 || t.TakeProfitFloorPct > 100 {
+		return fmt.Errorf("策略 %s 的 timeExit.takeProfitFloorPct 必须在 [0, 100] 之间，当前为 %v", name, t.TakeProfitFloorPct)
+	}
+	return nil
+}
+
+// BotConfig 机器人运行参数
+type BotConfig struct {
+	MaxHoldToken      int      `json:"maxHoldToken"`      // 同时持有的最大代币数量
+	InactivityTimeout Duration `json:"inactivityTimeout"` // 无交易消息多久后全部卖出，策略未配置 timeExit.inactivity 时使用
+}
+
+// PaperConfig 模拟盘参数
+type PaperConfig struct {
+	InitialSol float64 `json:"initialSol"` // 模拟盘初始SOL余额
+}
+
+// Duration 支持 "30s"、"2m" 形式的 JSON 时长
+type Duration time.Duration
+
+// Std 返回标准库时长
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("时长必须是字符串(如 \"30s\"): %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("无效的时长 %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default 返回默认配置，与原先硬编码的参数保持一致
+func Default() *Config {
+	return &Config{
+		RPC: RPCConfig{
+			Commitment:     "confirmed",
+			RequestTimeout: Duration(5 * time.Second),
+		},
+		Buy: BuyConfig{
+			AmountSol:   0.001,
+			Slippage:    10,
+			PriorityFee: 0.0005,
+			Pool:        common.PUMP,
+		},
+		Sell: SellConfig{
+			Slippage:     20,
+			PriorityFee:  0.0005,
+			Pool:         common.PUMP,
+			MigratedPool: common.PUMP_AMM,
+			OnMigration:  MigrationHold,
+		},
+		Trade: TradeConfig{
+			Mode:             ModeLive,
+			Engine:           EnginePortal,
+			ComputeUnitLimit: pump.DefaultComputeUnitLimit,
+			InspectPortalTx:  true,
+			PortalFeeBps:     100,
+			SimulateBuy:      false,
+			SimulateSell:     true,
+			TuneComputeUnits: true,
+		},
+		Bot: BotConfig{
+			MaxHoldToken:      3,
+			InactivityTimeout: Duration(30 * time.Second),
+		},
+		Paper: PaperConfig{
+			InitialSol: 1,
+		},
+		Fee: FeeConfig{
+			Dynamic:        false,
+			MinSol:         0.00001,
+			MaxSol:         0.005,
+			SampleInterval: Duration(5 * time.Second),
+		},
+		Jito: JitoConfig{
+			Enabled:       false,
+			Endpoint:      jito.DefaultEndpoint,
+			TipSol:        0.0001,
+			StatusTimeout: Duration(5 * time.Second),
+		},
+		Janitor: JanitorConfig{
+			CloseAccounts: true,
+			DustTokens:    0,
+			SweepOnStart:  false,
+		},
+		Budget: BudgetConfig{
+			MinBalanceSol:    0.01,
+			MaxExposureSol:   0,
+			ExitTransactions: 4,
+		},
+		Candle: CandleConfig{
+			Intervals: []Duration{Duration(time.Second), Duration(5 * time.Second), Duration(time.Minute)},
+			Capacity:  300,
+		},
+		Strategy: StrategyConfig{
+			Profile: DefaultProfile,
+			Profiles: map[string]StrategyProfile{
+				DefaultProfile: {TakeProfit: defaultTakeProfit()},
+			},
+		},
+	}
+}
+
+// Load 读取配置文件，应用环境变量覆盖并校验
+// path 为空时只使用默认值和环境变量
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate 校验配置是否可以用于启动
+func (c *Config) Validate() error {
+	if c.RPC.URL == "" {
+		return fmt.Errorf("配置缺少 rpc.url")
+	}
+	if c.RPC.RateLimit < 0 {
+		return fmt.Errorf("rpc.rateLimit 不能为负数")
+	}
+	for i, e := range c.RPC.Endpoints {
+		if e.URL == "" {
+			return fmt.Errorf("rpc.endpoints[%d] 缺少 url", i)
+		}
+		if e.RateLimit < 0 {
+			return fmt.Errorf("rpc.endpoints[%d].rateLimit 不能为负数", i)
+		}
+	}
+	switch c.RPC.Commitment {
+	case "processed", "confirmed", "finalized":
+	default:
+		return fmt.Errorf("rpc.commitment 只能是 processed、confirmed 或 finalized，当前为 %q", c.RPC.Commitment)
+	}
+	if c.RPC.RequestTimeout <= 0 {
+		return fmt.Errorf("rpc.requestTimeout 必须大于0")
+	}
+	if (c.Wallet.KeypairPath == "") == (c.Wallet.KeystorePath == "") {
+		return fmt.Errorf("wallet.keypairPath 与 wallet.keystorePath 必须且只能配置一个")
+	}
+	if c.Wallet.KeystorePath != "" && c.Wallet.Passphrase == "" {
+		return fmt.Errorf("使用keystore时必须通过环境变量 %s 提供口令", EnvKeystorePassphrase)
+	}
+	if c.Buy.AmountSol <= 0 {
+		return fmt.Errorf("buy.amountSol 必须大于0，当前为 %v", c.Buy.AmountSol)
+	}
+	if c.Buy.Slippage < 0 || c.Buy.Slippage > 100 {
+		return fmt.Errorf("buy.slippage 必须在 0-100 之间，当前为 %d", c.Buy.Slippage)
+	}
+	if c.Sell.Slippage < 0 || c.Sell.Slippage > 100 {
+		return fmt.Errorf("sell.slippage 必须在 0-100 之间，当前为 %d", c.Sell.Slippage)
+	}
+	if c.Buy.PriorityFee < 0 || c.Sell.PriorityFee < 0 {
+		return fmt.Errorf("优先费不能为负数")
+	}
+	if c.Buy.Pool == "" {
+		return fmt.Errorf("配置缺少 buy.pool")
+	}
+	if c.Sell.Pool == "" {
+		return fmt.Errorf("配置缺少 sell.pool")
+	}
+	if c.Sell.MigratedPool == "" || c.Sell.MigratedPool == common.PUMP {
+		return fmt.Errorf("sell.migratedPool 必须是迁移后的池子(如 %s)，当前为 %q", common.PUMP_AMM, c.Sell.MigratedPool)
+	}
+	if c.Sell.OnMigration != MigrationHold && c.Sell.OnMigration != MigrationSell {
+		return fmt.Errorf("sell.onMigration 只能是 %s 或 %s，当前为 %q", MigrationHold, MigrationSell, c.Sell.OnMigration)
+	}
+	if c.Trade.Mode != ModeLive && c.Trade.Mode != ModePaper {
+		return fmt.Errorf("trade.mode 只能是 %s 或 %s，当前为 %q", ModeLive, ModePaper, c.Trade.Mode)
+	}
+	if c.Trade.Mode == ModePaper && c.Paper.InitialSol <= 0 {
+		return fmt.Errorf("模拟盘 paper.initialSol 必须大于0")
+	}
+	if c.Trade.Engine != EnginePortal && c.Trade.Engine != EngineNative {
+		return fmt.Errorf("trade.engine 只能是 %s 或 %s，当前为 %q", EnginePortal, EngineNative, c.Trade.Engine)
+	}
+	if c.Trade.Engine == EngineNative && c.Trade.ComputeUnitLimit == 0 {
+		return fmt.Errorf("native 模式下 trade.computeUnitLimit 必须大于0")
+	}
+	if c.Trade.PortalFeeBps > 1_000 {
+		return fmt.Errorf("trade.portalFeeBps 不能超过 1000 (10%%)，当前为 %d", c.Trade.PortalFeeBps)
+	}
+	if c.Fee.Dynamic {
+		if c.Fee.MaxSol <= 0 || c.Fee.MinSol < 0 || c.Fee.MinSol > c.Fee.MaxSol {
+			return fmt.Errorf("fee.minSol/fee.maxSol 无效，需满足 0 <= minSol <= maxSol 且 maxSol > 0")
+		}
+		if c.Fee.SampleInterval <= 0 {
+			return fmt.Errorf("fee.sampleInterval 必须大于0")
+		}
+	}
+	if c.Jito.Enabled {
+		if c.Jito.Endpoint == "" {
+			return fmt.Errorf("开启 jito 时必须配置 jito.endpoint")
+		}
+		if c.Jito.TipSol*float64(solana.LAMPORTS_PER_SOL) < jito.MinTipLamports {
+			return fmt.Errorf("jito.tipSol 不能低于 %d lamports，当前为 %v SOL", jito.MinTipLamports, c.Jito.TipSol)
+		}
+		if c.Jito.TipAccount != "" {
+			if _, err := solana.PublicKeyFromBase58(c.Jito.TipAccount); err != nil {
+				return fmt.Errorf("jito.tipAccount 无效: %w", err)
+			}
+		}
+		if c.Jito.StatusTimeout <= 0 {
+			return fmt.Errorf("jito.statusTimeout 必须大于0")
+		}
+	}
+	if c.Janitor.DustTokens < 0 {
+		return fmt.Errorf("janitor.dustTokens 不能为负数，当前为 %v", c.Janitor.DustTokens)
+	}
+	if c.Budget.MinBalanceSol < 0 || c.Budget.MaxExposureSol < 0 {
+		return fmt.Errorf("budget.minBalanceSol 和 budget.maxExposureSol 不能为负数")
+	}
+	if c.Budget.MaxExposureSol > 0 && c.Budget.MaxExposureSol < c.Buy.AmountSol {
+		return fmt.Errorf("budget.maxExposureSol (%v) 小于单笔买入金额 buy.amountSol (%v)，无法买入", c.Budget.MaxExposureSol, c.Buy.AmountSol)
+	}
+	if c.Budget.ExitTransactions <= 0 {
+		return fmt.Errorf("budget.exitTransactions 必须大于0，当前为 %d", c.Budget.ExitTransactions)
+	}
+	seen := make(map[Duration]bool)
+	for _, interval := range c.Candle.Intervals {
+		if interval <= 0 || seen[interval] {
+			return fmt.Errorf("candle.intervals 中的周期必须大于0且不能重复，当前为 %s", interval.Std())
+		}
+		seen[interval] = true
+	}
+	if len(c.Candle.Intervals) > 0 && c.Candle.Capacity <= 0 {
+		return fmt.Errorf("candle.capacity 必须大于0，当前为 %d", c.Candle.Capacity)
+	}
+	if _, ok := c.Strategy.Profiles[c.Strategy.Profile]; !ok {
+		return fmt.Errorf("strategy.profile %q 未在 strategy.profiles 中定义", c.Strategy.Profile)
+	}
+	for _, name := range c.Strategy.Rotate {
+		if _, ok := c.Strategy.Profiles[name]; !ok {
+			return fmt.Errorf("strategy.rotate 中的 %q 未在 strategy.profiles 中定义", name)
+		}
+	}
+	for name, profile := range c.Strategy.Profiles {
+		// 时间出场规则对所有策略都有同样的含义，配置了就要校验
+		if err := validateTimeExit(name, profile.TimeExit); err != nil {
+			return err
+		}
+		if profile.StrategyName() != StrategyLadder {
+			// 其他策略实现的参数由实现自行解释
+			continue
+		}
+		if err := validateTakeProfit(name, profile.TakeProfit); err != nil {
+			return err
+		}
+		if err := validateTrailingStop(name, profile.TrailingStop); err != nil {
+			return err
+		}
+	}
+	if c.Bot.MaxHoldToken <= 0 {
+		return fmt.Errorf("bot.maxHoldToken 必须大于0，当前为 %d", c.Bot.MaxHoldToken)
+	}
+	if c.Bot.InactivityTimeout <= 0 {
+		return fmt.Errorf("bot.inactivityTimeout 必须大于0")
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"pump_auto/internal/common"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr bool
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "配置文件覆盖默认值",
+			content: `{
+				"rpc": {"url": "http://localhost:8899"},
+				"wallet": {"keypairPath": "id.json"},
+				"buy": {"amountSol": 0.05},
+				"bot": {"inactivityTimeout": "45s"}
+			}`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Buy.AmountSol != 0.05 {
+					t.Errorf("Buy.AmountSol = %v, want 0.05", cfg.Buy.AmountSol)
+				}
+				if cfg.Buy.Slippage != 10 {
+					t.Errorf("未配置的 Buy.Slippage 应保留默认值 10，实际为 %d", cfg.Buy.Slippage)
+				}
+				if cfg.Bot.InactivityTimeout.Std() != 45*time.Second {
+					t.Errorf("InactivityTimeout = %v, want 45s", cfg.Bot.InactivityTimeout.Std())
+				}
+			},
+		},
+		{
+			name:    "环境变量优先于配置文件",
+			content: `{"rpc": {"url": "http://localhost:8899"}, "wallet": {"keypairPath": "id.json"}}`,
+			env: map[string]string{
+				EnvRPCURL:       "http://override:8899",
+				EnvSellSlippage: "35",
+				EnvMaxHoldToken: "5",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.RPC.URL != "http://override:8899" {
+					t.Errorf("RPC.URL = %s, want http://override:8899", cfg.RPC.URL)
+				}
+				if cfg.Sell.Slippage != 35 {
+					t.Errorf("Sell.Slippage = %d, want 35", cfg.Sell.Slippage)
+				}
+				if cfg.Bot.MaxHoldToken != 5 {
+					t.Errorf("Bot.MaxHoldToken = %d, want 5", cfg.Bot.MaxHoldToken)
+				}
+			},
+		},
+		{
+			name:    "卖出池类型来自环境变量",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "buy": {"pool": "raydium"}}`,
+			env:     map[string]string{EnvSellPool: "raydium"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Sell.Pool != common.RAYDIUM {
+					t.Errorf("Sell.Pool = %s, want %s", cfg.Sell.Pool, common.RAYDIUM)
+				}
+			},
+		},
+		{
+			name:    "迁移后池子不能是联合曲线",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "sell": {"migratedPool": "pump"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "迁移处理方式无效",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "sell": {"onMigration": "dump"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "缺少RPC地址",
+			content: `{"wallet": {"keypairPath": "id.json"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "同时配置keypair和keystore",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json", "keystorePath": "wallet.json"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "keystore缺少口令",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keystorePath": "wallet.json"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "keystore口令来自环境变量",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keystorePath": "wallet.json"}}`,
+			env:     map[string]string{EnvKeystorePassphrase: "secret"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Wallet.Passphrase != "secret" {
+					t.Errorf("Wallet.Passphrase 未从环境变量读取")
+				}
+			},
+		},
+		{
+			name:    "未知字段",
+			content: `{"rpc": {"url": "x", "timeout": 3}}`,
+			wantErr: true,
+		},
+		{
+			name:    "滑点越界",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "sell": {"slippage": 150}}`,
+			wantErr: true,
+		},
+		{
+			name:    "确认级别无效",
+			content: `{"rpc": {"url": "x", "commitment": "recent"}, "wallet": {"keypairPath": "id.json"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "websocket地址由RPC地址推导",
+			content: `{"rpc": {"url": "https://rpc.example.com/?key=1"}, "wallet": {"keypairPath": "id.json"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				if got := cfg.RPC.WebsocketURL(); got != "wss://rpc.example.com/?key=1" {
+					t.Errorf("WebsocketURL() = %q", got)
+				}
+				if cfg.RPC.Commitment != "confirmed" {
+					t.Errorf("Commitment = %q, want confirmed", cfg.RPC.Commitment)
+				}
+			},
+		},
+		{
+			name:    "额外RPC节点来自环境变量",
+			content: `{"rpc": {"url": "x", "endpoints": [{"url": "y", "rateLimit": 5}]}, "wallet": {"keypairPath": "id.json"}}`,
+			env:     map[string]string{EnvRPCEndpoints: "http://a:8899, http://b:8899"},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.RPC.Endpoints) != 2 || cfg.RPC.Endpoints[1].URL != "http://b:8899" {
+					t.Errorf("RPC.Endpoints = %+v", cfg.RPC.Endpoints)
+				}
+			},
+		},
+		{
+			name:    "RPC请求超时来自环境变量",
+			content: `{"rpc": {"url": "x", "requestTimeout": "3s"}, "wallet": {"keypairPath": "id.json"}}`,
+			env:     map[string]string{EnvRPCRequestTimeout: "800ms"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.RPC.RequestTimeout.Std() != 800*time.Millisecond {
+					t.Errorf("RPC.RequestTimeout = %v", cfg.RPC.RequestTimeout.Std())
+				}
+			},
+		},
+		{
+			name:    "RPC请求超时为0",
+			content: `{"rpc": {"url": "x", "requestTimeout": "0s"}, "wallet": {"keypairPath": "id.json"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "RPC节点缺少地址",
+			content: `{"rpc": {"url": "x", "endpoints": [{"rateLimit": 5}]}, "wallet": {"keypairPath": "id.json"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "jito小费低于下限",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "jito": {"enabled": true, "tipSol": 0.0000001}}`,
+			wantErr: true,
+		},
+		{
+			name:    "jito地址来自环境变量",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "jito": {"enabled": true}}`,
+			env:     map[string]string{EnvJitoEndpoint: "http://127.0.0.1:9000"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Jito.Endpoint != "http://127.0.0.1:9000" {
+					t.Errorf("Jito.Endpoint = %q", cfg.Jito.Endpoint)
+				}
+			},
+		},
+		{
+			name:    "零头上限为负数",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "janitor": {"dustTokens": -1}}`,
+			wantErr: true,
+		},
+		{
+			name:    "敞口上限来自环境变量",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}}`,
+			env:     map[string]string{EnvMaxExposureSol: "0.5"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Budget.MaxExposureSol != 0.5 {
+					t.Errorf("Budget.MaxExposureSol = %v, want 0.5", cfg.Budget.MaxExposureSol)
+				}
+			},
+		},
+		{
+			name:    "敞口上限小于单笔买入",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "buy": {"amountSol": 0.1}, "budget": {"maxExposureSol": 0.05}}`,
+			wantErr: true,
+		},
+		{
+			name: "自定义止盈阶梯",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"profile": "moonbag", "profiles": {
+				"moonbag": {"takeProfit": [{"priceIncreasePct": 100, "sellPct": 50}, {"priceIncreasePct": 900, "sellPct": 100}]}
+			}}}`,
+			check: func(t *testing.T, cfg *Config) {
+				levels := cfg.Strategy.Active().TakeProfit
+				if len(levels) != 2 || levels[1].PriceIncreasePct != 900 {
+					t.Errorf("Active().TakeProfit = %+v", levels)
+				}
+				if len(cfg.Strategy.Profiles[DefaultProfile].TakeProfit) != 10 {
+					t.Errorf("默认策略应保留")
+				}
+			},
+		},
+		{
+			name: "止盈阶梯未递增",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"profiles": {
+				"default": {"takeProfit": [{"priceIncreasePct": 50, "sellPct": 50}, {"priceIncreasePct": 40, "sellPct": 60}]}
+			}}}`,
+			wantErr: true,
+		},
+		{
+			name: "移动止损回落比例越界",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"profiles": {
+				"default": {"takeProfit": [{"priceIncreasePct": 50, "sellPct": 100}], "trailingStop": [{"activationPct": 30, "triggerPct": 100, "sellPct": 50}]}
+			}}}`,
+			wantErr: true,
+		},
+		{
+			name: "时间出场规则",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"profiles": {
+				"default": {"takeProfit": [{"priceIncreasePct": 50, "sellPct": 100}], "timeExit": {"maxHold": "10m", "profitDeadline": "2m", "profitTargetPct": 20, "takeProfitHalfLife": "5m"}}
+			}}}`,
+			check: func(t *testing.T, cfg *Config) {
+				timeExit := cfg.Strategy.Active().TimeExit
+				if timeExit.MaxHold.Std() != 10*time.Minute || timeExit.ProfitDeadline.Std() != 2*time.Minute || timeExit.TakeProfitHalfLife.Std() != 5*time.Minute {
+					t.Errorf("Active().TimeExit = %+v", timeExit)
+				}
+			},
+		},
+		{
+			name: "盈利期限缺少目标涨幅",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"profiles": {
+				"default": {"takeProfit": [{"priceIncreasePct": 50, "sellPct": 100}], "timeExit": {"profitDeadline": "2m"}}
+			}}}`,
+			wantErr: true,
+		},
+		{
+			name: "其他策略的时间出场规则也要校验",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"profiles": {
+				"default": {"takeProfit": [{"priceIncreasePct": 50, "sellPct": 100}]},
+				"custom": {"strategy": "scripted", "timeExit": {"maxHold": "-1m"}}
+			}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "K线周期重复",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "candle": {"intervals": ["1s", "1s"], "capacity": 10}}`,
+			wantErr: true,
+		},
+		{
+			name:    "轮换策略未定义",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}, "strategy": {"rotate": ["default", "moonbag"]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "策略未定义",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}}`,
+			env:     map[string]string{EnvStrategyProfile: "missing"},
+			wantErr: true,
+		},
+		{
+			name:    "环境变量格式错误",
+			content: `{"rpc": {"url": "x"}, "wallet": {"keypairPath": "id.json"}}`,
+			env:     map[string]string{EnvBuyAmountSol: "abc"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := Load(writeConfig(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
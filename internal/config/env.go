@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"pump_auto/internal/common"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 环境变量覆盖，优先级高于配置文件
+const (
+	EnvRPCURL             = "PUMP_RPC_URL"
+	EnvRPCWSURL           = "PUMP_RPC_WS_URL"
+	EnvRPCEndpoints       = "PUMP_RPC_ENDPOINTS"
+	EnvRPCRequestTimeout  = "PUMP_RPC_REQUEST_TIMEOUT"
+	EnvKeypairPath        = "PUMP_KEYPAIR_PATH"
+	EnvKeystorePath       = "PUMP_KEYSTORE_PATH"
+	EnvKeystorePassphrase = "PUMP_KEYSTORE_PASSPHRASE"
+	EnvBuyAmountSol       = "PUMP_BUY_AMOUNT_SOL"
+	EnvBuySlippage        = "PUMP_BUY_SLIPPAGE"
+	EnvBuyPriorityFee     = "PUMP_BUY_PRIORITY_FEE"
+	EnvBuyPool            = "PUMP_BUY_POOL"
+	EnvSellSlippage       = "PUMP_SELL_SLIPPAGE"
+	EnvSellPriorityFee    = "PUMP_SELL_PRIORITY_FEE"
+	EnvSellPool           = "PUMP_SELL_POOL"
+	EnvTradeEngine        = "PUMP_TRADE_ENGINE"
+	EnvTradeMode          = "PUMP_TRADE_MODE"
+	EnvPaperInitialSol    = "PUMP_PAPER_INITIAL_SOL"
+	EnvFeeMaxSol          = "PUMP_FEE_MAX_SOL"
+	EnvJitoEndpoint       = "PUMP_JITO_ENDPOINT"
+	EnvJitoTipSol         = "PUMP_JITO_TIP_SOL"
+	EnvMaxHoldToken       = "PUMP_MAX_HOLD_TOKEN"
+	EnvMinBalanceSol      = "PUMP_MIN_BALANCE_SOL"
+	EnvMaxExposureSol     = "PUMP_MAX_EXPOSURE_SOL"
+	EnvStrategyProfile    = "PUMP_STRATEGY_PROFILE"
+	EnvInactivityTimeout  = "PUMP_INACTIVITY_TIMEOUT"
+)
+
+func applyEnv(cfg *Config) error {
+	setString(EnvRPCURL, &cfg.RPC.URL)
+	setString(EnvRPCWSURL, &cfg.RPC.WSURL)
+	if v, ok := os.LookupEnv(EnvRPCEndpoints); ok && v != "" {
+		cfg.RPC.Endpoints = nil
+		for _, url := range strings.Split(v, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				cfg.RPC.Endpoints = append(cfg.RPC.Endpoints, RPCEndpoint{URL: url})
+			}
+		}
+	}
+	setString(EnvKeypairPath, &cfg.Wallet.KeypairPath)
+	setString(EnvKeystorePath, &cfg.Wallet.KeystorePath)
+	setString(EnvKeystorePassphrase, &cfg.Wallet.Passphrase)
+	setString(EnvTradeEngine, &cfg.Trade.Engine)
+	setString(EnvTradeMode, &cfg.Trade.Mode)
+	setString(EnvJitoEndpoint, &cfg.Jito.Endpoint)
+	setString(EnvStrategyProfile, &cfg.Strategy.Profile)
+
+	if v, ok := os.LookupEnv(EnvBuyPool); ok && v != "" {
+		cfg.Buy.Pool = common.PoolType(v)
+	}
+	if v, ok := os.LookupEnv(EnvSellPool); ok && v != "" {
+		cfg.Sell.Pool = common.PoolType(v)
+	}
+	if err := setFloat(EnvBuyAmountSol, &cfg.Buy.AmountSol); err != nil {
+		return err
+	}
+	if err := setInt(EnvBuySlippage, &cfg.Buy.Slippage); err != nil {
+		return err
+	}
+	if err := setFloat(EnvBuyPriorityFee, &cfg.Buy.PriorityFee); err != nil {
+		return err
+	}
+	if err := setInt(EnvSellSlippage, &cfg.Sell.Slippage); err != nil {
+		return err
+	}
+	if err := setFloat(EnvSellPriorityFee, &cfg.Sell.PriorityFee); err != nil {
+		return err
+	}
+	if err := setFloat(EnvPaperInitialSol, &cfg.Paper.InitialSol); err != nil {
+		return err
+	}
+	if err := setFloat(EnvFeeMaxSol, &cfg.Fee.MaxSol); err != nil {
+		return err
+	}
+	if err := setFloat(EnvMinBalanceSol, &cfg.Budget.MinBalanceSol); err != nil {
+		return err
+	}
+	if err := setFloat(EnvMaxExposureSol, &cfg.Budget.MaxExposureSol); err != nil {
+		return err
+	}
+	if err := setFloat(EnvJitoTipSol, &cfg.Jito.TipSol); err != nil {
+		return err
+	}
+	if err := setInt(EnvMaxHoldToken, &cfg.Bot.MaxHoldToken); err != nil {
+		return err
+	}
+	if err := setDuration(EnvRPCRequestTimeout, &cfg.RPC.RequestTimeout); err != nil {
+		return err
+	}
+	if err := setDuration(EnvInactivityTimeout, &cfg.Bot.InactivityTimeout); err != nil {
+		return err
+	}
+	return nil
+}
+
+func setString(key string, dst *string) {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		*dst = v
+	}
+}
+
+func setFloat(key string, dst *float64) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("环境变量 %s=%q 不是有效数字: %w", key, v, err)
+	}
+	*dst = parsed
+	return nil
+}
+
+func setInt(key string, dst *int) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("环境变量 %s=%q 不是有效整数: %w", key, v, err)
+	}
+	*dst = parsed
+	return nil
+}
+
+func setDuration(key string, dst *Duration) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("环境变量 %s=%q 不是有效时长: %w", key, v, err)
+	}
+	*dst = Duration(parsed)
+	return nil
+}
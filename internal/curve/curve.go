@@ -0,0 +1,107 @@
+package curve
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"pump_auto/internal/pump"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Anchor 账户鉴别码 sha256("account:BondingCurve")[:8]
+var accountDiscriminator = []byte{23, 183, 248, 55, 96, 216, 172, 96}
+
+// 账户布局: 鉴别码(8) + 5个u64(40) + complete(1) + creator(32)
+const (
+	layoutLenWithoutCreator = 8 + 5*8 + 1
+	layoutLen               = layoutLenWithoutCreator + 32
+)
+
+// pump.fun 曲线的固定参数
+const (
+	InitialVirtualTokenReserves uint64 = 1_073_000_000_000_000
+	InitialVirtualSolReserves   uint64 = 30_000_000_000
+	InitialRealTokenReserves    uint64 = 793_100_000_000_000
+	TokenTotalSupply            uint64 = 1_000_000_000_000_000
+
+	// 虚拟储备与真实储备之间的固定差值
+	virtualTokenOffset = InitialVirtualTokenReserves - InitialRealTokenReserves
+	virtualSolOffset   = InitialVirtualSolReserves
+)
+
+// BondingCurve pump.fun 联合曲线账户
+type BondingCurve struct {
+	VirtualTokenReserves uint64
+	VirtualSolReserves   uint64
+	RealTokenReserves    uint64
+	RealSolReserves      uint64
+	TokenTotalSupply     uint64
+	Complete             bool             // 曲线已完成，代币已迁移
+	Creator              solana.PublicKey // 旧账户可能没有该字段，此时为零值
+}
+
+// Decode 解析联合曲线账户数据
+func Decode(data []byte) (*BondingCurve, error) {
+	if len(data) < layoutLenWithoutCreator {
+		return nil, fmt.Errorf("联合曲线账户数据长度不足，期望至少 %d 字节，实际 %d 字节", layoutLenWithoutCreator, len(data))
+	}
+	if !bytes.Equal(data[:8], accountDiscriminator) {
+		return nil, fmt.Errorf("不是联合曲线账户，鉴别码: %v", data[:8])
+	}
+
+	c := &BondingCurve{
+		VirtualTokenReserves: binary.LittleEndian.Uint64(data[8:16]),
+		VirtualSolReserves:   binary.LittleEndian.Uint64(data[16:24]),
+		RealTokenReserves:    binary.LittleEndian.Uint64(data[24:32]),
+		RealSolReserves:      binary.LittleEndian.Uint64(data[32:40]),
+		TokenTotalSupply:     binary.LittleEndian.Uint64(data[40:48]),
+		Complete:             data[48] != 0,
+	}
+	if len(data) >= layoutLen {
+		copy(c.Creator[:], data[49:81])
+	}
+	return c, nil
+}
+
+// Fetch 从链上读取并解析代币的联合曲线账户
+func Fetch(ctx context.Context, client *rpc.Client, mint solana.PublicKey) (*BondingCurve, error) {
+	address, err := pump.DeriveBondingCurve(mint)
+	if err != nil {
+		return nil, err
+	}
+	out, err := client.GetAccountInfoWithOpts(ctx, address, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentProcessed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取联合曲线账户 %s 失败: %w", address, err)
+	}
+	if out == nil || out.Value == nil || out.Value.Data == nil {
+		return nil, fmt.Errorf("联合曲线账户 %s 不存在", address)
+	}
+	if !out.Value.Owner.Equals(pump.ProgramID) {
+		return nil, fmt.Errorf("联合曲线账户 %s 的所有者不是 pump 程序: %s", address, out.Value.Owner)
+	}
+	return Decode(out.Value.Data.GetBinary())
+}
+
+// FromVirtualReserves 由 pumpportal 推送的虚拟储备还原曲线状态
+// 真实储备按 pump.fun 固定差值推导
+func FromVirtualReserves(virtualSol, virtualTokens uint64, creator solana.PublicKey) *BondingCurve {
+	c := &BondingCurve{
+		VirtualTokenReserves: virtualTokens,
+		VirtualSolReserves:   virtualSol,
+		TokenTotalSupply:     TokenTotalSupply,
+		Creator:              creator,
+	}
+	if virtualTokens > virtualTokenOffset {
+		c.RealTokenReserves = virtualTokens - virtualTokenOffset
+	}
+	if virtualSol > virtualSolOffset {
+		c.RealSolReserves = virtualSol - virtualSolOffset
+	}
+	return c
+}
@@ -0,0 +1,163 @@
+package curve
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func encodeCurve(c BondingCurve, withCreator bool) []byte {
+	size := layoutLenWithoutCreator
+	if withCreator {
+		size = layoutLen
+	}
+	data := make([]byte, size)
+	copy(data, accountDiscriminator)
+	binary.LittleEndian.PutUint64(data[8:], c.VirtualTokenReserves)
+	binary.LittleEndian.PutUint64(data[16:], c.VirtualSolReserves)
+	binary.LittleEndian.PutUint64(data[24:], c.RealTokenReserves)
+	binary.LittleEndian.PutUint64(data[32:], c.RealSolReserves)
+	binary.LittleEndian.PutUint64(data[40:], c.TokenTotalSupply)
+	if c.Complete {
+		data[48] = 1
+	}
+	if withCreator {
+		copy(data[49:], c.Creator[:])
+	}
+	return data
+}
+
+func initialCurve() *BondingCurve {
+	return FromVirtualReserves(InitialVirtualSolReserves, InitialVirtualTokenReserves, solana.PublicKey{})
+}
+
+func TestDecode(t *testing.T) {
+	creator, _ := solana.NewRandomPrivateKey()
+	want := BondingCurve{
+		VirtualTokenReserves: 1_000_000_000_000_000,
+		VirtualSolReserves:   32_000_000_000,
+		RealTokenReserves:    720_000_000_000_000,
+		RealSolReserves:      2_000_000_000,
+		TokenTotalSupply:     TokenTotalSupply,
+		Complete:             true,
+		Creator:              creator.PublicKey(),
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantCreator bool
+		wantErr     bool
+	}{
+		{name: "包含创建者", data: encodeCurve(want, true), wantCreator: true},
+		{name: "旧版账户无创建者", data: encodeCurve(want, false)},
+		{name: "鉴别码错误", data: append([]byte{0, 0, 0, 0, 0, 0, 0, 0}, encodeCurve(want, true)[8:]...), wantErr: true},
+		{name: "长度不足", data: encodeCurve(want, true)[:20], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			expected := want
+			if !tt.wantCreator {
+				expected.Creator = solana.PublicKey{}
+			}
+			if *got != expected {
+				t.Errorf("Decode() = %+v, want %+v", *got, expected)
+			}
+		})
+	}
+}
+
+func TestBuyQuote(t *testing.T) {
+	// 期望值按程序的整数运算手工推导:
+	// net = solIn*10000/10125, tokens = vT - (vS*vT/(vS+net) + 1)
+	// cost = tokens*vS/(vT-tokens) + 1, SolAmount = cost + ceil(cost*125/10000)
+	tests := []struct {
+		name          string
+		solIn         uint64
+		wantTokens    uint64
+		wantSolAmount uint64
+	}{
+		{name: "1 SOL", solIn: solana.LAMPORTS_PER_SOL, wantTokens: 34_199_203_154_141, wantSolAmount: 999_999_999},
+		// 取整后总花费恰好等于预算，不能超过
+		{name: "取整边界 0.01 SOL", solIn: 10_000_000, wantTokens: 353_134_762_944, wantSolAmount: 10_000_000},
+		{name: "取整边界 1000 lamports", solIn: 1_000, wantTokens: 35_301_698, wantSolAmount: 1_000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := initialCurve().BuyQuote(tt.solIn)
+			if err != nil {
+				t.Fatalf("BuyQuote() error = %v", err)
+			}
+			if q.TokenAmount != tt.wantTokens {
+				t.Errorf("TokenAmount = %d, want %d", q.TokenAmount, tt.wantTokens)
+			}
+			if q.SolAmount != tt.wantSolAmount {
+				t.Errorf("SolAmount = %d, want %d", q.SolAmount, tt.wantSolAmount)
+			}
+			if q.SolAmount > tt.solIn {
+				t.Errorf("实际花费 %d 超过预算 %d", q.SolAmount, tt.solIn)
+			}
+			if q.Fee == 0 || q.PriceImpact <= 0 {
+				t.Errorf("手续费和价格影响应大于0: fee=%d impact=%f", q.Fee, q.PriceImpact)
+			}
+		})
+	}
+}
+
+func TestSellQuote(t *testing.T) {
+	c := initialCurve()
+	buy, err := c.BuyQuote(solana.LAMPORTS_PER_SOL)
+	if err != nil {
+		t.Fatalf("BuyQuote() error = %v", err)
+	}
+
+	// 买入后的曲线
+	after := FromVirtualReserves(
+		c.VirtualSolReserves+(buy.SolAmount-buy.Fee),
+		c.VirtualTokenReserves-buy.TokenAmount,
+		solana.PublicKey{},
+	)
+	sell, err := after.SellQuote(buy.TokenAmount)
+	if err != nil {
+		t.Fatalf("SellQuote() error = %v", err)
+	}
+	if sell.SolAmount >= buy.SolAmount {
+		t.Errorf("立即卖出收回 %d 不应超过买入花费 %d", sell.SolAmount, buy.SolAmount)
+	}
+	if min := sell.WithSlippage(false, 20); min >= sell.SolAmount {
+		t.Errorf("WithSlippage() = %d 应小于报价 %d", min, sell.SolAmount)
+	}
+}
+
+func TestProgressAndComplete(t *testing.T) {
+	c := initialCurve()
+	if p := c.Progress(); p != 0 {
+		t.Errorf("初始曲线 Progress() = %f, want 0", p)
+	}
+
+	half := *c
+	half.RealTokenReserves = InitialRealTokenReserves / 2
+	if p := half.Progress(); p < 0.49 || p > 0.51 {
+		t.Errorf("Progress() = %f, want 0.5", p)
+	}
+
+	done := *c
+	done.Complete = true
+	if p := done.Progress(); p != 1 {
+		t.Errorf("已完成曲线 Progress() = %f, want 1", p)
+	}
+	if _, err := done.BuyQuote(solana.LAMPORTS_PER_SOL); !errors.Is(err, ErrCurveComplete) {
+		t.Errorf("已完成曲线应返回 ErrCurveComplete，实际为 %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package curve
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"pump_auto/internal/pump"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// FeeBasisPoints 交易手续费(协议费+创建者费)，以万分比计
+const FeeBasisPoints uint64 = 125
+
+// ErrCurveComplete 曲线已完成，代币已迁移，无法再通过联合曲线交易
+var ErrCurveComplete = errors.New("联合曲线已完成，代币已迁移")
+
+// Quote 报价结果
+type Quote struct {
+	TokenAmount uint64  // 代币数量(最小单位)
+	SolAmount   uint64  // 买入为总花费(含手续费)，卖出为净收入(已扣手续费)，lamports
+	Fee         uint64  // 手续费，lamports
+	PriceImpact float64 // 成交均价相对当前价格的偏离比例，始终为非负数
+}
+
+// Price 当前边际价格，单位为 SOL/代币
+func (c *BondingCurve) Price() float64 {
+	if c.VirtualTokenReserves == 0 {
+		return 0
+	}
+	sol := float64(c.VirtualSolReserves) / float64(solana.LAMPORTS_PER_SOL)
+	tokens := float64(c.VirtualTokenReserves) / math.Pow10(pump.TokenDecimals)
+	return sol / tokens
+}
+
+// MarketCapSol 以当前价格计算的市值(SOL)
+func (c *BondingCurve) MarketCapSol() float64 {
+	supply := c.TokenTotalSupply
+	if supply == 0 {
+		supply = TokenTotalSupply
+	}
+	return c.Price() * float64(supply) / math.Pow10(pump.TokenDecimals)
+}
+
+// Progress 曲线进度，0 表示刚发行，1 表示可迁移
+func (c *BondingCurve) Progress() float64 {
+	if c.Complete || c.RealTokenReserves == 0 {
+		return 1
+	}
+	if c.RealTokenReserves >= InitialRealTokenReserves {
+		return 0
+	}
+	return 1 - float64(c.RealTokenReserves)/float64(InitialRealTokenReserves)
+}
+
+// BuyCost 买入指定数量代币所需的SOL(含手续费)
+func (c *BondingCurve) BuyCost(tokens uint64) (Quote, error) {
+	if err := c.checkTradable(); err != nil {
+		return Quote{}, err
+	}
+	if tokens == 0 {
+		return Quote{}, fmt.Errorf("买入数量不能为0")
+	}
+	if tokens > c.RealTokenReserves || tokens >= c.VirtualTokenReserves {
+		return Quote{}, fmt.Errorf("买入数量 %d 超过曲线剩余代币 %d", tokens, c.RealTokenReserves)
+	}
+
+	// sol = tokens * vS / (vT - tokens) + 1，与程序内的取整方式一致
+	num := mul(tokens, c.VirtualSolReserves)
+	den := new(big.Int).SetUint64(c.VirtualTokenReserves - tokens)
+	cost := num.Div(num, den).Uint64() + 1
+	fee := feeOf(cost)
+
+	return Quote{
+		TokenAmount: tokens,
+		SolAmount:   cost + fee,
+		Fee:         fee,
+		PriceImpact: c.impact(cost, tokens),
+	}, nil
+}
+
+// BuyQuote 花费 solIn lamports(含手续费)可买到的代币数量
+func (c *BondingCurve) BuyQuote(solIn uint64) (Quote, error) {
+	if err := c.checkTradable(); err != nil {
+		return Quote{}, err
+	}
+
+	net := solIn * 10_000 / (10_000 + FeeBasisPoints)
+	if net == 0 {
+		return Quote{}, fmt.Errorf("买入金额过小: %d lamports", solIn)
+	}
+
+	// tokens = vT - vS*vT/(vS+net)，向下取整后再减1，保证实际花费不超过 solIn
+	k := mul(c.VirtualSolReserves, c.VirtualTokenReserves)
+	den := new(big.Int).SetUint64(c.VirtualSolReserves)
+	den.Add(den, new(big.Int).SetUint64(net))
+	remaining := k.Div(k, den)
+	remaining.Add(remaining, big.NewInt(1))
+	tokens := c.VirtualTokenReserves - remaining.Uint64()
+	if tokens > c.RealTokenReserves {
+		tokens = c.RealTokenReserves
+	}
+	if tokens == 0 {
+		return Quote{}, fmt.Errorf("买入金额过小: %d lamports", solIn)
+	}
+
+	return c.BuyCost(tokens)
+}
+
+// SellQuote 卖出指定数量代币可获得的SOL(已扣除手续费)
+func (c *BondingCurve) SellQuote(tokens uint64) (Quote, error) {
+	if err := c.checkTradable(); err != nil {
+		return Quote{}, err
+	}
+	if tokens == 0 {
+		return Quote{}, fmt.Errorf("卖出数量不能为0")
+	}
+
+	// sol = tokens * vS / (vT + tokens)
+	num := mul(tokens, c.VirtualSolReserves)
+	den := new(big.Int).SetUint64(c.VirtualTokenReserves)
+	den.Add(den, new(big.Int).SetUint64(tokens))
+	gross := num.Div(num, den).Uint64()
+	if gross > c.RealSolReserves && c.RealSolReserves > 0 {
+		gross = c.RealSolReserves
+	}
+	fee := feeOf(gross)
+	if fee > gross {
+		fee = gross
+	}
+
+	return Quote{
+		TokenAmount: tokens,
+		SolAmount:   gross - fee,
+		Fee:         fee,
+		PriceImpact: c.impact(gross, tokens),
+	}, nil
+}
+
+// WithSlippage 计算买入的最大花费或卖出的最少获得
+func (q Quote) WithSlippage(buy bool, slippagePct int) uint64 {
+	if buy {
+		return q.SolAmount * uint64(100+slippagePct) / 100
+	}
+	if slippagePct >= 100 {
+		return 0
+	}
+	return q.SolAmount * uint64(100-slippagePct) / 100
+}
+
+func (c *BondingCurve) checkTradable() error {
+	if c.Complete {
+		return ErrCurveComplete
+	}
+	if c.VirtualSolReserves == 0 || c.VirtualTokenReserves == 0 {
+		return fmt.Errorf("联合曲线储备为空")
+	}
+	return nil
+}
+
+// impact 成交均价与边际价格的偏离
+func (c *BondingCurve) impact(sol uint64, tokens uint64) float64 {
+	spot := float64(c.VirtualSolReserves) / float64(c.VirtualTokenReserves)
+	exec := float64(sol) / float64(tokens)
+	return math.Abs(exec/spot - 1)
+}
+
+func feeOf(amount uint64) uint64 {
+	// 向上取整
+	return (amount*FeeBasisPoints + 9_999) / 10_000
+}
+
+func mul(a, b uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+}
@@ -0,0 +1,279 @@
+package execctor
+
+import (
+	"fmt"
+	"math"
+	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/fee"
+	"pump_auto/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fixedStopLossPct 兜底止损: 价格低于买入价的该比例时全部卖出
+const fixedStopLossPct = 0.05
+
+// recentTradeCount 按成交频率缩放无成交超时时参考的最近成交笔数
+const recentTradeCount = 20
+
+func init() {
+	RegisterStrategy(config.StrategyLadder, newLadderStrategy)
+}
+
+// ladderStrategy 内置策略: 兜底止损、移动止损、止盈阶梯和时间出场规则，迁移时按 sell.onMigration 处理
+type ladderStrategy struct {
+	takeProfit    []TakeProfitSetting   // 止盈阶梯，按涨幅从低到高排列
+	trailingStop  []StopLossSetting     // 移动止损档位，按激活涨幅从低到高排列
+	timeExit      config.TimeExitConfig // 时间出场规则，inactivity 已按 bot.inactivityTimeout 补全
+	onMigration   string                // sell.onMigration
+	trailingFired map[int]bool          // 已触发的移动止损档位
+	deadlineDone  bool                  // 盈利期限已检查过
+	tradeTimes    []time.Time           // 最近的成交时间，用于按成交频率缩放无成交超时
+}
+
+func newLadderStrategy(cfg *config.Config, profile config.StrategyProfile) Strategy {
+	timeExit := profile.TimeExit
+	if timeExit.Inactivity == 0 {
+		timeExit.Inactivity = cfg.Bot.InactivityTimeout
+	}
+	return &ladderStrategy{
+		takeProfit:    profile.TakeProfit,
+		trailingStop:  profile.TrailingStop,
+		timeExit:      timeExit,
+		onMigration:   cfg.Sell.OnMigration,
+		trailingFired: make(map[int]bool),
+	}
+}
+
+func (l *ladderStrategy) OnEntry(pos *PriceTrackInfo) []Intent {
+	return nil
+}
+
+// OnTrade 依次检查兜底止损、移动止损和止盈，最多给出一个卖出意图
+func (l *ladderStrategy) OnTrade(pos *PriceTrackInfo, record *TradeRecord) []Intent {
+	l.recordTrade(pos.LastUpdateTime)
+
+	// 1. 兜底止损策略：如果当前最新原始价格低于买入价的5%
+	if pos.CurrentPrice < pos.EntryPrice*(1-fixedStopLossPct) {
+		common.Log.Debug(fmt.Sprintf("进入兜底止损，token--%s,当前价格--%v,买入价格--%v", pos.Mint, pos.CurrentPrice, pos.EntryPrice))
+		return []Intent{{Action: common.SELL, SellAll: true, Urgency: fee.UrgencyStopLoss, Reason: "兜底止损"}}
+	}
+
+	// 2. 移动止损：从最高价回落时按档位卖出
+	if intent, ok := l.checkTrailingStop(pos); ok {
+		common.Log.Info("移动止损已触发")
+		return []Intent{intent}
+	}
+
+	// 3. 检查止盈条件
+	common.Log.Debug("开始检查止盈条件")
+	if intent, ok := l.checkTakeProfit(pos, pos.LastUpdateTime); ok {
+		common.Log.Info("止盈条件已触发")
+		return []Intent{intent}
+	}
+	common.Log.Debug("止盈条件未触发")
+	return nil
+}
+
+// OnTick 检查时间出场规则；止盈目标随时间衰减时，价格不变也可能达到止盈点
+func (l *ladderStrategy) OnTick(pos *PriceTrackInfo, now time.Time) []Intent {
+	if reason, urgency := l.checkTimeExit(pos, now); reason != "" {
+		common.Log.WithFields(logrus.Fields{
+			"token":        pos.Mint,
+			"held":         now.Sub(pos.BuyTime).String(),
+			"sinceTrade":   now.Sub(pos.LastUpdateTime).String(),
+			"currentPrice": pos.CurrentPrice,
+			"entryPrice":   pos.EntryPrice,
+		}).Info(reason)
+		return []Intent{{Action: common.SELL, SellAll: true, Urgency: urgency, Reason: reason}}
+	}
+	if l.timeExit.TakeProfitHalfLife > 0 {
+		if intent, ok := l.checkTakeProfit(pos, now); ok {
+			common.Log.Info("衰减后的止盈条件已触发")
+			return []Intent{intent}
+		}
+	}
+	return nil
+}
+
+// OnMigration sell.onMigration 为 sell 时立即在新池子全部卖出，为 hold 时继续按原策略持有
+func (l *ladderStrategy) OnMigration(pos *PriceTrackInfo, pool common.PoolType) []Intent {
+	if l.onMigration != config.MigrationSell {
+		return nil
+	}
+	return []Intent{{Action: common.SELL, SellAll: true, Urgency: fee.UrgencyEmergency, Reason: "代币迁移"}}
+}
+
+// checkTrailingStop 最高价涨幅超过激活点的档位在价格回落到触发点时卖出
+// 同时触发的多档合并为一笔卖单，依次按各档比例卖出剩余持仓
+func (l *ladderStrategy) checkTrailingStop(pos *PriceTrackInfo) (Intent, bool) {
+	if len(l.trailingStop) == 0 || pos.HighestPrice <= 0 {
+		return Intent{}, false
+	}
+	peakIncreasePct := (pos.HighestPrice - pos.EntryPrice) / pos.EntryPrice
+	drawdownPct := (pos.HighestPrice - pos.CurrentPrice) / pos.HighestPrice
+
+	const epsilon = 1e-8
+	keepBps := uint64(model.MaxBps) // 触发的档位卖出后保留的剩余持仓比例
+	var fired []int
+	for i, tier := range l.trailingStop {
+		if l.trailingFired[i] || peakIncreasePct < tier.ActivationPct/100-epsilon || drawdownPct < tier.TriggerPct/100-epsilon {
+			continue
+		}
+		fired = append(fired, i)
+		keepBps = keepBps * (model.MaxBps - model.PctToBps(tier.SellPct/100)) / model.MaxBps
+	}
+	if len(fired) == 0 {
+		return Intent{}, false
+	}
+	for _, i := range fired {
+		l.trailingFired[i] = true
+	}
+
+	sellAmount := pos.RemainingCoin.Sub(pos.RemainingCoin.MulBps(keepBps))
+	common.Log.WithFields(logrus.Fields{
+		"token":         pos.Mint,
+		"tiers":         fired,
+		"highestPrice":  pos.HighestPrice,
+		"currentPrice":  pos.CurrentPrice,
+		"peakIncrease":  peakIncreasePct * 100,
+		"drawdown":      drawdownPct * 100,
+		"sellAmount":    sellAmount.String(),
+		"remainingCoin": pos.RemainingCoin.String(),
+	}).Info("触发移动止损")
+
+	return Intent{
+		Action:  common.SELL,
+		Tokens:  sellAmount,
+		SellAll: keepBps == 0,
+		Label:   fmt.Sprintf("%g%%", float64(model.MaxBps-keepBps)/100),
+		Urgency: fee.UrgencyStopLoss,
+		Reason:  "移动止损",
+	}, true
+}
+
+// recordTrade 记录成交时间，只保留最近 recentTradeCount 笔
+func (l *ladderStrategy) recordTrade(at time.Time) {
+	l.tradeTimes = append(l.tradeTimes, at)
+	if len(l.tradeTimes) > recentTradeCount {
+		l.tradeTimes = l.tradeTimes[len(l.tradeTimes)-recentTradeCount:]
+	}
+}
+
+// inactivityTimeout 无成交超时: 配置了 inactivityFactor 且有足够成交时取近期平均成交间隔的倍数，
+// 限制在 [inactivityMin, inactivity] 之间，否则使用 inactivity
+func (l *ladderStrategy) inactivityTimeout() time.Duration {
+	limit := l.timeExit.Inactivity.Std()
+	if l.timeExit.InactivityFactor <= 0 || len(l.tradeTimes) < 2 {
+		return limit
+	}
+	first, last := l.tradeTimes[0], l.tradeTimes[len(l.tradeTimes)-1]
+	avgGap := last.Sub(first) / time.Duration(len(l.tradeTimes)-1)
+	timeout := time.Duration(float64(avgGap) * l.timeExit.InactivityFactor)
+	if min := l.timeExit.InactivityMin.Std(); timeout < min {
+		timeout = min
+	}
+	if limit > 0 && timeout > limit {
+		timeout = limit
+	}
+	return timeout
+}
+
+// checkTimeExit 按最长持仓、盈利期限和无成交超时依次检查，触发时返回原因和优先费档位
+func (l *ladderStrategy) checkTimeExit(pos *PriceTrackInfo, now time.Time) (string, fee.Urgency) {
+	held := now.Sub(pos.BuyTime)
+	if maxHold := l.timeExit.MaxHold.Std(); maxHold > 0 && held >= maxHold {
+		return "超过最长持仓时间", fee.UrgencyEmergency
+	}
+
+	// 盈利期限只在到期时检查一次，之后的回落交给止损处理
+	if deadline := l.timeExit.ProfitDeadline.Std(); deadline > 0 && !l.deadlineDone && held >= deadline {
+		l.deadlineDone = true
+		increasePct := (pos.CurrentPrice - pos.EntryPrice) / pos.EntryPrice
+		if increasePct < l.timeExit.ProfitTargetPct/100 {
+			return fmt.Sprintf("%s 内涨幅未达到 %g%%", deadline, l.timeExit.ProfitTargetPct), fee.UrgencyStopLoss
+		}
+	}
+
+	if timeout := l.inactivityTimeout(); timeout > 0 && now.Sub(pos.LastUpdateTime) >= timeout {
+		return fmt.Sprintf("%s 内没有成交", timeout), fee.UrgencyEmergency
+	}
+	return "", ""
+}
+
+// takeProfitDecay 止盈涨幅目标的衰减系数: 每过一个半衰期减半，不低于 takeProfitFloorPct
+func (l *ladderStrategy) takeProfitDecay(held time.Duration) float64 {
+	halfLife := l.timeExit.TakeProfitHalfLife.Std()
+	if halfLife <= 0 || held <= 0 {
+		return 1
+	}
+	return math.Max(math.Pow(0.5, float64(held)/float64(halfLife)), l.timeExit.TakeProfitFloorPct/100)
+}
+
+// checkTakeProfit 按止盈阶梯找到已达到的最高一级，卖到该级的累计比例
+// 配置了 takeProfitHalfLife 时各级涨幅目标按 now 时的持仓时长衰减
+func (l *ladderStrategy) checkTakeProfit(pos *PriceTrackInfo, now time.Time) (Intent, bool) {
+	// 计算价格涨幅，使用16位精度
+	currentPriceIncreasePct := (pos.CurrentPrice - pos.EntryPrice) / pos.EntryPrice
+
+	common.Log.WithFields(logrus.Fields{
+		"token":            pos.Mint,
+		"currentPrice":     pos.CurrentPrice,
+		"entryPrice":       pos.EntryPrice,
+		"priceIncrease":    currentPriceIncreasePct * 100,
+		"soldedPercentage": pos.SoldPercent * 100,
+	}).Debug("价格检查详情")
+
+	// 从最高一级往下找已达到的止盈点
+	const epsilon = 1e-8 // 定义一个小的容差值
+	decay := l.takeProfitDecay(now.Sub(pos.BuyTime))
+	var level *TakeProfitSetting
+	for i := len(l.takeProfit) - 1; i >= 0; i-- {
+		if currentPriceIncreasePct >= l.takeProfit[i].PriceIncreasePct*decay/100-epsilon {
+			level = &l.takeProfit[i]
+			break
+		}
+	}
+	if level == nil {
+		common.Log.Debug("未达到任何止盈点")
+		return Intent{}, false
+	}
+	levelKey := fmt.Sprintf("%s_%g", pos.Mint, level.PriceIncreasePct)
+	targetOverallSellPct := level.SellPct / 100 // 目标总共卖出的原始购买量的百分比
+
+	// 已卖到该级比例时每笔成交和每次 tick 都会走到这里，只在调试时输出
+	if targetOverallSellPct <= pos.SoldPercent {
+		common.Log.WithFields(logrus.Fields{
+			"currentLevel":     levelKey,
+			"soldPercentage":   pos.SoldPercent * 100,
+			"targetPercentage": targetOverallSellPct * 100,
+		}).Debug("当前level 但已卖出比例 大于目标比例")
+		return Intent{}, false
+	}
+	common.Log.Info(fmt.Sprintf("触发 %g%% 止盈点", level.PriceIncreasePct))
+
+	// 按整数最小单位计算: 目标累计卖出量减去已卖出量，不会因累积舍入留下零头或超卖
+	sold := pos.BuyAmount.Sub(pos.RemainingCoin)
+	sellAmount := pos.BuyAmount.MulBps(model.PctToBps(targetOverallSellPct)).Sub(sold)
+	common.Log.WithFields(logrus.Fields{
+		"token":                pos.Mint,
+		"targetPercentage":     targetOverallSellPct * 100,
+		"currentPercentage":    pos.SoldPercent * 100,
+		"sellAmount":           sellAmount.String(),
+		"buyAmount":            pos.BuyAmount.String(),
+		"targetOverallSellPct": targetOverallSellPct,
+		"SoldPercent":          pos.SoldPercent,
+	}).Info("准备执行卖出")
+
+	// 只有最后一级卖出比例为100%时才全部卖出
+	return Intent{
+		Action:  common.SELL,
+		Tokens:  sellAmount,
+		SellAll: level.SellPct >= 100,
+		Label:   fmt.Sprintf("%g%%", level.SellPct),
+		Urgency: fee.UrgencyTakeProfit,
+		Reason:  "止盈",
+	}, true
+}
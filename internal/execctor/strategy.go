@@ -0,0 +1,106 @@
+package execctor
+
+import (
+	"fmt"
+	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/fee"
+	"pump_auto/internal/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// strategyTickInterval 调用 OnTick 的间隔
+var strategyTickInterval = time.Second
+
+// maxExitRetries 卖出失败后在之后的 tick 中重试同一意图的最多次数
+const maxExitRetries = 5
+
+// Intent 策略给出的下单意图，由 TradeExecutor 执行
+type Intent struct {
+	Action  common.TradeAction // common.BUY 加仓，common.SELL 卖出
+	Sol     model.Lamports     // 加仓花费的SOL
+	Tokens  model.TokenAmount  // 卖出数量，超过剩余持仓时按剩余持仓卖出
+	SellAll bool               // 卖出全部剩余持仓，忽略 Tokens
+	Label   string             // 卖出比例的说明，如 "30%"
+	Urgency fee.Urgency        // 决定动态优先费的分位数
+	Reason  string             // 触发原因，用于日志
+}
+
+// Strategy 持仓的出场(和加仓)策略，每个持仓使用独立的实例，可以在实例中保存该持仓的状态
+// 所有回调在持仓锁内调用，pos 只读，可通过 pos.Candles 查看近期K线；返回的意图按顺序执行，没有动作时返回 nil
+type Strategy interface {
+	// OnEntry 买入成交、开始跟踪持仓时调用
+	OnEntry(pos *PriceTrackInfo) []Intent
+	// OnTrade 收到该代币的成交推送并更新价格后调用
+	OnTrade(pos *PriceTrackInfo, record *TradeRecord) []Intent
+	// OnTick 定时调用，用于与成交无关的判断(如持仓时长)
+	OnTick(pos *PriceTrackInfo, now time.Time) []Intent
+	// OnMigration 代币迁移出联合曲线后调用，之后的卖单已改走迁移后的池子
+	OnMigration(pos *PriceTrackInfo, pool common.PoolType) []Intent
+}
+
+// StrategyFactory 按策略配置为一个持仓创建策略实例
+type StrategyFactory func(cfg *config.Config, profile config.StrategyProfile) Strategy
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = make(map[string]StrategyFactory)
+)
+
+// RegisterStrategy 按名称注册策略实现，配置中 strategy.profiles.*.strategy 引用该名称
+// 名称重复或 factory 为空时 panic，应在 init 中调用
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	if factory == nil {
+		panic("execctor: 策略 " + name + " 的 factory 为空")
+	}
+	if _, dup := strategies[name]; dup {
+		panic("execctor: 重复注册策略 " + name)
+	}
+	strategies[name] = factory
+}
+
+// StrategyNames 返回已注册的策略名称
+func StrategyNames() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckStrategies 校验配置中引用的策略都已注册，启动时调用
+func CheckStrategies(cfg *config.Config) error {
+	for name, profile := range cfg.Strategy.Profiles {
+		if _, ok := lookupStrategy(profile.StrategyName()); !ok {
+			return fmt.Errorf("策略配置 %s 引用了未注册的策略 %q，可用: %v", name, profile.StrategyName(), StrategyNames())
+		}
+	}
+	return nil
+}
+
+func lookupStrategy(name string) (StrategyFactory, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	factory, ok := strategies[name]
+	return factory, ok
+}
+
+// newStrategy 按策略配置名称为持仓创建策略实例
+func newStrategy(cfg *config.Config, profileName string) (Strategy, error) {
+	profile, ok := cfg.Strategy.Profiles[profileName]
+	if !ok {
+		return nil, fmt.Errorf("策略配置 %q 不存在", profileName)
+	}
+	factory, ok := lookupStrategy(profile.StrategyName())
+	if !ok {
+		return nil, fmt.Errorf("策略 %q 未注册", profile.StrategyName())
+	}
+	return factory(cfg, profile), nil
+}
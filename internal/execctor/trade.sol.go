@@ -3,13 +3,22 @@ package execctor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"pump_auto/internal/candle"
 	"pump_auto/internal/chainTx"
 	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/curve"
+	"pump_auto/internal/fee"
+	"pump_auto/internal/model"
+	"pump_auto/internal/pump"
+	"pump_auto/internal/trader"
 	"sync"
 	"time"
 
+	"github.com/gagliardetto/solana-go"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,11 +32,6 @@ const (
 	StatusSold                     // 已卖出
 )
 
-const (
-	maxRecentPrices = 100 // 定义固定队列的长度
-	PRECISION       = 16  // 价格计算精度
-)
-
 // WebSocket消息结构
 type WSMessage struct {
 	Method string   `json:"method"`
@@ -57,58 +61,95 @@ type TradeRecord struct {
 
 // 价格跟踪信息
 type PriceTrackInfo struct {
-	Mint           string           // 代币符号
-	EntryPrice     float64          // 买入价格 (下一步处理精度)
-	HighestPrice   float64          // 历史最高价格 (基于原始价格流) (下一步处理精度)
-	CurrentPrice   float64          // 当前最新原始价格 (下一步处理精度)
-	BuyAmount      float64          // 买入数量 (最小单位)
-	RemainingCoin  float64          // 剩余币量 (最小单位)
-	SoldPercent    float64          // 已卖出百分比
-	Status         TokenTradeStatus // 交易状态
-	BuyTime        time.Time        // 买入时间
-	LastUpdateTime time.Time        // 最新原始价格的更新时间
-
-	mutex sync.Mutex // 保护并发访问
+	Mint           string            // 代币符号
+	EntryPrice     float64           // 买入价格 (下一步处理精度)
+	HighestPrice   float64           // 历史最高价格 (基于原始价格流) (下一步处理精度)
+	CurrentPrice   float64           // 当前最新原始价格 (下一步处理精度)
+	BuyAmount      model.TokenAmount // 买入数量 (最小单位)
+	RemainingCoin  model.TokenAmount // 剩余币量 (最小单位)，卖出成交后扣减
+	SoldPercent    float64           // 已卖出百分比
+	Status         TokenTradeStatus  // 交易状态
+	BuyTime        time.Time         // 买入时间
+	LastUpdateTime time.Time         // 最新原始价格的更新时间
+	CostSol        model.Lamports    // 买入(含加仓)花费的SOL
+	Profile        string            // 持仓使用的策略配置名称
+
+	strategy    Strategy           // 持仓的策略实例
+	candles     *candle.Aggregator // 执行器的K线聚合器
+	pendingExit *Intent            // 执行失败的卖出意图，下次 tick 时重试
+	exitRetries int                // pendingExit 已重试的次数
+	mutex       sync.Mutex         // 保护并发访问
 }
 
-// 移动止盈设置
-type TakeProfitSetting struct {
-	PriceIncreasePct float64 // 价格涨幅百分比
-	SellPct          float64 // 卖出百分比
+// Candles 按时间顺序返回该代币在 interval 周期最近 n 根K线(n <= 0 时返回全部)，最后一根可能尚未收盘
+// interval 应为 candle.intervals 中配置的周期，否则返回 nil
+func (p *PriceTrackInfo) Candles(interval time.Duration, n int) []candle.Candle {
+	if p.candles == nil {
+		return nil
+	}
+	return p.candles.Candles(p.Mint, interval, n)
 }
 
-// 移动止损设置
-type StopLossSetting struct {
-	ActivationPct float64 // 激活止损的涨幅百分比
-	TriggerPct    float64 // 触发止损的跌幅百分比
-	SellPct       float64 // 卖出百分比
-}
+// 止盈阶梯的一级，由 strategy 配置提供
+type TakeProfitSetting = config.TakeProfitSetting
+
+// 移动止损的一档，由 strategy 配置提供
+type StopLossSetting = config.StopLossSetting
 
 // 交易执行器
 type TradeExecutor struct {
-	priceTracks map[string]*PriceTrackInfo // 价格跟踪，按代币地址索引
-	mutex       sync.RWMutex               // 保护priceTracks的锁
-	// stopLossRule    StopLossSetting            // 移动止损规则 - 当前策略下不直接使用其参数
-	ctx             context.Context           // 上下文
-	cancel          context.CancelFunc        // 取消函数
-	triggeredLevels map[string]bool           // 已触发的止盈级别
-	onTokenSold     func(tokenAddress string) // 新增字段：代币售出后的回调函数
+	priceTracks     map[string]*PriceTrackInfo // 价格跟踪，按代币地址索引
+	mutex           sync.RWMutex               // 保护priceTracks的锁
+	ctx             context.Context            // 上下文
+	cancel          context.CancelFunc         // 取消函数
+	triggeredLevels map[string]bool            // 已触发的止盈级别
+	onTokenSold     func(tokenAddress string)  // 新增字段：代币售出后的回调函数
+	cfg             *config.Config             // 运行时配置
+	executor        trader.Executor            // 下单执行器，实盘或模拟盘
+	rotateNext      int                        // 下一个新持仓使用 strategy.rotate 中的第几个策略
+	candles         *candle.Aggregator         // 持仓代币的K线
 }
 
 // 创建新的交易执行器
-func NewTradeExecutor(onTokenSoldCallback func(tokenAddress string)) *TradeExecutor {
+func NewTradeExecutor(cfg *config.Config, executor trader.Executor, onTokenSoldCallback func(tokenAddress string)) *TradeExecutor {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &TradeExecutor{
+	t := &TradeExecutor{
 		priceTracks:     make(map[string]*PriceTrackInfo),
 		ctx:             ctx,
 		cancel:          cancel,
 		triggeredLevels: make(map[string]bool),
 		onTokenSold:     onTokenSoldCallback, // 保存回调函数
+		cfg:             cfg,
+		executor:        executor,
+		candles:         newCandles(cfg),
+	}
+	go t.tickLoop(strategyTickInterval)
+	return t
+}
+
+// newCandles 按 candle 配置创建K线聚合器
+func newCandles(cfg *config.Config) *candle.Aggregator {
+	intervals := make([]time.Duration, len(cfg.Candle.Intervals))
+	for i, interval := range cfg.Candle.Intervals {
+		intervals[i] = interval.Std()
+	}
+	return candle.NewAggregator(intervals, cfg.Candle.Capacity)
+}
+
+// nextProfile 选择新持仓使用的策略配置，配置了 strategy.rotate 时轮流使用
+// 调用时 t.mutex 应已被锁定
+func (t *TradeExecutor) nextProfile() string {
+	rotate := t.cfg.Strategy.Rotate
+	if len(rotate) == 0 {
+		return t.cfg.Strategy.Profile
 	}
+	name := rotate[t.rotateNext%len(rotate)]
+	t.rotateNext++
+	return name
 }
 
-func (t *TradeExecutor) ExpectBuyForToken(tokenAddress string, solToSpend float64, OutAmount float64) {
+func (t *TradeExecutor) ExpectBuyForToken(tokenAddress string, solToSpend model.Lamports, OutAmount model.TokenAmount) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
@@ -119,10 +160,19 @@ func (t *TradeExecutor) ExpectBuyForToken(tokenAddress string, solToSpend float6
 		return
 	}
 
-	var initialPrice = solToSpend / OutAmount
-	initialPrice = math.Round(initialPrice*math.Pow10(PRECISION)) / math.Pow10(PRECISION)
+	// 价格只用于策略判断，数量本身保持为整数最小单位
+	var initialPrice = solToSpend.SOL() / OutAmount.UI()
+
+	profile := t.nextProfile()
+	strategy, err := newStrategy(t.cfg, profile)
+	if err != nil {
+		// CheckStrategies 启动时已校验，这里只在配置被绕过时发生
+		common.Log.WithError(err).WithField("token", tokenAddress).Error("创建持仓策略失败，改用默认策略")
+		profile = t.cfg.Strategy.Profile
+		strategy = newLadderStrategy(t.cfg, t.cfg.Strategy.Active())
+	}
 
-	t.priceTracks[tokenAddress] = &PriceTrackInfo{
+	track := &PriceTrackInfo{
 		Mint:           tokenAddress,
 		EntryPrice:     initialPrice,
 		HighestPrice:   initialPrice,
@@ -133,8 +183,13 @@ func (t *TradeExecutor) ExpectBuyForToken(tokenAddress string, solToSpend float6
 		Status:         StatusBought,
 		BuyTime:        time.Now(),
 		LastUpdateTime: time.Now(),
+		CostSol:        solToSpend,
+		Profile:        profile,
+		strategy:       strategy,
+		candles:        t.candles,
 		mutex:          sync.Mutex{},
 	}
+	t.priceTracks[tokenAddress] = track
 
 	// 使用WithFields记录结构体的各个字段
 	common.Log.WithFields(logrus.Fields{
@@ -142,18 +197,21 @@ func (t *TradeExecutor) ExpectBuyForToken(tokenAddress string, solToSpend float6
 		"entryPrice":    initialPrice,
 		"highestPrice":  initialPrice,
 		"currentPrice":  initialPrice,
-		"buyAmount":     OutAmount,
-		"remainingCoin": OutAmount,
+		"buyAmount":     OutAmount.String(),
+		"remainingCoin": OutAmount.String(),
 		"soldPercent":   0,
 		"status":        StatusBought,
 		"buyTime":       time.Now(),
+		"profile":       profile,
 	}).Debug("代币追踪初始化完成")
 
 	common.Log.WithFields(logrus.Fields{
 		"token":        tokenAddress,
-		"solAmount":    solToSpend,
+		"solAmount":    solToSpend.String(),
 		"initialPrice": initialPrice,
 	}).Info("等待买入交易消息")
+
+	go t.runStrategy(track, func(s Strategy) []Intent { return s.OnEntry(track) })
 }
 
 // UpdatePrice 处理原始价格流，更新直接受原始价格影响的字段
@@ -175,145 +233,230 @@ func (t *TradeExecutor) UpdatePrice(tokenAddress string, newRawPrice float64) {
 	// 更新基于原始价格的历史最高价
 	if newRawPrice > track.HighestPrice {
 		track.HighestPrice = newRawPrice
-		common.Log.Info(fmt.Sprintf("代币--%s,价格创新高 %s ", tokenAddress, newRawPrice))
+		common.Log.Info(fmt.Sprintf("代币--%s,价格创新高 %v ", tokenAddress, newRawPrice))
 	}
 }
 
-// 检查并执行策略(止盈/止损)
-func (t *TradeExecutor) checkAndExecuteStrategies(track *PriceTrackInfo, tokenAddress string) {
+// runStrategy 在持仓锁内调用策略回调并执行返回的意图，已卖出的持仓不再处理
+func (t *TradeExecutor) runStrategy(track *PriceTrackInfo, hook func(s Strategy) []Intent) {
 	track.mutex.Lock()
 	defer track.mutex.Unlock()
+	if track.Status == StatusSold || track.strategy == nil {
+		return
+	}
+	for _, intent := range hook(track.strategy) {
+		if track.Status == StatusSold {
+			return
+		}
+		t.executeIntent(track, intent)
+	}
+}
 
-	// 1. 新的兜底止损策略：如果当前最新原始价格低于买入价的5%
-	if track.CurrentPrice < track.EntryPrice*0.95 {
-		common.Log.Debug(fmt.Sprintf("进入兜底止损，token--%s,当前价格--%s,买入价格--%s", tokenAddress, track.CurrentPrice, track.EntryPrice))
+// 检查并执行策略，由持仓的策略根据成交推送决定是否下单
+func (t *TradeExecutor) checkAndExecuteStrategies(track *PriceTrackInfo, record *TradeRecord) {
+	t.runStrategy(track, func(s Strategy) []Intent { return s.OnTrade(track, record) })
+}
 
-		t.executeTokenSellInternal(track, track.SoldPercent, tokenAddress, track.RemainingCoin, fmt.Sprintf("%.0f%%", 1.00*100), false, 20, 0.0005, common.PUMP)
+// executeIntent 执行策略给出的意图，调用时 track 应已被锁定
+func (t *TradeExecutor) executeIntent(track *PriceTrackInfo, intent Intent) {
+	logger := common.Log.WithFields(logrus.Fields{
+		"token":   track.Mint,
+		"profile": track.Profile,
+		"action":  intent.Action,
+		"reason":  intent.Reason,
+	})
+	switch intent.Action {
+	case common.SELL:
+		sellAmount, sellPercent := intent.Tokens.Min(track.RemainingCoin), intent.Label
+		if intent.SellAll {
+			sellAmount, sellPercent = track.RemainingCoin, "100%"
+		}
+		// 卖出后占买入量的累计比例，止盈阶梯据此判断是否还需卖出
+		soldAfter := 1.0
+		if !intent.SellAll && !track.BuyAmount.IsZero() {
+			soldAfter = float64(track.BuyAmount.Sub(track.RemainingCoin).Raw+sellAmount.Raw) / float64(track.BuyAmount.Raw)
+		}
+		logger.WithField("sellAmount", sellAmount.String()).Info("执行策略卖出")
+		err := t.executeTokenSellInternal(track, soldAfter, track.Mint, sellAmount, sellPercent, false, t.cfg.Sell.Slippage, t.cfg.Sell.PriorityFee, t.SellPool(track.Mint), intent.Urgency)
+		if err == nil {
+			track.pendingExit, track.exitRetries = nil, 0
+			return
+		}
+		// 卖出失败时持仓保持跟踪，意图留到下次 tick 重试；已触发过的档位不会再由策略给出
+		if track.exitRetries >= maxExitRetries {
+			logger.WithError(err).Error("卖出重试次数已用完，放弃该意图，交由策略后续判断")
+			track.pendingExit, track.exitRetries = nil, 0
+			return
+		}
+		retry := intent
+		track.pendingExit = &retry
+		track.exitRetries++
+		logger.WithError(err).WithField("retries", track.exitRetries).Warn("卖出失败，下次 tick 时重试")
+	case common.BUY:
+		t.executeTokenBuyInternal(track, intent.Sol, intent.Urgency)
+	default:
+		logger.Warn("未知的策略意图，忽略")
+	}
+}
 
+// executeTokenBuyInternal 加仓并按总花费更新买入均价，调用时 track 应已被锁定
+// 加仓不经过 bot 的预算检查，策略需要自行控制金额
+func (t *TradeExecutor) executeTokenBuyInternal(track *PriceTrackInfo, sol model.Lamports, urgency fee.Urgency) {
+	if sol == 0 {
+		common.Log.Warn("加仓金额为0，取消买入")
 		return
 	}
-
-	// 2. 检查止盈条件
-	common.Log.Debug("开始检查止盈条件")
-	if t.checkTakeProfit(track, tokenAddress, track.CurrentPrice) {
-		common.Log.Info("止盈条件已触发")
+	fill, err := t.executor.Buy(trader.Order{
+		Mint:        track.Mint,
+		Sol:         sol,
+		Slippage:    t.cfg.Buy.Slippage,
+		PriorityFee: t.cfg.Buy.PriorityFee,
+		Pool:        pump.Route(track.Mint, t.cfg.Buy.Pool, t.cfg.Sell.MigratedPool),
+		Urgency:     urgency,
+	})
+	if err != nil {
+		common.Log.WithError(err).WithField("token", track.Mint).Error("加仓失败")
 		return
 	}
-	common.Log.Debug("止盈条件未触发")
+	spent := fill.Sol
+	if spent == 0 {
+		spent = sol
+	}
+	track.BuyAmount.Raw += fill.Tokens.Raw
+	track.RemainingCoin.Raw += fill.Tokens.Raw
+	track.CostSol += spent
+	if !track.BuyAmount.IsZero() {
+		track.EntryPrice = track.CostSol.SOL() / track.BuyAmount.UI()
+		track.SoldPercent = float64(track.BuyAmount.Sub(track.RemainingCoin).Raw) / float64(track.BuyAmount.Raw)
+	}
+	common.Log.WithFields(logrus.Fields{
+		"token":      track.Mint,
+		"tokens":     fill.Tokens.String(),
+		"sol":        spent.String(),
+		"entryPrice": track.EntryPrice,
+	}).Info("加仓成交")
 }
 
-// 检查并执行止盈策略 - 使用switch-case提高效率
-// track 应已被外部锁定
-func (t *TradeExecutor) checkTakeProfit(track *PriceTrackInfo, tokenAddress string, priceForStrategy float64) bool {
-
-	// 计算价格涨幅，使用16位精度
-	currentPriceIncreasePct := (priceForStrategy - track.EntryPrice) / track.EntryPrice
-	currentPriceIncreasePct = math.Round(currentPriceIncreasePct*math.Pow10(PRECISION)) / math.Pow10(PRECISION)
-
-	common.Log.WithFields(logrus.Fields{
-		"token":            track.Mint,
-		"currentPrice":     priceForStrategy,
-		"entryPrice":       track.EntryPrice,
-		"priceIncrease":    currentPriceIncreasePct * 100,
-		"soldedPercentage": track.SoldPercent * 100,
-	}).Info("价格检查详情")
-
-	levelKey := ""
-	targetOverallSellPct := 0.0 // 目标总共卖出的原始购买量的百分比
-	const epsilon = 1e-8        // 定义一个小的容差值
-
-	switch {
-	case currentPriceIncreasePct >= (1.00 - epsilon):
-		levelKey = fmt.Sprintf("%s_100", tokenAddress)
-		targetOverallSellPct = 1.00
-		common.Log.Info("触发 100%% 止盈点")
-	case currentPriceIncreasePct >= (0.90 - epsilon):
-		levelKey = fmt.Sprintf("%s_90", tokenAddress)
-		targetOverallSellPct = 0.90
-		common.Log.Info("触发 90%% 止盈点")
-	case currentPriceIncreasePct >= (0.80 - epsilon):
-		levelKey = fmt.Sprintf("%s_80", tokenAddress)
-		targetOverallSellPct = 0.80
-		common.Log.Info("触发 80%% 止盈点")
-	case currentPriceIncreasePct >= (0.70 - epsilon):
-		levelKey = fmt.Sprintf("%s_70", tokenAddress)
-		targetOverallSellPct = 0.70
-		common.Log.Info("触发 70%% 止盈点")
-	case currentPriceIncreasePct >= (0.60 - epsilon):
-		levelKey = fmt.Sprintf("%s_60", tokenAddress)
-		targetOverallSellPct = 0.60
-		common.Log.Info("触发 60%% 止盈点")
-	case currentPriceIncreasePct >= (0.50 - epsilon):
-		levelKey = fmt.Sprintf("%s_50", tokenAddress)
-		targetOverallSellPct = 0.50
-		common.Log.Info("触发 50%% 止盈点")
-	case currentPriceIncreasePct >= (0.40 - epsilon):
-		levelKey = fmt.Sprintf("%s_40", tokenAddress)
-		targetOverallSellPct = 0.40
-		common.Log.Info("触发 40%% 止盈点")
-	case currentPriceIncreasePct >= (0.30 - epsilon):
-		levelKey = fmt.Sprintf("%s_30", tokenAddress)
-		targetOverallSellPct = 0.30
-		common.Log.Info("触发 30%% 止盈点")
-	case currentPriceIncreasePct >= (0.20 - epsilon):
-		levelKey = fmt.Sprintf("%s_20", tokenAddress)
-		targetOverallSellPct = 0.20
-		common.Log.Info("触发 20%% 止盈点")
-	case currentPriceIncreasePct >= (0.10 - epsilon):
-		levelKey = fmt.Sprintf("%s_10", tokenAddress)
-		targetOverallSellPct = 0.10
-		common.Log.Info("触发 10%% 止盈点")
-	default:
-		common.Log.Info("未达到任何止盈点")
-		return false
+// tickLoop 定时调用各持仓策略的 OnTick，执行器停止时退出
+func (t *TradeExecutor) tickLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.tick(now)
+		}
 	}
+}
 
-	if targetOverallSellPct <= track.SoldPercent {
-		common.Log.WithFields(logrus.Fields{
-			"currentLevel":     levelKey,
-			"soldPercentage":   track.SoldPercent * 100,
-			"targetPercentage": targetOverallSellPct * 100,
-		}).Warn("当前level 但已卖出比例 大于目标比例")
-		return true
+// tick 对每个持仓先重试上次失败的卖出意图，没有时调用策略的 OnTick
+func (t *TradeExecutor) tick(now time.Time) {
+	t.mutex.RLock()
+	tracks := make([]*PriceTrackInfo, 0, len(t.priceTracks))
+	for _, track := range t.priceTracks {
+		tracks = append(tracks, track)
+	}
+	t.mutex.RUnlock()
+	for _, track := range tracks {
+		t.runStrategy(track, func(s Strategy) []Intent {
+			if retry := track.pendingExit; retry != nil {
+				track.pendingExit = nil
+				return []Intent{*retry}
+			}
+			return s.OnTick(track, now)
+		})
 	}
-
-	sellAmount := track.BuyAmount * (targetOverallSellPct - track.SoldPercent)
-	common.Log.WithFields(logrus.Fields{
-		"token":                tokenAddress,
-		"targetPercentage":     targetOverallSellPct * 100,
-		"currentPercentage":    track.SoldPercent * 100,
-		"sellAmount":           sellAmount,
-		"buyAmount":            track.BuyAmount,
-		"targetOverallSellPct": targetOverallSellPct,
-		"SoldPercent":          track.SoldPercent,
-	}).Info("准备执行卖出")
-
-	t.executeTokenSellInternal(track, targetOverallSellPct, tokenAddress, sellAmount,
-		fmt.Sprintf("%.0f%%", targetOverallSellPct*100), false, 20, 0.0005, common.PUMP)
-	return true
 }
 
+// executeTokenSellInternal 卖出并在成交后扣减持仓，全部卖出成功时结束持仓；卖出失败时持仓不变并返回错误
 // 此函数在调用时，track 应已被锁定
-func (t *TradeExecutor) executeTokenSellInternal(track *PriceTrackInfo, SoldPercent float64, tokenAddress string, sellAmount float64, sellPercent string, denominatedInSol bool, slippage int, priorityFee float64, poolType common.PoolType) {
+func (t *TradeExecutor) executeTokenSellInternal(track *PriceTrackInfo, SoldPercent float64, tokenAddress string, sellAmount model.TokenAmount, sellPercent string, denominatedInSol bool, slippage int, priorityFee float64, poolType common.PoolType, urgency fee.Urgency) error {
 
-	if sellAmount <= 0 { // 避免卖出0或负数数量
+	if sellAmount.IsZero() { // 避免卖出0数量
 		common.Log.Warn("尝试卖出的数量过小或为0，取消卖出")
-		return
+		return nil
+	}
+	// 不超过剩余持仓
+	sellAmount = sellAmount.Min(track.RemainingCoin)
+
+	order := trader.Order{
+		Mint:        tokenAddress,
+		Tokens:      sellAmount,
+		SellPercent: sellPercent,
+		Slippage:    slippage,
+		PriorityFee: priorityFee,
+		Pool:        poolType,
+		Urgency:     urgency,
+	}
+	_, err := t.executor.Sell(order)
+	if errors.Is(err, chainTx.ErrTokenMigrated) && poolType == common.PUMP {
+		// 联合曲线已完成但尚未收到迁移事件，标记迁移后在新池子重试一次
+		pump.MarkMigrated(tokenAddress, "", pump.MigrationFromCurve)
+		order.Pool = t.SellPool(tokenAddress)
+		common.Log.WithFields(logrus.Fields{
+			"token": tokenAddress,
+			"pool":  order.Pool,
+		}).Warn("联合曲线已完成，改用迁移后的池子卖出")
+		_, err = t.executor.Sell(order)
 	}
-
-	_, err := chainTx.SellToken(tokenAddress, sellAmount, sellPercent, denominatedInSol, slippage, priorityFee, poolType)
 	if err != nil {
-		common.Log.WithError(err).Error("卖出代币失败")
+		common.Log.WithError(err).WithField("token", tokenAddress).Error("卖出代币失败")
+		return err
 	}
-
+	track.RemainingCoin = track.RemainingCoin.Sub(sellAmount)
+	track.SoldPercent = SoldPercent
 	if sellPercent == "100%" {
-		t.onTokenSold(tokenAddress)
-		return
+		t.finishTrack(track)
+		return nil
 	}
 
 	common.Log.Info("执行卖出")
+	return nil
+}
 
-	track.SoldPercent = SoldPercent
+// finishTrack 全部卖出成功后结束持仓: 标记为已卖出、移出跟踪列表并清理K线，再通知 bot
+// 此函数在调用时，track 应已被锁定
+func (t *TradeExecutor) finishTrack(track *PriceTrackInfo) {
+	track.Status = StatusSold
+	track.pendingExit = nil
+	t.mutex.Lock()
+	if t.priceTracks[track.Mint] == track {
+		delete(t.priceTracks, track.Mint)
+	}
+	t.mutex.Unlock()
+	t.candles.Forget(track.Mint)
+	t.onTokenSold(track.Mint)
+}
+
+// SellPool 返回代币卖出应使用的池子，已迁移出联合曲线的代币改走 sell.migratedPool
+func (t *TradeExecutor) SellPool(tokenAddress string) common.PoolType {
+	return pump.Route(tokenAddress, t.cfg.Sell.Pool, t.cfg.Sell.MigratedPool)
+}
+
+// OnMigration 处理持仓代币迁移出联合曲线，之后的卖出改走迁移后的池子，再交由持仓的策略处理
+func (t *TradeExecutor) OnMigration(tokenAddress string, pool common.PoolType, source string) {
+	t.mutex.RLock()
+	track, exists := t.priceTracks[tokenAddress]
+	t.mutex.RUnlock()
+
+	if !exists || !pump.MarkMigrated(tokenAddress, pool, source) {
+		return
+	}
+	common.Log.WithFields(logrus.Fields{
+		"token":   tokenAddress,
+		"pool":    pool,
+		"source":  source,
+		"profile": track.Profile,
+	}).Info("持仓代币已迁移出联合曲线")
+
+	t.runStrategy(track, func(s Strategy) []Intent { return s.OnMigration(track, pool) })
+}
+
+// Candles 按时间顺序返回持仓代币在 interval 周期最近 n 根K线(n <= 0 时返回全部)，最后一根可能尚未收盘
+func (t *TradeExecutor) Candles(tokenAddress string, interval time.Duration, n int) []candle.Candle {
+	return t.candles.Candles(tokenAddress, interval, n)
 }
 
 // 获取交易信息
@@ -333,6 +476,31 @@ func (t *TradeExecutor) Stop() {
 	common.Log.Info("交易执行器已停止")
 }
 
+// addCandle 只为持仓中的代币聚合K线，在持仓锁内检查状态，全部卖出后清理的缓冲区不会被重建
+func (t *TradeExecutor) addCandle(track *PriceTrackInfo, trade candle.Trade) bool {
+	track.mutex.Lock()
+	defer track.mutex.Unlock()
+	if track.Status == StatusSold {
+		return false
+	}
+	t.candles.Add(track.Mint, trade)
+	return true
+}
+
+// tradePrice 联合曲线交易使用成交后虚拟储备的边际价格，
+// 其余情况(如迁移后的池子)退回到本笔成交的 SOL/代币 比值
+func tradePrice(record *TradeRecord) float64 {
+	if record.VSolInBondingCurve > 0 && record.VTokensInBondingCurve > 0 {
+		c := curve.FromVirtualReserves(
+			uint64(model.SOLToLamports(record.VSolInBondingCurve)),
+			model.UIToTokenAmount(record.VTokensInBondingCurve, pump.TokenDecimals).Raw,
+			solana.PublicKey{},
+		)
+		return c.Price()
+	}
+	return record.SolAmount / record.TokenAmount
+}
+
 // ProcessTradeMessage 处理从WebSocket收到的交易消息
 func (t *TradeExecutor) ProcessTradeMessage(message []byte) {
 	var tradeRecord TradeRecord
@@ -344,7 +512,7 @@ func (t *TradeExecutor) ProcessTradeMessage(message []byte) {
 		return
 	}
 	logger := common.Log.WithFields(logrus.Fields{})
-	logger.Debug(fmt.Sprintf("接收到交易消息,detail,%s", tradeRecord))
+	logger.Debug(fmt.Sprintf("接收到交易消息,detail,%+v", tradeRecord))
 
 	t.mutex.RLock()
 	track, exists := t.priceTracks[tradeRecord.Mint]
@@ -354,17 +522,32 @@ func (t *TradeExecutor) ProcessTradeMessage(message []byte) {
 		return // Token not expected at all
 	}
 
-	// 使用16位精度处理价格计算
-	price := tradeRecord.SolAmount / tradeRecord.TokenAmount
-	price = math.Round(price*math.Pow10(PRECISION)) / math.Pow10(PRECISION)
+	// 交易已不在联合曲线上成交，说明代币已迁移
+	if tradeRecord.Pool != "" && common.PoolType(tradeRecord.Pool) != common.PUMP {
+		t.OnMigration(tradeRecord.Mint, common.PoolType(tradeRecord.Pool), pump.MigrationFromTrade)
+	}
+
+	price := tradePrice(&tradeRecord)
 
 	// 检查价格是否有效
 	if math.IsInf(price, 0) || math.IsNaN(price) || price <= 0 {
-		logger.Warn(fmt.Sprintf("价格--%s，处理无效，跳过处理", price))
+		logger.Warn(fmt.Sprintf("价格--%v，处理无效，跳过处理", price))
 		return
 	}
 
-	logger.Debug(fmt.Sprintf("计算得到新价格--%s", price))
+	logger.Debug(fmt.Sprintf("计算得到新价格--%v", price))
+
+	// 先计入K线，策略处理本笔成交时可以看到；已卖出的持仓不再处理
+	if !t.addCandle(track, candle.Trade{
+		Time:   time.Now(),
+		Price:  price,
+		Sol:    tradeRecord.SolAmount,
+		Tokens: tradeRecord.TokenAmount,
+		IsBuy:  tradeRecord.TxType == "buy",
+		Trader: tradeRecord.TraderPublicKey,
+	}) {
+		return
+	}
 
 	// 只要代币在我们关注列表（不论状态是None, Bought, Selling），都更新其当前价格信息
 	t.UpdatePrice(tradeRecord.Mint, price)
@@ -375,7 +558,7 @@ func (t *TradeExecutor) ProcessTradeMessage(message []byte) {
 	if exists && track.Status != StatusSold {
 		t.mutex.RUnlock()
 		logger.Debug("开始检查策略")
-		t.checkAndExecuteStrategies(track, tradeRecord.Mint)
+		t.checkAndExecuteStrategies(track, &tradeRecord)
 		logger.Debug("策略检查完毕 ---- ", tradeRecord.Mint)
 	} else {
 		t.mutex.RUnlock()
@@ -1,15 +1,35 @@
 package execctor
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"pump_auto/internal/chainTx"
 	"pump_auto/internal/common"
+	"pump_auto/internal/config"
+	"pump_auto/internal/fee"
+	"pump_auto/internal/model"
+	"pump_auto/internal/pump"
+	"pump_auto/internal/trader"
 	"sync"
 	"testing"
 	"time"
 )
 
+// tokens 以整数个 pump 代币创建数量
+func tokens(n uint64) model.TokenAmount {
+	return model.NewTokenAmount(n*1_000_000, pump.TokenDecimals)
+}
+
+// feedPrice 模拟一笔成交推送: 更新价格后交给持仓的策略处理
+func feedPrice(te *TradeExecutor, track *PriceTrackInfo, price float64) {
+	te.UpdatePrice(track.Mint, price)
+	te.checkAndExecuteStrategies(track, &TradeRecord{Mint: track.Mint})
+}
+
 func TestExecuteTokenSellInternal(t *testing.T) {
 	// 创建一个测试用的TradeExecutor
-	executor := NewTradeExecutor(func(tokenAddress string) {
+	executor := NewTradeExecutor(config.Default(), &recordingExecutor{}, func(tokenAddress string) {
 		t.Logf("代币售出回调被触发: %s", tokenAddress)
 	})
 
@@ -20,8 +40,8 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			EntryPrice:     1.0,
 			HighestPrice:   1.0,
 			CurrentPrice:   1.0,
-			BuyAmount:      1000,
-			RemainingCoin:  1000,
+			BuyAmount:      tokens(1000),
+			RemainingCoin:  tokens(1000),
 			SoldPercent:    0,
 			Status:         StatusBought,
 			BuyTime:        time.Now(),
@@ -29,7 +49,7 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			mutex:          sync.Mutex{},
 		}
 
-		executor.executeTokenSellInternal(track, 0.1, "test_token_1", 100, "10%", false, 20, 0.0005, common.PUMP)
+		executor.executeTokenSellInternal(track, 0.1, "test_token_1", tokens(100), "10%", false, 20, 0.0005, common.PUMP, fee.UrgencyTakeProfit)
 
 		if track.SoldPercent != 0.1 {
 			t.Errorf("期望SoldPercent为0.1，实际为%f", track.SoldPercent)
@@ -43,8 +63,8 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			EntryPrice:     1.0,
 			HighestPrice:   1.0,
 			CurrentPrice:   1.0,
-			BuyAmount:      1000,
-			RemainingCoin:  1000,
+			BuyAmount:      tokens(1000),
+			RemainingCoin:  tokens(1000),
 			SoldPercent:    0,
 			Status:         StatusBought,
 			BuyTime:        time.Now(),
@@ -52,7 +72,7 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			mutex:          sync.Mutex{},
 		}
 
-		executor.executeTokenSellInternal(track, 0.1, "test_token_2", 0, "10%", false, 20, 0.0005, common.PUMP)
+		executor.executeTokenSellInternal(track, 0.1, "test_token_2", tokens(0), "10%", false, 20, 0.0005, common.PUMP, fee.UrgencyTakeProfit)
 
 		if track.SoldPercent != 0 {
 			t.Errorf("期望SoldPercent保持为0，实际为%f", track.SoldPercent)
@@ -66,8 +86,8 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			EntryPrice:     1.0,
 			HighestPrice:   1.0,
 			CurrentPrice:   1.0,
-			BuyAmount:      1000,
-			RemainingCoin:  1000,
+			BuyAmount:      tokens(1000),
+			RemainingCoin:  tokens(1000),
 			SoldPercent:    0,
 			Status:         StatusBought,
 			BuyTime:        time.Now(),
@@ -75,7 +95,7 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			mutex:          sync.Mutex{},
 		}
 
-		executor.executeTokenSellInternal(track, 1.0, "test_token_3", 1000, "100%", false, 20, 0.0005, common.PUMP)
+		executor.executeTokenSellInternal(track, 1.0, "test_token_3", tokens(1000), "100%", false, 20, 0.0005, common.PUMP, fee.UrgencyTakeProfit)
 
 		if track.SoldPercent != 1.0 {
 			t.Errorf("期望SoldPercent为1.0，实际为%f", track.SoldPercent)
@@ -89,8 +109,8 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			EntryPrice:     1.0,
 			HighestPrice:   1.0,
 			CurrentPrice:   1.0,
-			BuyAmount:      1000,
-			RemainingCoin:  500,
+			BuyAmount:      tokens(1000),
+			RemainingCoin:  tokens(500),
 			SoldPercent:    0.5,
 			Status:         StatusBought,
 			BuyTime:        time.Now(),
@@ -98,10 +118,618 @@ func TestExecuteTokenSellInternal(t *testing.T) {
 			mutex:          sync.Mutex{},
 		}
 
-		executor.executeTokenSellInternal(track, 0.8, "test_token_4", 800, "80%", false, 20, 0.0005, common.PUMP)
+		executor.executeTokenSellInternal(track, 0.8, "test_token_4", tokens(800), "80%", false, 20, 0.0005, common.PUMP, fee.UrgencyTakeProfit)
 
 		if track.SoldPercent != 0.8 {
 			t.Errorf("期望SoldPercent为0.8，实际为%f", track.SoldPercent)
 		}
 	})
+
+	// 测试用例5: 卖出失败时持仓不变
+	t.Run("卖出失败", func(t *testing.T) {
+		sold := false
+		failing := NewTradeExecutor(config.Default(), &recordingExecutor{sellErr: errors.New("网络错误")}, func(string) { sold = true })
+		track := &PriceTrackInfo{
+			Mint:          "test_token_5",
+			EntryPrice:    1.0,
+			BuyAmount:     tokens(1000),
+			RemainingCoin: tokens(1000),
+			Status:        StatusBought,
+		}
+
+		if err := failing.executeTokenSellInternal(track, 1.0, "test_token_5", tokens(1000), "100%", false, 20, 0.0005, common.PUMP, fee.UrgencyStopLoss); err == nil {
+			t.Fatal("卖出失败时应返回错误")
+		}
+		if sold || track.Status == StatusSold || track.SoldPercent != 0 || track.RemainingCoin != tokens(1000) {
+			t.Errorf("卖出失败后 售出回调 = %v, 状态 = %v, SoldPercent = %f, 剩余 = %s", sold, track.Status, track.SoldPercent, track.RemainingCoin)
+		}
+	})
+}
+
+func TestExitRetry(t *testing.T) {
+	const mint = "exit_retry_token"
+	rec := &recordingExecutor{sellErr: errors.New("网络错误")}
+	sold := 0
+	te := NewTradeExecutor(config.Default(), rec, func(string) { sold++ })
+	te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), tokens(1000))
+	track := te.GetTradeInfo(mint)
+
+	// 兜底止损卖出失败: 持仓继续跟踪，意图等待重试
+	feedPrice(te, track, track.EntryPrice*0.5)
+	if sold != 0 || te.GetTradeInfo(mint) == nil || track.Status == StatusSold {
+		t.Fatalf("卖出失败后 售出回调 = %d 次, 跟踪 = %v, 状态 = %v", sold, te.GetTradeInfo(mint) != nil, track.Status)
+	}
+
+	// 下次 tick 重试成功后结束持仓并移出跟踪列表
+	rec.mu.Lock()
+	rec.sellErr = nil
+	rec.mu.Unlock()
+	te.tick(time.Now())
+	if sold != 1 || track.Status != StatusSold {
+		t.Fatalf("重试后 售出回调 = %d 次, 状态 = %v", sold, track.Status)
+	}
+	if te.GetTradeInfo(mint) != nil {
+		t.Error("全部卖出后应移出跟踪列表")
+	}
+
+	// 再次买入同一代币可以重新跟踪
+	te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), tokens(1000))
+	if again := te.GetTradeInfo(mint); again == nil || again == track {
+		t.Error("全部卖出后再次买入应创建新的跟踪")
+	}
+}
+
+func TestTradePrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		record TradeRecord
+		want   float64
+	}{
+		{
+			name: "联合曲线使用虚拟储备边际价格",
+			record: TradeRecord{
+				SolAmount:             1,
+				TokenAmount:           1,
+				VSolInBondingCurve:    30,
+				VTokensInBondingCurve: 1_073_000_000,
+			},
+			want: 30.0 / 1_073_000_000,
+		},
+		{
+			name:   "缺少储备时使用成交比值",
+			record: TradeRecord{SolAmount: 2, TokenAmount: 1000},
+			want:   0.002,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tradePrice(&tt.record)
+			if diff := got - tt.want; diff > 1e-18 || diff < -1e-18 {
+				t.Errorf("tradePrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingExecutor 记录卖单，curveComplete 中的代币在联合曲线上卖出时返回 ErrTokenMigrated，sellErr 不为空时卖出均失败
+type recordingExecutor struct {
+	mu            sync.Mutex
+	buys          []trader.Order
+	sells         []trader.Order
+	curveComplete map[string]bool
+	sellErr       error
+}
+
+// Buy 记录买单，按每 0.001 SOL 100 个代币成交
+func (r *recordingExecutor) Buy(order trader.Order) (*trader.Fill, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buys = append(r.buys, order)
+	return &trader.Fill{Sol: order.Sol, Tokens: tokens(uint64(order.Sol) / 10_000)}, nil
+}
+
+func (r *recordingExecutor) orders() (buys, sells int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.buys), len(r.sells)
+}
+
+func (r *recordingExecutor) Sell(order trader.Order) (*trader.Fill, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sells = append(r.sells, order)
+	if order.Pool == common.PUMP && r.curveComplete[order.Mint] {
+		return nil, chainTx.ErrTokenMigrated
+	}
+	if r.sellErr != nil {
+		return nil, r.sellErr
+	}
+	return &trader.Fill{Tokens: order.Tokens}, nil
+}
+
+func (r *recordingExecutor) SolBalance() (model.Lamports, error) {
+	return 0, nil
+}
+
+func (r *recordingExecutor) TokenBalance(mint string) (model.TokenAmount, error) {
+	return model.TokenAmount{}, nil
+}
+
+func TestMigrationRouting(t *testing.T) {
+	tests := []struct {
+		name        string
+		onMigration string
+		migrate     func(te *TradeExecutor, mint string)
+		curveDone   bool
+		wantPools   []common.PoolType
+		wantSold    bool
+	}{
+		{
+			name:        "迁移事件后继续持有",
+			onMigration: config.MigrationHold,
+			migrate: func(te *TradeExecutor, mint string) {
+				te.OnMigration(mint, common.PUMP_AMM, pump.MigrationFromEvent)
+			},
+		},
+		{
+			name:        "迁移事件触发全部卖出",
+			onMigration: config.MigrationSell,
+			migrate: func(te *TradeExecutor, mint string) {
+				te.OnMigration(mint, common.PUMP_AMM, pump.MigrationFromEvent)
+			},
+			wantPools: []common.PoolType{common.PUMP_AMM},
+			wantSold:  true,
+		},
+		{
+			name:        "AMM池成交推送视为迁移",
+			onMigration: config.MigrationSell,
+			migrate: func(te *TradeExecutor, mint string) {
+				te.ProcessTradeMessage([]byte(`{"mint":"` + mint + `","txType":"buy","solAmount":1,"tokenAmount":1000,"pool":"pump-amm"}`))
+			},
+			wantPools: []common.PoolType{common.PUMP_AMM},
+			wantSold:  true,
+		},
+		{
+			name:        "联合曲线已完成时改用AMM池重试",
+			onMigration: config.MigrationHold,
+			curveDone:   true,
+			migrate: func(te *TradeExecutor, mint string) {
+				track := te.GetTradeInfo(mint)
+				track.mutex.Lock()
+				defer track.mutex.Unlock()
+				te.executeTokenSellInternal(track, 1, mint, track.RemainingCoin, "100%", false, 20, 0.0005, te.SellPool(mint), fee.UrgencyStopLoss)
+			},
+			wantPools: []common.PoolType{common.PUMP, common.PUMP_AMM},
+			wantSold:  true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mint := fmt.Sprintf("migrated_token_%d", i)
+			defer pump.ForgetMigration(mint)
+
+			cfg := config.Default()
+			cfg.Sell.OnMigration = tt.onMigration
+			rec := &recordingExecutor{curveComplete: map[string]bool{mint: tt.curveDone}}
+			sold := false
+			te := NewTradeExecutor(cfg, rec, func(string) { sold = true })
+			te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), tokens(1000))
+
+			tt.migrate(te, mint)
+
+			if len(rec.sells) != len(tt.wantPools) {
+				t.Fatalf("卖出次数 = %d, want %d", len(rec.sells), len(tt.wantPools))
+			}
+			for j, order := range rec.sells {
+				if order.Pool != tt.wantPools[j] {
+					t.Errorf("第%d笔卖单池子 = %s, want %s", j+1, order.Pool, tt.wantPools[j])
+				}
+			}
+			if sold != tt.wantSold {
+				t.Errorf("售出回调 = %v, want %v", sold, tt.wantSold)
+			}
+			if got := te.SellPool(mint); got != common.PUMP_AMM {
+				t.Errorf("迁移后 SellPool() = %s, want %s", got, common.PUMP_AMM)
+			}
+		})
+	}
+}
+
+func TestTakeProfitExactAmounts(t *testing.T) {
+	const mint = "exact_amount_token"
+	rec := &recordingExecutor{}
+	te := NewTradeExecutor(config.Default(), rec, func(string) {})
+	buy := model.NewTokenAmount(1_000_003, pump.TokenDecimals)
+	te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), buy)
+	track := te.GetTradeInfo(mint)
+
+	// 涨幅 10% 卖出 10%，涨幅 30% 累计卖出 30%
+	for _, rise := range []float64{1.1, 1.3, 1.3} {
+		feedPrice(te, track, track.EntryPrice*rise)
+	}
+
+	want := []uint64{100_000, 200_000}
+	if len(rec.sells) != len(want) {
+		t.Fatalf("卖出次数 = %d, want %d", len(rec.sells), len(want))
+	}
+	for i, order := range rec.sells {
+		if order.Tokens.Raw != want[i] {
+			t.Errorf("第%d笔卖出 = %d, want %d", i+1, order.Tokens.Raw, want[i])
+		}
+	}
+	if got := track.RemainingCoin.Raw; got != 700_003 {
+		t.Errorf("剩余持仓 = %d, want 700003", got)
+	}
+}
+
+func TestTakeProfitLadder(t *testing.T) {
+	cfg := config.Default()
+	cfg.Strategy.Profiles["moonbag"] = config.StrategyProfile{TakeProfit: []config.TakeProfitSetting{
+		{PriceIncreasePct: 100, SellPct: 50},
+		{PriceIncreasePct: 400, SellPct: 80},
+		{PriceIncreasePct: 900, SellPct: 100},
+	}}
+	cfg.Strategy.Profile = "moonbag"
+
+	const mint = "moonbag_token"
+	rec := &recordingExecutor{}
+	sold := false
+	te := NewTradeExecutor(cfg, rec, func(string) { sold = true })
+	te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), tokens(1000))
+	track := te.GetTradeInfo(mint)
+
+	// 1.5x 未达到第一级，2x 卖出一半，5x 累计卖出 80%，10x 卖出剩余全部
+	for _, rise := range []float64{1.5, 2, 3, 5, 10} {
+		feedPrice(te, track, track.EntryPrice*rise)
+	}
+
+	want := []struct {
+		tokens  model.TokenAmount
+		percent string
+	}{
+		{tokens(500), "50%"},
+		{tokens(300), "80%"},
+		{tokens(200), "100%"},
+	}
+	if len(rec.sells) != len(want) {
+		t.Fatalf("卖出次数 = %d, want %d", len(rec.sells), len(want))
+	}
+	for i, order := range rec.sells {
+		if order.Tokens != want[i].tokens || order.SellPercent != want[i].percent {
+			t.Errorf("第%d笔卖出 = %s (%s), want %s (%s)", i+1, order.Tokens, order.SellPercent, want[i].tokens, want[i].percent)
+		}
+	}
+	if !sold || !track.RemainingCoin.IsZero() {
+		t.Errorf("最后一级应全部卖出: 售出回调 = %v, 剩余持仓 = %s", sold, track.RemainingCoin)
+	}
+}
+
+func TestTrailingStop(t *testing.T) {
+	cfg := config.Default()
+	cfg.Strategy.Profiles[config.DefaultProfile] = config.StrategyProfile{
+		TakeProfit: []config.TakeProfitSetting{{PriceIncreasePct: 1000, SellPct: 100}},
+		TrailingStop: []config.StopLossSetting{
+			{ActivationPct: 30, TriggerPct: 10, SellPct: 50},
+			{ActivationPct: 100, TriggerPct: 20, SellPct: 100},
+		},
+	}
+
+	type sell struct {
+		tokens  model.TokenAmount
+		percent string
+	}
+	tests := []struct {
+		name     string
+		prices   []float64 // 相对买入价的倍数
+		want     []sell
+		wantSold bool
+	}{
+		{
+			name:   "未激活时回落不卖出",
+			prices: []float64{1.2, 1.05},
+		},
+		{
+			name:   "逐档触发",
+			prices: []float64{1.5, 1.34, 1.2, 2.5, 2.1, 1.9},
+			want: []sell{
+				{tokens(500), "50%"},
+				{tokens(500), "100%"},
+			},
+			wantSold: true,
+		},
+		{
+			name:     "多档同时触发合并卖出",
+			prices:   []float64{2.5, 1.9},
+			want:     []sell{{tokens(1000), "100%"}},
+			wantSold: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mint := fmt.Sprintf("trailing_token_%d", i)
+			rec := &recordingExecutor{}
+			sold := false
+			te := NewTradeExecutor(cfg, rec, func(string) { sold = true })
+			te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), tokens(1000))
+			track := te.GetTradeInfo(mint)
+			entry := track.EntryPrice
+
+			for _, rise := range tt.prices {
+				feedPrice(te, track, entry*rise)
+			}
+
+			if len(rec.sells) != len(tt.want) {
+				t.Fatalf("卖出次数 = %d, want %d", len(rec.sells), len(tt.want))
+			}
+			for j, order := range rec.sells {
+				if order.Tokens != tt.want[j].tokens || order.SellPercent != tt.want[j].percent {
+					t.Errorf("第%d笔卖出 = %s (%s), want %s (%s)", j+1, order.Tokens, order.SellPercent, tt.want[j].tokens, tt.want[j].percent)
+				}
+			}
+			if sold != tt.wantSold || (tt.wantSold && track.Status != StatusSold) {
+				t.Errorf("售出回调 = %v, 状态 = %v, want %v", sold, track.Status, tt.wantSold)
+			}
+		})
+	}
+}
+
+// scriptedStrategy 测试用策略: 建仓后加仓一次，下一次定时检查时全部卖出
+type scriptedStrategy struct {
+	addSol model.Lamports
+}
+
+func (s *scriptedStrategy) OnEntry(pos *PriceTrackInfo) []Intent {
+	return []Intent{{Action: common.BUY, Sol: s.addSol, Urgency: fee.UrgencySnipe, Reason: "加仓"}}
+}
+
+func (s *scriptedStrategy) OnTrade(pos *PriceTrackInfo, record *TradeRecord) []Intent {
+	return nil
+}
+
+func (s *scriptedStrategy) OnTick(pos *PriceTrackInfo, now time.Time) []Intent {
+	return []Intent{{Action: common.SELL, SellAll: true, Urgency: fee.UrgencyEmergency, Reason: "定时卖出"}}
+}
+
+func (s *scriptedStrategy) OnMigration(pos *PriceTrackInfo, pool common.PoolType) []Intent {
+	return nil
+}
+
+func TestStrategyRotation(t *testing.T) {
+	const name = "scripted"
+	if _, ok := lookupStrategy(name); !ok {
+		RegisterStrategy(name, func(cfg *config.Config, profile config.StrategyProfile) Strategy {
+			return &scriptedStrategy{addSol: model.SOLToLamports(0.01)}
+		})
+	}
+	strategyTickInterval = 10 * time.Millisecond
+	defer func() { strategyTickInterval = time.Second }()
+
+	cfg := config.Default()
+	cfg.Strategy.Profiles[name] = config.StrategyProfile{Strategy: name}
+	cfg.Strategy.Rotate = []string{config.DefaultProfile, name}
+	if err := CheckStrategies(cfg); err != nil {
+		t.Fatalf("CheckStrategies() error = %v", err)
+	}
+
+	rec := &recordingExecutor{}
+	var mu sync.Mutex
+	var sold []string
+	te := NewTradeExecutor(cfg, rec, func(mint string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sold = append(sold, mint)
+	})
+	defer te.Stop()
+	te.ExpectBuyForToken("ladder_position", model.SOLToLamports(0.01), tokens(1000))
+	te.ExpectBuyForToken("scripted_position", model.SOLToLamports(0.01), tokens(1000))
+
+	if got := te.GetTradeInfo("ladder_position").Profile; got != config.DefaultProfile {
+		t.Errorf("第一个持仓策略 = %s, want %s", got, config.DefaultProfile)
+	}
+	scripted := te.GetTradeInfo("scripted_position")
+	if scripted.Profile != name {
+		t.Errorf("第二个持仓策略 = %s, want %s", scripted.Profile, name)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		done := len(sold) > 0
+		mu.Unlock()
+		if done || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	buys, sells := rec.orders()
+	if buys != 1 || sells != 1 {
+		t.Fatalf("买单 = %d, 卖单 = %d, want 1, 1", buys, sells)
+	}
+	scripted.mutex.Lock()
+	defer scripted.mutex.Unlock()
+	if rec.sells[0].Mint != "scripted_position" || rec.sells[0].Tokens != tokens(2000) || rec.sells[0].SellPercent != "100%" {
+		t.Errorf("卖单 = %+v, 应卖出加仓后的全部 2000 个代币", rec.sells[0])
+	}
+	if scripted.Status != StatusSold || scripted.CostSol != model.SOLToLamports(0.02) {
+		t.Errorf("状态 = %v, 花费 = %s", scripted.Status, scripted.CostSol)
+	}
+	if te.GetTradeInfo("ladder_position").Status == StatusSold {
+		t.Errorf("ladder 持仓不应被定时卖出")
+	}
+}
+
+func TestTimeExit(t *testing.T) {
+	// step 持仓后 at 时刻的一次事件，price 大于0时为该价格(相对买入价的倍数)的成交推送，否则为定时检查
+	type step struct {
+		at         time.Duration
+		price      float64
+		wantReason string // 期望的卖出原因，为空表示不卖出
+	}
+	tests := []struct {
+		name     string
+		timeExit config.TimeExitConfig
+		steps    []step
+	}{
+		{
+			name:     "超过最长持仓时间",
+			timeExit: config.TimeExitConfig{MaxHold: config.Duration(10 * time.Minute), Inactivity: config.Duration(time.Hour)},
+			steps: []step{
+				{at: 9 * time.Minute},
+				{at: 10 * time.Minute, wantReason: "超过最长持仓时间"},
+			},
+		},
+		{
+			name: "默认使用 bot.inactivityTimeout",
+			steps: []step{
+				{at: 20 * time.Second, price: 1.01},
+				{at: 49 * time.Second},
+				{at: 50 * time.Second, wantReason: "30s 内没有成交"},
+			},
+		},
+		{
+			name:     "无成交超时按成交频率缩放",
+			timeExit: config.TimeExitConfig{Inactivity: config.Duration(time.Minute), InactivityFactor: 5, InactivityMin: config.Duration(2 * time.Second)},
+			steps: []step{
+				{at: 1 * time.Second, price: 1.01},
+				{at: 2 * time.Second, price: 1.02},
+				{at: 3 * time.Second, price: 1.03},
+				{at: 7 * time.Second},
+				{at: 8 * time.Second, wantReason: "5s 内没有成交"},
+			},
+		},
+		{
+			name:     "缩放后的超时不低于下限",
+			timeExit: config.TimeExitConfig{Inactivity: config.Duration(time.Minute), InactivityFactor: 2, InactivityMin: config.Duration(10 * time.Second)},
+			steps: []step{
+				{at: 100 * time.Millisecond, price: 1.01},
+				{at: 200 * time.Millisecond, price: 1.02},
+				{at: 5 * time.Second},
+				{at: 10200 * time.Millisecond, wantReason: "10s 内没有成交"},
+			},
+		},
+		{
+			name:     "盈利期限到期未达标",
+			timeExit: config.TimeExitConfig{Inactivity: config.Duration(time.Hour), ProfitDeadline: config.Duration(2 * time.Minute), ProfitTargetPct: 20},
+			steps: []step{
+				{at: time.Minute, price: 1.1},
+				{at: 2 * time.Minute, wantReason: "2m0s 内涨幅未达到 20%"},
+			},
+		},
+		{
+			name:     "盈利期限达标后不再检查",
+			timeExit: config.TimeExitConfig{Inactivity: config.Duration(time.Hour), ProfitDeadline: config.Duration(2 * time.Minute), ProfitTargetPct: 20},
+			steps: []step{
+				{at: time.Minute, price: 1.25},
+				{at: 2 * time.Minute},
+				{at: 3 * time.Minute, price: 1.1},
+				{at: 4 * time.Minute},
+			},
+		},
+		{
+			name:     "止盈目标随时间衰减",
+			timeExit: config.TimeExitConfig{Inactivity: config.Duration(time.Hour), TakeProfitHalfLife: config.Duration(time.Minute)},
+			steps: []step{
+				{at: 10 * time.Second, price: 1.2},
+				{at: 30 * time.Second},
+				{at: time.Minute, wantReason: "止盈"},
+			},
+		},
+		{
+			name:     "衰减不低于下限",
+			timeExit: config.TimeExitConfig{Inactivity: config.Duration(time.Hour), TakeProfitHalfLife: config.Duration(time.Minute), TakeProfitFloorPct: 25},
+			steps: []step{
+				{at: 10 * time.Second, price: 1.09},
+				{at: 10 * time.Minute},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			profile := config.StrategyProfile{
+				TakeProfit: []config.TakeProfitSetting{{PriceIncreasePct: 40, SellPct: 100}},
+				TimeExit:   tt.timeExit,
+			}
+			strategy := newLadderStrategy(cfg, profile)
+
+			buyTime := time.Now()
+			pos := &PriceTrackInfo{
+				Mint:           "time_exit_token",
+				BuyTime:        buyTime,
+				LastUpdateTime: buyTime,
+				EntryPrice:     0.0001,
+				CurrentPrice:   0.0001,
+				BuyAmount:      tokens(1000),
+				RemainingCoin:  tokens(1000),
+			}
+			for _, s := range tt.steps {
+				now := buyTime.Add(s.at)
+				var intents []Intent
+				if s.price > 0 {
+					pos.CurrentPrice = pos.EntryPrice * s.price
+					pos.LastUpdateTime = now
+					intents = strategy.OnTrade(pos, &TradeRecord{Mint: pos.Mint})
+				} else {
+					intents = strategy.OnTick(pos, now)
+				}
+
+				if s.wantReason == "" {
+					if len(intents) != 0 {
+						t.Fatalf("%s 时意图 = %+v, want 不卖出", s.at, intents)
+					}
+					continue
+				}
+				if len(intents) != 1 || intents[0].Action != common.SELL || intents[0].Reason != s.wantReason {
+					t.Fatalf("%s 时意图 = %+v, want 卖出(%s)", s.at, intents, s.wantReason)
+				}
+			}
+		})
+	}
+}
+
+func TestTradeCandles(t *testing.T) {
+	cfg := config.Default()
+	cfg.Candle.Intervals = []config.Duration{config.Duration(time.Minute)}
+	te := NewTradeExecutor(cfg, &recordingExecutor{}, func(string) {})
+	defer te.Stop()
+
+	const mint = "candle_token"
+	message := func(txType, trader string, sol, amount float64) []byte {
+		return []byte(fmt.Sprintf(`{"mint": %q, "txType": %q, "traderPublicKey": %q, "solAmount": %v, "tokenAmount": %v}`, mint, txType, trader, sol, amount))
+	}
+	te.ProcessTradeMessage(message("buy", "a", 0.001, 100))
+	if got := te.Candles(mint, time.Minute, 0); got != nil {
+		t.Fatalf("未跟踪的代币 Candles() = %+v, want nil", got)
+	}
+
+	te.ExpectBuyForToken(mint, model.SOLToLamports(0.01), tokens(1000))
+	te.ProcessTradeMessage(message("buy", "a", 0.0011, 100))
+	te.ProcessTradeMessage(message("sell", "b", 0.00098, 100))
+	te.ProcessTradeMessage(message("buy", "a", 0.001, 100))
+
+	candles := te.GetTradeInfo(mint).Candles(time.Minute, 0)
+	if len(candles) == 0 {
+		t.Fatal("Candles() 为空")
+	}
+	var buys, sells int
+	var volume float64
+	for _, c := range candles {
+		buys, sells, volume = buys+c.Buys, sells+c.Sells, volume+c.Volume
+	}
+	last := candles[len(candles)-1]
+	if buys != 2 || sells != 1 || math.Abs(volume-0.00308) > 1e-12 || last.Close != 0.00001 {
+		t.Errorf("Candles() = %+v", candles)
+	}
+
+	// 止损全部卖出后清理K线，之后的成交不再聚合
+	te.ProcessTradeMessage(message("sell", "b", 0.0001, 100))
+	te.ProcessTradeMessage(message("buy", "a", 0.001, 100))
+	if got := te.Candles(mint, time.Minute, 0); got != nil {
+		t.Errorf("全部卖出后 Candles() = %+v, want nil", got)
+	}
 }
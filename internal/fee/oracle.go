@@ -0,0 +1,186 @@
+package fee
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Urgency 交易的紧急程度，决定取哪个分位数的优先费
+type Urgency string
+
+const (
+	UrgencySnipe      Urgency = "snipe"       // 新币狙击，需要抢在前面
+	UrgencyTakeProfit Urgency = "take-profit" // 止盈，可以等
+	UrgencyStopLoss   Urgency = "stop-loss"   // 止损
+	UrgencyEmergency  Urgency = "emergency"   // 超时/异常清仓，必须尽快成交
+)
+
+// percentiles 各紧急程度对应的优先费分位数
+var percentiles = map[Urgency]float64{
+	UrgencySnipe:      90,
+	UrgencyTakeProfit: 60,
+	UrgencyStopLoss:   75,
+	UrgencyEmergency:  99,
+}
+
+// Source 获取近期优先费的RPC方法，*solana.Client 实现了该接口
+type Source interface {
+	GetRecentPrioritizationFees(accounts []solana.PublicKey) ([]rpc.PriorizationFeeResult, error)
+}
+
+// Config 优先费估算参数，金额单位为 SOL
+type Config struct {
+	MinSol           float64 // 估算结果下限
+	MaxSol           float64 // 估算结果上限
+	ComputeUnitLimit uint32  // 按该计算单元上限将单价折算为 SOL
+	WindowSlots      uint64  // 保留最近多少个 slot 的样本
+}
+
+const defaultWindowSlots = 150
+
+// Oracle 按写锁账户采样 getRecentPrioritizationFees，维护滚动窗口并按分位数估算优先费
+type Oracle struct {
+	source Source
+	cfg    Config
+
+	mu      sync.Mutex
+	samples map[uint64]uint64 // slot -> 该 slot 观察到的最高单价(微lamports/CU)
+	watched map[solana.PublicKey]struct{}
+}
+
+// NewOracle 创建优先费估算器，accounts 为始终采样的写锁账户(如 pump 手续费接收账户)
+func NewOracle(source Source, cfg Config, accounts ...solana.PublicKey) *Oracle {
+	if cfg.WindowSlots == 0 {
+		cfg.WindowSlots = defaultWindowSlots
+	}
+	o := &Oracle{
+		source:  source,
+		cfg:     cfg,
+		samples: make(map[uint64]uint64),
+		watched: make(map[solana.PublicKey]struct{}),
+	}
+	for _, account := range accounts {
+		o.watched[account] = struct{}{}
+	}
+	return o
+}
+
+// Watch 将写锁账户(如持仓代币的联合曲线)加入采样
+func (o *Oracle) Watch(account solana.PublicKey) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.watched[account] = struct{}{}
+}
+
+// Unwatch 停止采样账户
+func (o *Oracle) Unwatch(account solana.PublicKey) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.watched, account)
+}
+
+// Run 按间隔采样，直到 ctx 取消
+func (o *Oracle) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := o.Sample(); err != nil {
+			log.Printf("采样优先费失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample 采样一次所有关注账户的近期优先费
+func (o *Oracle) Sample() error {
+	o.mu.Lock()
+	accounts := make([]solana.PublicKey, 0, len(o.watched))
+	for account := range o.watched {
+		accounts = append(accounts, account)
+	}
+	o.mu.Unlock()
+
+	fees, err := o.source.GetRecentPrioritizationFees(accounts)
+	if err != nil {
+		return err
+	}
+	o.add(fees)
+	return nil
+}
+
+func (o *Oracle) add(fees []rpc.PriorizationFeeResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var latest uint64
+	for _, f := range fees {
+		if current, ok := o.samples[f.Slot]; !ok || f.PrioritizationFee > current {
+			o.samples[f.Slot] = f.PrioritizationFee
+		}
+		if f.Slot > latest {
+			latest = f.Slot
+		}
+	}
+	for slot := range o.samples {
+		if slot+o.cfg.WindowSlots < latest {
+			delete(o.samples, slot)
+		}
+	}
+}
+
+// Percentile 返回窗口内优先费单价的分位数(微lamports/CU)，没有样本时返回 false
+func (o *Oracle) Percentile(p float64) (uint64, bool) {
+	o.mu.Lock()
+	values := make([]uint64, 0, len(o.samples))
+	for _, v := range o.samples {
+		values = append(values, v)
+	}
+	o.mu.Unlock()
+	if len(values) == 0 {
+		return 0, false
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	// 最近秩法
+	rank := int(math.Ceil(p/100*float64(len(values)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(values) {
+		rank = len(values) - 1
+	}
+	return values[rank], true
+}
+
+// Fee 按紧急程度估算优先费(SOL)，限制在 [MinSol, MaxSol]；没有样本时返回 fallback 与上限的较小值
+func (o *Oracle) Fee(urgency Urgency, fallback float64) float64 {
+	p, ok := percentiles[urgency]
+	if !ok {
+		p = percentiles[UrgencyTakeProfit]
+	}
+	price, ok := o.Percentile(p)
+	if !ok || o.cfg.ComputeUnitLimit == 0 {
+		return o.clamp(fallback)
+	}
+	lamports := float64(price) * float64(o.cfg.ComputeUnitLimit) / 1_000_000
+	return o.clamp(lamports / float64(solana.LAMPORTS_PER_SOL))
+}
+
+func (o *Oracle) clamp(sol float64) float64 {
+	if sol < o.cfg.MinSol {
+		sol = o.cfg.MinSol
+	}
+	if o.cfg.MaxSol > 0 && sol > o.cfg.MaxSol {
+		sol = o.cfg.MaxSol
+	}
+	return sol
+}
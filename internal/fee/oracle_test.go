@@ -0,0 +1,73 @@
+package fee
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+type fakeSource struct {
+	fees     []rpc.PriorizationFeeResult
+	accounts []solana.PublicKey
+}
+
+func (f *fakeSource) GetRecentPrioritizationFees(accounts []solana.PublicKey) ([]rpc.PriorizationFeeResult, error) {
+	f.accounts = accounts
+	return f.fees, nil
+}
+
+func TestOracleFee(t *testing.T) {
+	// slot 1..100 的单价依次为 100_000..10_000_000 微lamports/CU
+	source := &fakeSource{}
+	for i := uint64(1); i <= 100; i++ {
+		source.fees = append(source.fees, rpc.PriorizationFeeResult{Slot: i, PrioritizationFee: i * 100_000})
+	}
+	cfg := Config{MinSol: 0.00001, MaxSol: 0.0008, ComputeUnitLimit: 100_000}
+
+	tests := []struct {
+		name     string
+		urgency  Urgency
+		fallback float64
+		empty    bool
+		want     float64
+	}{
+		// P90: 100_000 CU * 9_000_000 / 1e6 = 900_000 lamports = 0.0009 SOL，超过上限
+		{name: "狙击取上限", urgency: UrgencySnipe, want: 0.0008},
+		// P60: 100_000 CU * 6_000_000 / 1e6 = 600_000 lamports
+		{name: "止盈取P60", urgency: UrgencyTakeProfit, want: 0.0006},
+		{name: "没有样本时使用固定值", urgency: UrgencySnipe, fallback: 0.0005, empty: true, want: 0.0005},
+		{name: "固定值也受下限约束", urgency: UrgencySnipe, fallback: 0, empty: true, want: 0.00001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOracle(source, cfg, solana.SystemProgramID)
+			if !tt.empty {
+				if err := o.Sample(); err != nil {
+					t.Fatalf("Sample() error = %v", err)
+				}
+			}
+			if got := o.Fee(tt.urgency, tt.fallback); got != tt.want {
+				t.Errorf("Fee(%s) = %v, want %v", tt.urgency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOracleWindow(t *testing.T) {
+	source := &fakeSource{fees: []rpc.PriorizationFeeResult{{Slot: 1, PrioritizationFee: 1_000_000}}}
+	o := NewOracle(source, Config{WindowSlots: 10})
+	_ = o.Sample()
+
+	// 新的样本到达后，超出窗口的 slot 被淘汰
+	source.fees = []rpc.PriorizationFeeResult{{Slot: 50, PrioritizationFee: 10}, {Slot: 50, PrioritizationFee: 20}}
+	account := solana.MustPublicKeyFromBase58("6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P")
+	o.Watch(account)
+	_ = o.Sample()
+	if len(source.accounts) != 1 || !source.accounts[0].Equals(account) {
+		t.Errorf("采样账户 = %v, want [%s]", source.accounts, account)
+	}
+	if got, _ := o.Percentile(100); got != 20 {
+		t.Errorf("Percentile(100) = %d, want 20 (同一slot取最高值，旧slot被淘汰)", got)
+	}
+}
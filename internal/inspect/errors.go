@@ -0,0 +1,37 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 交易检查失败的原因，可通过 errors.Is 判断
+var (
+	ErrProgramNotAllowed     = errors.New("交易包含未允许的程序")
+	ErrInstructionNotAllowed = errors.New("交易包含未允许的指令")
+	ErrMintMismatch          = errors.New("交易代币与请求不一致")
+	ErrActionMismatch        = errors.New("交易动作与请求不一致")
+	ErrUnexpectedSigner      = errors.New("交易签名者或付款人与钱包不一致")
+	ErrSolLimitExceeded      = errors.New("交易花费的SOL超过允许范围")
+	ErrAmountMismatch        = errors.New("交易代币数量与请求不一致")
+	ErrMinSolOutTooLow       = errors.New("交易卖出的最少获得低于报价")
+	ErrAddressLookup         = errors.New("交易使用了无法校验的地址查找表")
+)
+
+// Violation 交易检查失败的详细信息
+type Violation struct {
+	Reason error  // 上面定义的原因之一
+	Detail string // 具体描述
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%v: %s", v.Reason, v.Detail)
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Reason
+}
+
+func violation(reason error, format string, args ...interface{}) *Violation {
+	return &Violation{Reason: reason, Detail: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,217 @@
+package inspect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"pump_auto/internal/common"
+	"pump_auto/internal/pump"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+// AssociatedTokenRent 创建一个代币账户需要的租金 (165字节账户的免租金额)
+const AssociatedTokenRent uint64 = 2_039_280
+
+// 代币程序中允许出现的指令，其余(转账、授权、修改权限等)会转移我们的代币，一律拒绝
+const (
+	tokenInstructionCloseAccount byte = 9
+	tokenInstructionSyncNative   byte = 17
+)
+
+// 计算预算程序的指令编号
+const (
+	computeBudgetSetUnitLimit byte = 2
+	computeBudgetSetUnitPrice byte = 3
+)
+
+// AllowedPrograms 交易中允许出现的程序
+var AllowedPrograms = map[solana.PublicKey]string{
+	solana.SystemProgramID:                    "System",
+	solana.TokenProgramID:                     "Token",
+	solana.SPLAssociatedTokenAccountProgramID: "AssociatedToken",
+	computebudget.ProgramID:                   "ComputeBudget",
+	pump.ProgramID:                            "Pump",
+}
+
+// Expectation 对交易内容的预期，来自发起的交易请求
+type Expectation struct {
+	Action    common.TradeAction
+	Mint      solana.PublicKey
+	User      solana.PublicKey
+	MaxSolOut uint64 // 允许离开钱包的最大 lamports，包括买入花费、转账、租金和优先费
+	// 卖出时 pump 指令的代币数量必须等于该值；买入时为按报价扣除滑点后至少获得的数量
+	Tokens uint64
+	// 卖出时 pump 指令最少获得的 lamports 不能低于该值，即扣除滑点后的报价
+	MinSolOut uint64
+	// 额外允许的服务费，按 pump 指令SOL上限/下限的万分比计算，用于覆盖交易API收取的手续费
+	FeeAllowanceBps uint64
+}
+
+// Report 检查通过后的交易摘要
+type Report struct {
+	PumpAmount   uint64 // pump 指令中的代币数量
+	PumpSolLimit uint64 // 买入的最大花费或卖出的最少获得
+	Transfers    uint64 // 用户发起的 System 转账和建账户的 lamports
+	Rent         uint64 // 创建代币账户的租金
+	PriorityFee  uint64 // 计算单元上限 * 单价 折算的 lamports
+	SolOut       uint64 // 最多离开钱包的 lamports 合计
+}
+
+// Inspect 在签名前解析交易消息，任何与预期不符的内容都返回 *Violation
+func Inspect(tx *solana.Transaction, want Expectation) (*Report, error) {
+	msg := tx.Message
+	if msg.IsVersioned() && len(msg.AddressTableLookups) > 0 {
+		return nil, violation(ErrAddressLookup, "交易引用了 %d 个地址查找表", len(msg.AddressTableLookups))
+	}
+	if len(msg.AccountKeys) == 0 {
+		return nil, violation(ErrUnexpectedSigner, "交易没有任何账户")
+	}
+	if !msg.AccountKeys[0].Equals(want.User) {
+		return nil, violation(ErrUnexpectedSigner, "付款人为 %s，期望 %s", msg.AccountKeys[0], want.User)
+	}
+	if msg.Header.NumRequiredSignatures != 1 {
+		return nil, violation(ErrUnexpectedSigner, "交易需要 %d 个签名，只允许钱包本身签名", msg.Header.NumRequiredSignatures)
+	}
+
+	report := &Report{}
+	var unitLimit, unitPrice uint64
+	pumpInstructions := 0
+
+	for i, inst := range msg.Instructions {
+		programID, err := msg.Program(inst.ProgramIDIndex)
+		if err != nil {
+			return nil, violation(ErrProgramNotAllowed, "第 %d 条指令程序索引无效: %v", i, err)
+		}
+		if _, ok := AllowedPrograms[programID]; !ok {
+			return nil, violation(ErrProgramNotAllowed, "第 %d 条指令调用了未允许的程序 %s", i, programID)
+		}
+		accounts, err := inst.ResolveInstructionAccounts(&msg)
+		if err != nil {
+			return nil, violation(ErrInstructionNotAllowed, "第 %d 条指令账户索引无效: %v", i, err)
+		}
+		data := inst.Data
+
+		switch programID {
+		case computebudget.ProgramID:
+			if len(data) == 0 {
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条计算预算指令为空", i)
+			}
+			switch {
+			case data[0] == computeBudgetSetUnitLimit && len(data) >= 5:
+				unitLimit = uint64(binary.LittleEndian.Uint32(data[1:5]))
+			case data[0] == computeBudgetSetUnitPrice && len(data) >= 9:
+				unitPrice = binary.LittleEndian.Uint64(data[1:9])
+			}
+
+		case solana.SystemProgramID:
+			lamports, err := systemOutflow(data, accounts, want.User)
+			if err != nil {
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条指令: %v", i, err)
+			}
+			report.Transfers += lamports
+
+		case solana.SPLAssociatedTokenAccountProgramID:
+			// Create(空数据或0) / CreateIdempotent(1): payer, ata, owner, mint, ...
+			if len(data) > 1 || (len(data) == 1 && data[0] > 1) {
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条指令不是创建关联代币账户", i)
+			}
+			if len(accounts) < 4 {
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条创建代币账户指令账户不足", i)
+			}
+			if !accounts[3].PublicKey.Equals(want.Mint) {
+				return nil, violation(ErrMintMismatch, "第 %d 条指令为代币 %s 创建账户，期望 %s", i, accounts[3].PublicKey, want.Mint)
+			}
+			if accounts[0].PublicKey.Equals(want.User) {
+				report.Rent += AssociatedTokenRent
+			}
+
+		case solana.TokenProgramID:
+			if len(data) == 0 {
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条代币指令为空", i)
+			}
+			switch data[0] {
+			case tokenInstructionSyncNative:
+			case tokenInstructionCloseAccount:
+				if len(accounts) < 2 || !accounts[1].PublicKey.Equals(want.User) {
+					return nil, violation(ErrInstructionNotAllowed, "第 %d 条关闭账户指令的租金未退回钱包", i)
+				}
+			default:
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条代币指令类型 %d 不允许", i, data[0])
+			}
+
+		case pump.ProgramID:
+			pumpInstructions++
+			action, amount, limit, err := pump.DecodeTradeData(data)
+			if err != nil {
+				return nil, violation(ErrInstructionNotAllowed, "第 %d 条 pump 指令: %v", i, err)
+			}
+			if action != want.Action {
+				return nil, violation(ErrActionMismatch, "pump 指令为 %s，期望 %s", action, want.Action)
+			}
+			if len(accounts) < 7 {
+				return nil, violation(ErrInstructionNotAllowed, "pump 指令账户不足: %d", len(accounts))
+			}
+			if !accounts[2].PublicKey.Equals(want.Mint) {
+				return nil, violation(ErrMintMismatch, "pump 指令代币为 %s，期望 %s", accounts[2].PublicKey, want.Mint)
+			}
+			if !accounts[6].PublicKey.Equals(want.User) {
+				return nil, violation(ErrUnexpectedSigner, "pump 指令用户为 %s，期望 %s", accounts[6].PublicKey, want.User)
+			}
+			report.PumpAmount = amount
+			report.PumpSolLimit = limit
+		}
+	}
+
+	if pumpInstructions != 1 {
+		return nil, violation(ErrActionMismatch, "交易包含 %d 条 pump 指令，期望 1 条", pumpInstructions)
+	}
+
+	switch want.Action {
+	case common.BUY:
+		if report.PumpAmount < want.Tokens {
+			return nil, violation(ErrAmountMismatch, "买入 %d 个代币，少于报价扣除滑点后的 %d", report.PumpAmount, want.Tokens)
+		}
+	case common.SELL:
+		if report.PumpAmount != want.Tokens {
+			return nil, violation(ErrAmountMismatch, "卖出 %d 个代币，请求卖出 %d", report.PumpAmount, want.Tokens)
+		}
+		if report.PumpSolLimit < want.MinSolOut {
+			return nil, violation(ErrMinSolOutTooLow, "最少获得 %d lamports，低于报价扣除滑点后的 %d lamports", report.PumpSolLimit, want.MinSolOut)
+		}
+	}
+
+	report.PriorityFee = unitLimit * unitPrice / 1_000_000
+	report.SolOut = report.Transfers + report.Rent + report.PriorityFee
+	if want.Action == common.BUY {
+		report.SolOut += report.PumpSolLimit
+	}
+	limit := want.MaxSolOut + report.PumpSolLimit*want.FeeAllowanceBps/10_000
+	if report.SolOut > limit {
+		return nil, violation(ErrSolLimitExceeded, "交易最多花费 %d lamports，超过允许的 %d lamports (买入上限=%d 转账=%d 租金=%d 优先费=%d)",
+			report.SolOut, limit, report.PumpSolLimit, report.Transfers, report.Rent, report.PriorityFee)
+	}
+	return report, nil
+}
+
+// systemOutflow 解析 System 指令中从用户转出的 lamports，只允许转账和建账户
+func systemOutflow(data []byte, accounts []*solana.AccountMeta, user solana.PublicKey) (uint64, error) {
+	if len(data) < 4 {
+		return 0, fmt.Errorf("System 指令数据过短")
+	}
+	kind := binary.LittleEndian.Uint32(data[:4])
+	switch kind {
+	case system.Instruction_Transfer, system.Instruction_CreateAccount:
+		// Transfer: u32 + u64 lamports; CreateAccount: u32 + u64 lamports + u64 space + owner
+		if len(data) < 12 || len(accounts) < 2 {
+			return 0, fmt.Errorf("System 指令格式错误")
+		}
+		if !accounts[0].PublicKey.Equals(user) {
+			return 0, nil
+		}
+		return binary.LittleEndian.Uint64(data[4:12]), nil
+	default:
+		return 0, fmt.Errorf("System 指令类型 %d 不允许", kind)
+	}
+}
@@ -0,0 +1,194 @@
+package inspect
+
+import (
+	"errors"
+	"pump_auto/internal/common"
+	"pump_auto/internal/pump"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+func testParams(t *testing.T) pump.TradeParams {
+	t.Helper()
+	user, _ := solana.NewRandomPrivateKey()
+	creator, _ := solana.NewRandomPrivateKey()
+	return pump.TradeParams{
+		Mint:             solana.MustPublicKeyFromBase58("7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump"),
+		Creator:          creator.PublicKey(),
+		User:             user.PublicKey(),
+		TokenAmount:      123_456,
+		SolLimit:         1_100_000,
+		ComputeUnitLimit: 100_000,
+		ComputeUnitPrice: pump.PriorityFeeToMicroLamports(0.0005, 100_000),
+	}
+}
+
+func TestInspect(t *testing.T) {
+	p := testParams(t)
+	priorityFee := uint64(500_000)
+	buyBudget := p.SolLimit + AssociatedTokenRent + priorityFee
+
+	withInstruction := func(extra solana.Instruction) func(t *testing.T) *solana.Transaction {
+		return func(t *testing.T) *solana.Transaction {
+			tx, err := pump.BuildBuyTransaction(p, solana.Hash{})
+			if err != nil {
+				t.Fatalf("BuildBuyTransaction() error = %v", err)
+			}
+			// 重新组装，附加一条额外指令
+			out, err := solana.NewTransaction(append(rebuild(tx), extra), solana.Hash{}, solana.TransactionPayer(p.User))
+			if err != nil {
+				t.Fatalf("NewTransaction() error = %v", err)
+			}
+			return out
+		}
+	}
+	buyTx := func(t *testing.T) *solana.Transaction {
+		tx, err := pump.BuildBuyTransaction(p, solana.Hash{})
+		if err != nil {
+			t.Fatalf("BuildBuyTransaction() error = %v", err)
+		}
+		return tx
+	}
+	stranger, _ := solana.NewRandomPrivateKey()
+
+	tests := []struct {
+		name    string
+		tx      func(t *testing.T) *solana.Transaction
+		want    Expectation
+		wantErr error
+	}{
+		{
+			name: "正常买入",
+			tx:   buyTx,
+			want: Expectation{Action: common.BUY, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget},
+		},
+		{
+			name:    "买入上限超出预算",
+			tx:      buyTx,
+			want:    Expectation{Action: common.BUY, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget - 1},
+			wantErr: ErrSolLimitExceeded,
+		},
+		{
+			name:    "买入数量少于报价",
+			tx:      buyTx,
+			want:    Expectation{Action: common.BUY, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget, Tokens: p.TokenAmount + 1},
+			wantErr: ErrAmountMismatch,
+		},
+		{
+			name:    "代币不一致",
+			tx:      buyTx,
+			want:    Expectation{Action: common.BUY, Mint: solana.SystemProgramID, User: p.User, MaxSolOut: buyBudget},
+			wantErr: ErrMintMismatch,
+		},
+		{
+			name:    "动作不一致",
+			tx:      buyTx,
+			want:    Expectation{Action: common.SELL, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget},
+			wantErr: ErrActionMismatch,
+		},
+		{
+			name:    "付款人不是钱包",
+			tx:      buyTx,
+			want:    Expectation{Action: common.BUY, Mint: p.Mint, User: stranger.PublicKey(), MaxSolOut: buyBudget},
+			wantErr: ErrUnexpectedSigner,
+		},
+		{
+			name:    "包含未允许的程序",
+			tx:      withInstruction(solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("x"))),
+			want:    Expectation{Action: common.BUY, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget},
+			wantErr: ErrProgramNotAllowed,
+		},
+		{
+			name:    "额外转账超出预算",
+			tx:      withInstruction(system.NewTransferInstruction(1, p.User, stranger.PublicKey()).Build()),
+			want:    Expectation{Action: common.BUY, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget},
+			wantErr: ErrSolLimitExceeded,
+		},
+		{
+			name: "预算内的服务费转账",
+			tx:   withInstruction(system.NewTransferInstruction(1_000, p.User, stranger.PublicKey()).Build()),
+			want: Expectation{Action: common.BUY, Mint: p.Mint, User: p.User, MaxSolOut: buyBudget + 1_000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Inspect(tt.tx(t), tt.want)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Inspect() error = %v", err)
+				}
+				if report.PumpSolLimit != p.SolLimit || report.PriorityFee != priorityFee {
+					t.Errorf("Inspect() report = %+v", report)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Inspect() error = %v, want %v", err, tt.wantErr)
+			}
+			var v *Violation
+			if !errors.As(err, &v) || v.Detail == "" {
+				t.Errorf("Inspect() 应返回带描述的 *Violation，实际 %T", err)
+			}
+		})
+	}
+}
+
+func TestInspectSell(t *testing.T) {
+	p := testParams(t)
+	tx, err := pump.BuildSellTransaction(p, solana.Hash{})
+	if err != nil {
+		t.Fatalf("BuildSellTransaction() error = %v", err)
+	}
+	// 卖出时最少获得不计入花费，只需覆盖优先费
+	sell := Expectation{Action: common.SELL, Mint: p.Mint, User: p.User, MaxSolOut: 500_000, Tokens: p.TokenAmount, MinSolOut: p.SolLimit}
+	report, err := Inspect(tx, sell)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if report.SolOut != 500_000 {
+		t.Errorf("SolOut = %d, want 500000", report.SolOut)
+	}
+
+	// 卖出数量必须与请求一致，最少获得不能低于报价
+	partial := sell
+	partial.Tokens = p.TokenAmount / 2
+	if _, err := Inspect(tx, partial); !errors.Is(err, ErrAmountMismatch) {
+		t.Errorf("卖出数量多于请求时应被拒绝，实际 %v", err)
+	}
+	floor := sell
+	floor.MinSolOut = p.SolLimit + 1
+	if _, err := Inspect(tx, floor); !errors.Is(err, ErrMinSolOutTooLow) {
+		t.Errorf("最少获得低于报价时应被拒绝，实际 %v", err)
+	}
+
+	// 服务费按卖出最少获得的万分比放行
+	withFee, err := solana.NewTransaction(
+		append(rebuild(tx), system.NewTransferInstruction(11_000, p.User, solana.SystemProgramID).Build()),
+		solana.Hash{}, solana.TransactionPayer(p.User),
+	)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	want := sell
+	want.FeeAllowanceBps = 100
+	if _, err := Inspect(withFee, want); err != nil {
+		t.Errorf("1%% 服务费应被放行: %v", err)
+	}
+	want.FeeAllowanceBps = 50
+	if _, err := Inspect(withFee, want); !errors.Is(err, ErrSolLimitExceeded) {
+		t.Errorf("超过 0.5%% 的服务费应被拒绝，实际 %v", err)
+	}
+}
+
+func rebuild(tx *solana.Transaction) []solana.Instruction {
+	var instructions []solana.Instruction
+	for _, inst := range tx.Message.Instructions {
+		accounts, _ := inst.ResolveInstructionAccounts(&tx.Message)
+		program, _ := tx.Message.Program(inst.ProgramIDIndex)
+		instructions = append(instructions, solana.NewInstruction(program, accounts, inst.Data))
+	}
+	return instructions
+}
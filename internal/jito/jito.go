@@ -0,0 +1,198 @@
+package jito
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+// DefaultEndpoint 主网 block engine 地址
+const DefaultEndpoint = "https://mainnet.block-engine.jito.wtf"
+
+// bundlesPath block engine 的 bundle JSON-RPC 路径
+const bundlesPath = "/api/v1/bundles"
+
+// MinTipLamports block engine 接受的最低小费
+const MinTipLamports = 1_000
+
+// TipAccounts 主网小费账户，未配置时随机选择一个以分散写锁
+var TipAccounts = []solana.PublicKey{
+	solana.MustPublicKeyFromBase58("96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5"),
+	solana.MustPublicKeyFromBase58("HFqU5x63VTqvQss8hp11i4wVV8bD44PvwucfZ2bU7gRe"),
+	solana.MustPublicKeyFromBase58("Cw8CFyM9FkoMi7K7Crf6HNQqf4uEMzpKw6QNghXLvLkY"),
+	solana.MustPublicKeyFromBase58("ADaUMid9yfUytqMBgopwjb2DTLSokTSzL1zt6iGPaS49"),
+	solana.MustPublicKeyFromBase58("DfXygSm4jCyNCybVYYK6DwvWqjKee8pbDmJGcLWNDXjh"),
+	solana.MustPublicKeyFromBase58("ADuUkR4vqLUMWXxW9gh6D6L8pMSawimctcNZ5pGwDcEt"),
+	solana.MustPublicKeyFromBase58("DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL"),
+	solana.MustPublicKeyFromBase58("3AVi9Tg9Uo68tJfuvoKvqKNWKkC5wPdSSdeBnizKZ6jT"),
+}
+
+// RandomTipAccount 随机返回一个主网小费账户
+func RandomTipAccount() solana.PublicKey {
+	return TipAccounts[rand.Intn(len(TipAccounts))]
+}
+
+// TipInstruction 向小费账户转账的指令
+func TipInstruction(from, tipAccount solana.PublicKey, lamports uint64) solana.Instruction {
+	return system.NewTransferInstruction(lamports, from, tipAccount).Build()
+}
+
+// BundleStatus bundle 在 block engine 中的状态
+type BundleStatus string
+
+const (
+	StatusPending BundleStatus = "Pending" // 尚未上链
+	StatusLanded  BundleStatus = "Landed"  // 已上链
+	StatusFailed  BundleStatus = "Failed"  // 所有区域都未能上链
+	StatusInvalid BundleStatus = "Invalid" // bundle 不存在或已超过5分钟
+)
+
+// ErrBundleNotLanded bundle 失败或失效，交易需要通过其它方式发送
+var ErrBundleNotLanded = errors.New("bundle 未上链")
+
+// Client block engine 客户端
+type Client struct {
+	endpoint string
+	http     *http.Client
+}
+
+// New 创建 block engine 客户端，endpoint 为空时使用主网地址
+func New(endpoint string) *Client {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendBundle 提交按顺序原子执行的已签名交易，返回 bundle ID
+func (c *Client) SendBundle(ctx context.Context, txs ...*solana.Transaction) (string, error) {
+	encoded := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return "", fmt.Errorf("序列化交易失败: %w", err)
+		}
+		encoded = append(encoded, base64.StdEncoding.EncodeToString(data))
+	}
+	var bundleID string
+	if err := c.call(ctx, "sendBundle", []interface{}{encoded, map[string]string{"encoding": "base64"}}, &bundleID); err != nil {
+		return "", err
+	}
+	return bundleID, nil
+}
+
+// InflightStatus 查询最近5分钟内提交的 bundle 的状态
+func (c *Client) InflightStatus(ctx context.Context, bundleID string) (BundleStatus, uint64, error) {
+	var out struct {
+		Value []struct {
+			BundleID   string       `json:"bundle_id"`
+			Status     BundleStatus `json:"status"`
+			LandedSlot *uint64      `json:"landed_slot"`
+		} `json:"value"`
+	}
+	if err := c.call(ctx, "getInflightBundleStatuses", []interface{}{[]string{bundleID}}, &out); err != nil {
+		return "", 0, err
+	}
+	for _, v := range out.Value {
+		if v.BundleID != bundleID {
+			continue
+		}
+		var slot uint64
+		if v.LandedSlot != nil {
+			slot = *v.LandedSlot
+		}
+		return v.Status, slot, nil
+	}
+	return StatusInvalid, 0, nil
+}
+
+// Wait 轮询 bundle 状态直到上链、失败或 ctx 结束
+// 上链时返回所在 slot，失败或失效时返回 ErrBundleNotLanded
+func (c *Client) Wait(ctx context.Context, bundleID string, interval time.Duration) (uint64, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, slot, err := c.InflightStatus(ctx, bundleID)
+		switch {
+		case err != nil:
+			// 查询失败时继续等待，由 ctx 控制总时长
+		case status == StatusLanded:
+			return slot, nil
+		case status == StatusFailed || status == StatusInvalid:
+			return 0, fmt.Errorf("%w: bundle %s 状态为 %s", ErrBundleNotLanded, bundleID, status)
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return 0, fmt.Errorf("等待 bundle %s 超时: %w (最后一次查询失败: %v)", bundleID, ctx.Err(), err)
+			}
+			return 0, fmt.Errorf("等待 bundle %s 超时: %w", bundleID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("block engine 错误 %d: %s", e.Code, e.Message)
+}
+
+// call 发送 JSON-RPC 请求并解析 result
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+bundlesPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 block engine 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取 block engine 响应失败: %w", err)
+	}
+	var out struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("解析 block engine 响应失败 (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out.Error != nil {
+		return out.Error
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("block engine 返回 HTTP %d", resp.StatusCode)
+	}
+	return json.Unmarshal(out.Result, result)
+}
@@ -0,0 +1,101 @@
+package jito
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// stubEngine 本地 block engine，依次返回 statuses 中的状态
+func stubEngine(t *testing.T, statuses []BundleStatus, bundles *[][]string) *httptest.Server {
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != bundlesPath {
+			t.Errorf("请求路径 = %s, want %s", r.URL.Path, bundlesPath)
+		}
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "sendBundle":
+			var txs []string
+			_ = json.Unmarshal(req.Params[0], &txs)
+			*bundles = append(*bundles, txs)
+			result = "bundle-1"
+		case "getInflightBundleStatuses":
+			status := statuses[polls]
+			if polls < len(statuses)-1 {
+				polls++
+			}
+			result = map[string]interface{}{
+				"context": map[string]interface{}{"slot": 10},
+				"value":   []interface{}{map[string]interface{}{"bundle_id": "bundle-1", "status": status, "landed_slot": 42}},
+			}
+		default:
+			t.Errorf("未预期的调用: %s", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func TestBundle(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	tip, err := solana.NewTransaction(
+		[]solana.Instruction{TipInstruction(key.PublicKey(), TipAccounts[0], MinTipLamports)},
+		solana.Hash{}, solana.TransactionPayer(key.PublicKey()),
+	)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	if _, err := tip.Sign(func(solana.PublicKey) *solana.PrivateKey { return &key }); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		statuses []BundleStatus
+		wantSlot uint64
+		wantErr  error
+	}{
+		{name: "等待后上链", statuses: []BundleStatus{StatusPending, StatusLanded}, wantSlot: 42},
+		{name: "上链失败", statuses: []BundleStatus{StatusPending, StatusFailed}, wantErr: ErrBundleNotLanded},
+		{name: "一直未上链", statuses: []BundleStatus{StatusPending}, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bundles [][]string
+			server := stubEngine(t, tt.statuses, &bundles)
+			defer server.Close()
+			c := New(server.URL)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			id, err := c.SendBundle(ctx, tip)
+			if err != nil {
+				t.Fatalf("SendBundle() error = %v", err)
+			}
+			if len(bundles) != 1 || len(bundles[0]) != 1 {
+				t.Fatalf("block engine 收到的 bundle = %v", bundles)
+			}
+			slot, err := c.Wait(ctx, id, 5*time.Millisecond)
+			if !errors.Is(err, tt.wantErr) && !(err == nil && tt.wantErr == nil) {
+				t.Fatalf("Wait() error = %v, want %v", err, tt.wantErr)
+			}
+			if slot != tt.wantSlot {
+				t.Errorf("Wait() slot = %d, want %d", slot, tt.wantSlot)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package mintinfo
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Source 缓存依赖的RPC方法，*solana.Client 实现了该接口
+type Source interface {
+	GetMultipleAccounts(accounts ...solana.PublicKey) (*rpc.GetMultipleAccountsResult, error)
+}
+
+// getMultipleAccounts 单次请求的账户数上限
+const maxAccountsPerRequest = 100
+
+// Cache 按代币地址缓存 Mint 信息
+// 精度和代币程序创建后不会改变；供应量和权限可能变化，需要最新值时先调用 Forget
+type Cache struct {
+	source Source
+
+	mu    sync.RWMutex
+	mints map[solana.PublicKey]*Info
+}
+
+// NewCache 创建 Mint 信息缓存
+func NewCache(source Source) *Cache {
+	return &Cache{
+		source: source,
+		mints:  make(map[solana.PublicKey]*Info),
+	}
+}
+
+// Get 返回代币的 Mint 信息，未缓存时从链上获取
+func (c *Cache) Get(mint solana.PublicKey) (*Info, error) {
+	c.mu.RLock()
+	info, ok := c.mints[mint]
+	c.mu.RUnlock()
+	if ok {
+		return info, nil
+	}
+	if err := c.Load(mint); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mints[mint], nil
+}
+
+// Load 批量获取尚未缓存的代币 Mint 信息
+func (c *Cache) Load(mints ...solana.PublicKey) error {
+	c.mu.RLock()
+	missing := make([]solana.PublicKey, 0, len(mints))
+	for _, mint := range mints {
+		if _, ok := c.mints[mint]; !ok {
+			missing = append(missing, mint)
+		}
+	}
+	c.mu.RUnlock()
+
+	for len(missing) > 0 {
+		batch := missing
+		if len(batch) > maxAccountsPerRequest {
+			batch = batch[:maxAccountsPerRequest]
+		}
+		missing = missing[len(batch):]
+
+		out, err := c.source.GetMultipleAccounts(batch...)
+		if err != nil {
+			return fmt.Errorf("获取代币 Mint 账户失败: %w", err)
+		}
+		if out == nil || len(out.Value) != len(batch) {
+			return fmt.Errorf("获取代币 Mint 账户失败: 节点返回的账户数量不符")
+		}
+		for i, account := range out.Value {
+			if account == nil || account.Data == nil {
+				return fmt.Errorf("%w: %s 账户不存在", ErrNotMint, batch[i])
+			}
+			info, err := Parse(batch[i], account.Owner, account.Data.GetBinary())
+			if err != nil {
+				return err
+			}
+			c.Put(info)
+		}
+	}
+	return nil
+}
+
+// Put 写入缓存
+func (c *Cache) Put(info *Info) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mints[info.Mint] = info
+}
+
+// Forget 移除代币的缓存
+func (c *Cache) Forget(mint solana.PublicKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.mints, mint)
+}
@@ -0,0 +1,159 @@
+package mintinfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// 代币 Mint 账户布局
+const (
+	mintLen         = 82  // Mint 基础数据长度，两种代币程序相同
+	accountLen      = 165 // Token-2022 扩展数据前按代币账户长度补齐
+	accountTypeLen  = 1
+	accountTypeMint = 1
+
+	extensionTransferFeeConfig = 1
+	transferFeeConfigLen       = 32 + 32 + 8 + 2*transferFeeLen
+	transferFeeLen             = 8 + 8 + 2 // epoch, maximum_fee, transfer_fee_basis_points
+
+	maxBasisPoints = 10_000
+)
+
+var (
+	ErrNotMint        = errors.New("账户不是代币 Mint")
+	ErrUnknownProgram = errors.New("账户不属于已知的代币程序")
+)
+
+// TransferFee Token-2022 转账手续费扩展中当前生效的费率
+type TransferFee struct {
+	Epoch       uint64 // 费率生效的 epoch
+	BasisPoints uint16 // 万分比
+	MaximumFee  uint64 // 每笔转账的手续费上限(最小单位)
+}
+
+// Fee 计算转账 amount 时扣除的手续费，向上取整且不超过上限
+func (f TransferFee) Fee(amount uint64) uint64 {
+	if f.BasisPoints == 0 || amount == 0 {
+		return 0
+	}
+	// amount * basisPoints 可能超出 uint64
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(amount), big.NewInt(int64(f.BasisPoints)))
+	fee.Add(fee, big.NewInt(maxBasisPoints-1))
+	fee.Quo(fee, big.NewInt(maxBasisPoints))
+	if !fee.IsUint64() || fee.Uint64() > f.MaximumFee {
+		return f.MaximumFee
+	}
+	return fee.Uint64()
+}
+
+// Info 代币 Mint 的基础信息
+type Info struct {
+	Mint            solana.PublicKey
+	Program         solana.PublicKey // SPL Token 或 Token-2022
+	Decimals        uint8
+	Supply          uint64
+	MintAuthority   *solana.PublicKey // 为 nil 表示已放弃增发权限
+	FreezeAuthority *solana.PublicKey // 为 nil 表示无法冻结持有人账户
+	TransferFee     *TransferFee      // 仅 Token-2022 带转账手续费扩展时存在
+}
+
+// Token2022 是否为 Token-2022 代币
+func (i *Info) Token2022() bool {
+	return i.Program.Equals(solana.Token2022ProgramID)
+}
+
+// AssociatedAccount 推导 owner 持有该代币的关联代币账户，Token-2022 代币的地址与 SPL Token 不同
+func (i *Info) AssociatedAccount(owner solana.PublicKey) (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress([][]byte{owner[:], i.Program[:], i.Mint[:]}, solana.SPLAssociatedTokenAccountProgramID)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("推导关联代币账户失败: %w", err)
+	}
+	return addr, nil
+}
+
+// UIAmount 将最小单位换算为带精度的数量
+func (i *Info) UIAmount(raw uint64) float64 {
+	return float64(raw) / math.Pow10(int(i.Decimals))
+}
+
+// RawAmount 将带精度的数量换算为最小单位
+func (i *Info) RawAmount(amount float64) uint64 {
+	return uint64(math.Round(amount * math.Pow10(int(i.Decimals))))
+}
+
+// NetAmount 转账 raw 后接收方实际到账的数量(扣除转账手续费)
+func (i *Info) NetAmount(raw uint64) uint64 {
+	if i.TransferFee == nil {
+		return raw
+	}
+	return raw - i.TransferFee.Fee(raw)
+}
+
+// Parse 解析 Mint 账户数据，owner 为账户所属的代币程序
+func Parse(mint, owner solana.PublicKey, data []byte) (*Info, error) {
+	if !owner.Equals(solana.TokenProgramID) && !owner.Equals(solana.Token2022ProgramID) {
+		return nil, fmt.Errorf("%w: %s 属于 %s", ErrUnknownProgram, mint, owner)
+	}
+	if len(data) < mintLen {
+		return nil, fmt.Errorf("%w: %s 数据长度 %d", ErrNotMint, mint, len(data))
+	}
+	if data[45] == 0 {
+		return nil, fmt.Errorf("%w: %s 尚未初始化", ErrNotMint, mint)
+	}
+	info := &Info{
+		Mint:            mint,
+		Program:         owner,
+		MintAuthority:   optionalKey(data[0:36]),
+		Supply:          binary.LittleEndian.Uint64(data[36:44]),
+		Decimals:        data[44],
+		FreezeAuthority: optionalKey(data[46:82]),
+	}
+	if !info.Token2022() || len(data) == mintLen {
+		return info, nil
+	}
+
+	// Token-2022: 补齐到代币账户长度后是账户类型和 TLV 扩展
+	if len(data) < accountLen+accountTypeLen || data[accountLen] != accountTypeMint {
+		return nil, fmt.Errorf("%w: %s 账户类型无效", ErrNotMint, mint)
+	}
+	for ext := data[accountLen+accountTypeLen:]; len(ext) >= 4; {
+		typ := binary.LittleEndian.Uint16(ext[0:2])
+		size := int(binary.LittleEndian.Uint16(ext[2:4]))
+		if len(ext) < 4+size {
+			return nil, fmt.Errorf("%w: %s 扩展 %d 数据不完整", ErrNotMint, mint, typ)
+		}
+		value := ext[4 : 4+size]
+		if typ == extensionTransferFeeConfig {
+			if size < transferFeeConfigLen {
+				return nil, fmt.Errorf("%w: %s 转账手续费扩展长度 %d", ErrNotMint, mint, size)
+			}
+			// 只记录较新的一档费率，它在 epoch 到达后生效，通常就是当前费率
+			newer := parseTransferFee(value[72+transferFeeLen:])
+			info.TransferFee = &newer
+		}
+		ext = ext[4+size:]
+	}
+	return info, nil
+}
+
+// optionalKey 解析 COption<Pubkey>: 4 字节标记 + 32 字节公钥
+func optionalKey(data []byte) *solana.PublicKey {
+	if binary.LittleEndian.Uint32(data[0:4]) == 0 {
+		return nil
+	}
+	key := solana.PublicKeyFromBytes(data[4:36])
+	return &key
+}
+
+func parseTransferFee(data []byte) TransferFee {
+	return TransferFee{
+		Epoch:       binary.LittleEndian.Uint64(data[0:8]),
+		MaximumFee:  binary.LittleEndian.Uint64(data[8:16]),
+		BasisPoints: binary.LittleEndian.Uint16(data[16:18]),
+	}
+}
@@ -0,0 +1,211 @@
+package mintinfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// mintData 按 Mint 布局编码账户数据，authority 为零值时表示没有该权限
+func mintData(decimals uint8, supply uint64, mintAuthority, freezeAuthority solana.PublicKey) []byte {
+	data := make([]byte, mintLen)
+	if !mintAuthority.IsZero() {
+		binary.LittleEndian.PutUint32(data[0:4], 1)
+		copy(data[4:36], mintAuthority[:])
+	}
+	binary.LittleEndian.PutUint64(data[36:44], supply)
+	data[44] = decimals
+	data[45] = 1
+	if !freezeAuthority.IsZero() {
+		binary.LittleEndian.PutUint32(data[46:50], 1)
+		copy(data[50:82], freezeAuthority[:])
+	}
+	return data
+}
+
+// withTransferFee 追加 Token-2022 账户类型和转账手续费扩展
+func withTransferFee(base []byte, basisPoints uint16, maximumFee uint64) []byte {
+	data := make([]byte, accountLen, accountLen+accountTypeLen+4+transferFeeConfigLen)
+	copy(data, base)
+	data = append(data, accountTypeMint)
+
+	ext := make([]byte, 4+transferFeeConfigLen)
+	binary.LittleEndian.PutUint16(ext[0:2], extensionTransferFeeConfig)
+	binary.LittleEndian.PutUint16(ext[2:4], transferFeeConfigLen)
+	newer := ext[4+72+transferFeeLen:]
+	binary.LittleEndian.PutUint64(newer[0:8], 500)
+	binary.LittleEndian.PutUint64(newer[8:16], maximumFee)
+	binary.LittleEndian.PutUint16(newer[16:18], basisPoints)
+	return append(data, ext...)
+}
+
+func TestParse(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	authority := solana.NewWallet().PublicKey()
+
+	tests := []struct {
+		name    string
+		owner   solana.PublicKey
+		data    []byte
+		wantErr error
+		check   func(t *testing.T, info *Info)
+	}{
+		{
+			name:  "SPL Token 已放弃权限",
+			owner: solana.TokenProgramID,
+			data:  mintData(6, 1_000_000_000_000_000, solana.PublicKey{}, solana.PublicKey{}),
+			check: func(t *testing.T, info *Info) {
+				if info.Decimals != 6 || info.Supply != 1_000_000_000_000_000 || info.Token2022() {
+					t.Errorf("Info = %+v", info)
+				}
+				if info.MintAuthority != nil || info.FreezeAuthority != nil {
+					t.Errorf("权限应为空: mint=%v freeze=%v", info.MintAuthority, info.FreezeAuthority)
+				}
+				if got := info.UIAmount(1_500_000); got != 1.5 {
+					t.Errorf("UIAmount() = %v, want 1.5", got)
+				}
+			},
+		},
+		{
+			name:  "Token-2022 带转账手续费",
+			owner: solana.Token2022ProgramID,
+			data:  withTransferFee(mintData(9, 10, authority, authority), 250, 1_000),
+			check: func(t *testing.T, info *Info) {
+				if !info.Token2022() || info.Decimals != 9 {
+					t.Errorf("Info = %+v", info)
+				}
+				if info.FreezeAuthority == nil || !info.FreezeAuthority.Equals(authority) {
+					t.Errorf("FreezeAuthority = %v, want %s", info.FreezeAuthority, authority)
+				}
+				if info.TransferFee == nil || info.TransferFee.BasisPoints != 250 || info.TransferFee.Epoch != 500 {
+					t.Fatalf("TransferFee = %+v", info.TransferFee)
+				}
+				if got := info.NetAmount(10_000); got != 9_750 {
+					t.Errorf("NetAmount(10000) = %d, want 9750", got)
+				}
+				if got := info.NetAmount(1_000_000); got != 999_000 {
+					t.Errorf("NetAmount(1000000) = %d, 手续费应受上限限制", got)
+				}
+			},
+		},
+		{
+			name:  "Token-2022 无扩展",
+			owner: solana.Token2022ProgramID,
+			data:  mintData(6, 1, solana.PublicKey{}, solana.PublicKey{}),
+			check: func(t *testing.T, info *Info) {
+				if info.TransferFee != nil {
+					t.Errorf("TransferFee = %+v, want nil", info.TransferFee)
+				}
+			},
+		},
+		{
+			name:    "不属于代币程序",
+			owner:   solana.SystemProgramID,
+			data:    mintData(6, 1, solana.PublicKey{}, solana.PublicKey{}),
+			wantErr: ErrUnknownProgram,
+		},
+		{
+			name:    "数据过短",
+			owner:   solana.TokenProgramID,
+			data:    make([]byte, 40),
+			wantErr: ErrNotMint,
+		},
+		{
+			name:    "未初始化",
+			owner:   solana.TokenProgramID,
+			data:    make([]byte, mintLen),
+			wantErr: ErrNotMint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Parse(mint, tt.owner, tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, info)
+			}
+		})
+	}
+}
+
+type fakeSource struct {
+	accounts map[solana.PublicKey]*rpc.Account
+	calls    int
+}
+
+func (f *fakeSource) GetMultipleAccounts(accounts ...solana.PublicKey) (*rpc.GetMultipleAccountsResult, error) {
+	f.calls++
+	out := &rpc.GetMultipleAccountsResult{}
+	for _, key := range accounts {
+		out.Value = append(out.Value, f.accounts[key])
+	}
+	return out, nil
+}
+
+func TestAssociatedAccount(t *testing.T) {
+	owner := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+
+	spl, err := (&Info{Mint: mint, Program: solana.TokenProgramID}).AssociatedAccount(owner)
+	if err != nil {
+		t.Fatalf("AssociatedAccount() error = %v", err)
+	}
+	want, _, _ := solana.FindAssociatedTokenAddress(owner, mint)
+	if spl != want {
+		t.Errorf("SPL Token 关联账户 = %s, want %s", spl, want)
+	}
+
+	token2022, err := (&Info{Mint: mint, Program: solana.Token2022ProgramID}).AssociatedAccount(owner)
+	if err != nil {
+		t.Fatalf("AssociatedAccount() error = %v", err)
+	}
+	if token2022 == spl {
+		t.Errorf("Token-2022 关联账户不应与 SPL Token 相同")
+	}
+}
+
+func TestCache(t *testing.T) {
+	spl := solana.NewWallet().PublicKey()
+	token2022 := solana.NewWallet().PublicKey()
+	missing := solana.NewWallet().PublicKey()
+	source := &fakeSource{accounts: map[solana.PublicKey]*rpc.Account{
+		spl: {
+			Owner: solana.TokenProgramID,
+			Data:  rpc.DataBytesOrJSONFromBytes(mintData(6, 1, solana.PublicKey{}, solana.PublicKey{})),
+		},
+		token2022: {
+			Owner: solana.Token2022ProgramID,
+			Data:  rpc.DataBytesOrJSONFromBytes(mintData(9, 1, solana.PublicKey{}, solana.PublicKey{})),
+		},
+	}}
+	cache := NewCache(source)
+
+	if err := cache.Load(spl, token2022); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if source.calls != 1 {
+		t.Errorf("批量加载请求次数 = %d, want 1", source.calls)
+	}
+	info, err := cache.Get(token2022)
+	if err != nil || info.Decimals != 9 || !info.Token2022() {
+		t.Fatalf("Get() = %+v, %v", info, err)
+	}
+	if source.calls != 1 {
+		t.Errorf("命中缓存时不应访问网络，请求次数 = %d", source.calls)
+	}
+
+	if _, err := cache.Get(missing); !errors.Is(err, ErrNotMint) {
+		t.Errorf("不存在的代币 Get() error = %v, want %v", err, ErrNotMint)
+	}
+
+	cache.Forget(spl)
+	if _, err := cache.Get(spl); err != nil || source.calls != 3 {
+		t.Errorf("移除后应重新获取: error = %v, 请求次数 = %d", err, source.calls)
+	}
+}
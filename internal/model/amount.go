@@ -0,0 +1,142 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// LamportsPerSOL 1 SOL 对应的 lamports
+const LamportsPerSOL = 1_000_000_000
+
+// MaxBps 万分比的上限(100%)
+const MaxBps = 10_000
+
+// Lamports SOL 数量，以 lamports 计
+type Lamports uint64
+
+// SOLToLamports 将以SOL计的数量四舍五入为 lamports，非正数返回0
+func SOLToLamports(sol float64) Lamports {
+	if sol <= 0 || math.IsNaN(sol) {
+		return 0
+	}
+	return Lamports(math.Round(sol * LamportsPerSOL))
+}
+
+// SOL 换算为以SOL计的浮点数，仅用于展示和价格计算
+func (l Lamports) SOL() float64 {
+	return float64(l) / LamportsPerSOL
+}
+
+// String 以SOL为单位的精确十进制表示，如 0.0015
+func (l Lamports) String() string {
+	return formatUnits(uint64(l), 9)
+}
+
+// TokenAmount 代币数量，以最小单位计并记录精度
+type TokenAmount struct {
+	Raw      uint64
+	Decimals uint8
+}
+
+// NewTokenAmount 使用最小单位创建代币数量
+func NewTokenAmount(raw uint64, decimals uint8) TokenAmount {
+	return TokenAmount{Raw: raw, Decimals: decimals}
+}
+
+// UIToTokenAmount 将带精度的浮点数量四舍五入为最小单位，用于接入只提供浮点数的外部数据
+func UIToTokenAmount(ui float64, decimals uint8) TokenAmount {
+	if ui <= 0 || math.IsNaN(ui) {
+		return TokenAmount{Decimals: decimals}
+	}
+	return TokenAmount{Raw: uint64(math.Round(ui * math.Pow10(int(decimals)))), Decimals: decimals}
+}
+
+// ParseTokenAmount 精确解析十进制字符串，小数位数不能超过精度
+func ParseTokenAmount(s string, decimals uint8) (TokenAmount, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if whole == "" && frac == "" {
+		return TokenAmount{}, errors.New("代币数量为空")
+	}
+	if len(frac) > int(decimals) {
+		return TokenAmount{}, fmt.Errorf("代币数量 %q 的小数位超过精度 %d", s, decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	raw, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return TokenAmount{}, fmt.Errorf("解析代币数量 %q 失败: %w", s, err)
+	}
+	return TokenAmount{Raw: raw, Decimals: decimals}, nil
+}
+
+// UI 换算为带精度的浮点数，仅用于展示和价格计算
+func (a TokenAmount) UI() float64 {
+	return float64(a.Raw) / math.Pow10(int(a.Decimals))
+}
+
+// String 精确的十进制表示，去掉末尾多余的0
+func (a TokenAmount) String() string {
+	return formatUnits(a.Raw, a.Decimals)
+}
+
+// IsZero 数量是否为0
+func (a TokenAmount) IsZero() bool {
+	return a.Raw == 0
+}
+
+// Sub 返回 a - b，结果不小于0
+func (a TokenAmount) Sub(b TokenAmount) TokenAmount {
+	if b.Raw >= a.Raw {
+		return TokenAmount{Decimals: a.Decimals}
+	}
+	return TokenAmount{Raw: a.Raw - b.Raw, Decimals: a.Decimals}
+}
+
+// Min 返回较小的数量
+func (a TokenAmount) Min(b TokenAmount) TokenAmount {
+	if b.Raw < a.Raw {
+		return TokenAmount{Raw: b.Raw, Decimals: a.Decimals}
+	}
+	return a
+}
+
+// MulBps 按万分比取数量并向下取整，bps 超过 MaxBps 时按 100% 计算
+func (a TokenAmount) MulBps(bps uint64) TokenAmount {
+	if bps >= MaxBps {
+		return a
+	}
+	hi, lo := bits.Mul64(a.Raw, bps)
+	q, _ := bits.Div64(hi, lo, MaxBps)
+	return TokenAmount{Raw: q, Decimals: a.Decimals}
+}
+
+// PctToBps 将比例(如 0.25)四舍五入为万分比
+func PctToBps(pct float64) uint64 {
+	if pct <= 0 {
+		return 0
+	}
+	if pct >= 1 {
+		return MaxBps
+	}
+	return uint64(math.Round(pct * MaxBps))
+}
+
+// formatUnits 将最小单位格式化为十进制字符串
+func formatUnits(raw uint64, decimals uint8) string {
+	s := strconv.FormatUint(raw, 10)
+	if decimals == 0 {
+		return s
+	}
+	if len(s) <= int(decimals) {
+		s = strings.Repeat("0", int(decimals)-len(s)+1) + s
+	}
+	point := len(s) - int(decimals)
+	frac := strings.TrimRight(s[point:], "0")
+	if frac == "" {
+		return s[:point]
+	}
+	return s[:point] + "." + frac
+}
@@ -0,0 +1,55 @@
+package model
+
+import "testing"
+
+func TestTokenAmount(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "整数", got: NewTokenAmount(5_000_000, 6).String(), want: "5"},
+		{name: "小于1", got: NewTokenAmount(1_500, 6).String(), want: "0.0015"},
+		{name: "保留全部小数位", got: NewTokenAmount(123_456_789_123, 6).String(), want: "123456.789123"},
+		{name: "无精度", got: NewTokenAmount(42, 0).String(), want: "42"},
+		{name: "lamports", got: Lamports(1_000_000).String(), want: "0.001"},
+		{name: "浮点数四舍五入到最小单位", got: UIToTokenAmount(0.1+0.2, 6).String(), want: "0.3"},
+		{name: "按万分比向下取整", got: NewTokenAmount(1_000_003, 6).MulBps(3_000).String(), want: "0.3"},
+		{name: "相减不小于0", got: NewTokenAmount(1, 6).Sub(NewTokenAmount(2, 6)).String(), want: "0"},
+		{name: "超大数量按万分比不溢出", got: NewTokenAmount(1<<63, 0).MulBps(5_000).String(), want: "4611686018427387904"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{name: "整数", in: "12", want: 12_000_000},
+		{name: "小数", in: "0.000001", want: 1},
+		{name: "省略整数部分", in: ".5", want: 500_000},
+		{name: "小数位超过精度", in: "0.0000001", wantErr: true},
+		{name: "非数字", in: "1e3", wantErr: true},
+		{name: "空字符串", in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTokenAmount(tt.in, 6)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTokenAmount(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Raw != tt.want {
+				t.Errorf("ParseTokenAmount(%q) = %d, want %d", tt.in, got.Raw, tt.want)
+			}
+		})
+	}
+}
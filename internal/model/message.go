@@ -18,7 +18,7 @@ type QueueMessage struct {
 	TokenName    string                 // 代币名称
 	TokenURI     string                 // 代币URI
 	Price        float64                // 价格
-	Amount       float64                // 数量
+	Amount       TokenAmount            // 数量
 	Timestamp    time.Time              // 时间戳
 	ExtraData    map[string]interface{} // 额外数据
 }
@@ -37,7 +37,7 @@ func NewBuyMessage(address string, symbol string, name string, uri string) *Queu
 }
 
 // 创建卖出消息
-func NewSellMessage(address string, symbol string, name string, price float64, amount float64) *QueueMessage {
+func NewSellMessage(address string, symbol string, name string, price float64, amount TokenAmount) *QueueMessage {
 	return &QueueMessage{
 		Type:         MessageTypeSell,
 		TokenAddress: address,
@@ -0,0 +1,64 @@
+package pump
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Anchor emit_cpi 事件指令前缀，以及 sha256("event:TradeEvent")[:8]
+var (
+	EventInstructionTag     = []byte{0xe4, 0x45, 0xa5, 0x2e, 0x51, 0xcb, 0x9a, 0x1d}
+	TradeEventDiscriminator = []byte{189, 219, 127, 211, 78, 230, 97, 238}
+)
+
+// TradeEvent 字段布局: mint(32) sol(8) token(8) is_buy(1) user(32) timestamp(8) + 4个储备u64(32)
+// 新版程序在后面追加了手续费和创建者字段，解析时忽略
+const tradeEventLen = 32 + 8 + 8 + 1 + 32 + 8 + 4*8
+
+// TradeEvent pump 程序每次买卖通过自调用记录的成交事件
+type TradeEvent struct {
+	Mint                 solana.PublicKey
+	SolAmount            uint64 // lamports，不含手续费
+	TokenAmount          uint64 // 代币最小单位
+	IsBuy                bool
+	User                 solana.PublicKey
+	Timestamp            int64
+	VirtualSolReserves   uint64
+	VirtualTokenReserves uint64
+	RealSolReserves      uint64
+	RealTokenReserves    uint64
+}
+
+// IsTradeEvent 判断指令数据是否为 TradeEvent 的 emit_cpi
+func IsTradeEvent(data []byte) bool {
+	return len(data) >= 16 &&
+		bytes.Equal(data[:8], EventInstructionTag) &&
+		bytes.Equal(data[8:16], TradeEventDiscriminator)
+}
+
+// DecodeTradeEvent 解析 emit_cpi 指令数据中的 TradeEvent
+func DecodeTradeEvent(data []byte) (*TradeEvent, error) {
+	if !IsTradeEvent(data) {
+		return nil, fmt.Errorf("不是 TradeEvent 指令数据")
+	}
+	body := data[16:]
+	if len(body) < tradeEventLen {
+		return nil, fmt.Errorf("TradeEvent 数据长度不足，期望至少 %d 字节，实际 %d 字节", tradeEventLen, len(body))
+	}
+
+	e := &TradeEvent{}
+	copy(e.Mint[:], body[0:32])
+	e.SolAmount = binary.LittleEndian.Uint64(body[32:40])
+	e.TokenAmount = binary.LittleEndian.Uint64(body[40:48])
+	e.IsBuy = body[48] != 0
+	copy(e.User[:], body[49:81])
+	e.Timestamp = int64(binary.LittleEndian.Uint64(body[81:89]))
+	e.VirtualSolReserves = binary.LittleEndian.Uint64(body[89:97])
+	e.VirtualTokenReserves = binary.LittleEndian.Uint64(body[97:105])
+	e.RealSolReserves = binary.LittleEndian.Uint64(body[105:113])
+	e.RealTokenReserves = binary.LittleEndian.Uint64(body[113:121])
+	return e, nil
+}
@@ -0,0 +1,147 @@
+package pump
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"pump_auto/internal/common"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Anchor 指令鉴别码 sha256("global:<name>")[:8]
+var (
+	BuyDiscriminator  = []byte{102, 6, 61, 18, 1, 218, 235, 234}
+	SellDiscriminator = []byte{51, 230, 133, 164, 1, 127, 131, 173}
+)
+
+// 指令数据长度: 鉴别码 + 两个 u64 参数
+const tradeInstructionDataLen = 8 + 8 + 8
+
+// NewBuyAccounts 根据代币、创建者和用户推导买入指令所需的全部账户
+func NewBuyAccounts(mint, creator, user solana.PublicKey) (*common.PumpfunBuyInstruction, error) {
+	bondingCurve, err := DeriveBondingCurve(mint)
+	if err != nil {
+		return nil, err
+	}
+	associatedBondingCurve, err := DeriveAssociatedBondingCurve(bondingCurve, mint)
+	if err != nil {
+		return nil, err
+	}
+	associatedUser, _, err := solana.FindAssociatedTokenAddress(user, mint)
+	if err != nil {
+		return nil, fmt.Errorf("推导用户代币账户失败: %w", err)
+	}
+	creatorVault, err := DeriveCreatorVault(creator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.PumpfunBuyInstruction{
+		Global:                 GlobalAccount,
+		FeeReceipt:             FeeRecipient,
+		Mint:                   mint,
+		BondingCurve:           bondingCurve,
+		AssociatedBondingCurve: associatedBondingCurve,
+		AssociatedUser:         associatedUser,
+		User:                   user,
+		SystemProgram:          solana.SystemProgramID,
+		TokenProgram:           solana.TokenProgramID,
+		CreatorVault:           creatorVault,
+		EventAuthority:         EventAuthority,
+		Program:                ProgramID,
+	}, nil
+}
+
+// NewSellAccounts 推导卖出指令所需的全部账户
+func NewSellAccounts(mint, creator, user solana.PublicKey) (*common.PumpfunSellInstruction, error) {
+	buy, err := NewBuyAccounts(mint, creator, user)
+	if err != nil {
+		return nil, err
+	}
+	return &common.PumpfunSellInstruction{
+		Global:                 buy.Global,
+		FeeReceipt:             buy.FeeReceipt,
+		Mint:                   buy.Mint,
+		BondingCurve:           buy.BondingCurve,
+		AssociatedBondingCurve: buy.AssociatedBondingCurve,
+		AssociatedUser:         buy.AssociatedUser,
+		User:                   buy.User,
+		SystemProgram:          buy.SystemProgram,
+		CreatorVault:           buy.CreatorVault,
+		TokenProgram:           buy.TokenProgram,
+		EventAuthority:         buy.EventAuthority,
+		Program:                buy.Program,
+	}, nil
+}
+
+// BuildBuyInstruction 编码买入指令，账户顺序与程序 IDL 一致
+func BuildBuyInstruction(accounts *common.PumpfunBuyInstruction) (solana.Instruction, error) {
+	if accounts.Input == nil {
+		return nil, fmt.Errorf("买入指令缺少参数")
+	}
+	metas := solana.AccountMetaSlice{
+		solana.Meta(accounts.Global),
+		solana.Meta(accounts.FeeReceipt).WRITE(),
+		solana.Meta(accounts.Mint),
+		solana.Meta(accounts.BondingCurve).WRITE(),
+		solana.Meta(accounts.AssociatedBondingCurve).WRITE(),
+		solana.Meta(accounts.AssociatedUser).WRITE(),
+		solana.Meta(accounts.User).WRITE().SIGNER(),
+		solana.Meta(accounts.SystemProgram),
+		solana.Meta(accounts.TokenProgram),
+		solana.Meta(accounts.CreatorVault).WRITE(),
+		solana.Meta(accounts.EventAuthority),
+		solana.Meta(accounts.Program),
+	}
+	data := encodeTradeData(BuyDiscriminator, accounts.Input.Amount, accounts.Input.MaxSolCost)
+	return solana.NewInstruction(ProgramID, metas, data), nil
+}
+
+// BuildSellInstruction 编码卖出指令，注意 creator_vault 位于 token_program 之前
+func BuildSellInstruction(accounts *common.PumpfunSellInstruction) (solana.Instruction, error) {
+	if accounts.Input == nil {
+		return nil, fmt.Errorf("卖出指令缺少参数")
+	}
+	metas := solana.AccountMetaSlice{
+		solana.Meta(accounts.Global),
+		solana.Meta(accounts.FeeReceipt).WRITE(),
+		solana.Meta(accounts.Mint),
+		solana.Meta(accounts.BondingCurve).WRITE(),
+		solana.Meta(accounts.AssociatedBondingCurve).WRITE(),
+		solana.Meta(accounts.AssociatedUser).WRITE(),
+		solana.Meta(accounts.User).WRITE().SIGNER(),
+		solana.Meta(accounts.SystemProgram),
+		solana.Meta(accounts.CreatorVault).WRITE(),
+		solana.Meta(accounts.TokenProgram),
+		solana.Meta(accounts.EventAuthority),
+		solana.Meta(accounts.Program),
+	}
+	data := encodeTradeData(SellDiscriminator, accounts.Input.Amount, accounts.Input.MinSolOutput)
+	return solana.NewInstruction(ProgramID, metas, data), nil
+}
+
+func encodeTradeData(discriminator []byte, amount uint64, solLimit uint64) []byte {
+	data := make([]byte, tradeInstructionDataLen)
+	copy(data, discriminator)
+	binary.LittleEndian.PutUint64(data[8:16], amount)
+	binary.LittleEndian.PutUint64(data[16:24], solLimit)
+	return data
+}
+
+// DecodeTradeData 解析买入/卖出指令数据，返回动作、代币数量和SOL上限/下限
+func DecodeTradeData(data []byte) (common.TradeAction, uint64, uint64, error) {
+	if len(data) < tradeInstructionDataLen {
+		return "", 0, 0, fmt.Errorf("指令数据长度不足，期望至少 %d 字节，实际 %d 字节", tradeInstructionDataLen, len(data))
+	}
+	var action common.TradeAction
+	switch {
+	case bytes.Equal(data[:8], BuyDiscriminator):
+		action = common.BUY
+	case bytes.Equal(data[:8], SellDiscriminator):
+		action = common.SELL
+	default:
+		return "", 0, 0, fmt.Errorf("未知的指令鉴别码: %v", data[:8])
+	}
+	return action, binary.LittleEndian.Uint64(data[8:16]), binary.LittleEndian.Uint64(data[16:24]), nil
+}
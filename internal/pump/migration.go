@@ -0,0 +1,65 @@
+package pump
+
+import (
+	"pump_auto/internal/common"
+	"sync"
+	"time"
+)
+
+// 迁移的发现来源
+const (
+	MigrationFromEvent = "event" // pumpportal 迁移事件
+	MigrationFromTrade = "trade" // 交易推送中的池子不再是联合曲线
+	MigrationFromCurve = "curve" // 联合曲线 complete 标志(交易返回 BondingCurveComplete)
+)
+
+// Migration 代币迁移出联合曲线的记录
+type Migration struct {
+	Pool       common.PoolType // 事件中给出的目标池子，未知时为空
+	Source     string          // 发现来源
+	DetectedAt time.Time
+}
+
+var (
+	migrations     = make(map[string]Migration)
+	migrationMutex sync.RWMutex
+)
+
+// MarkMigrated 记录代币已迁移，首次记录时返回 true
+func MarkMigrated(mint string, pool common.PoolType, source string) bool {
+	migrationMutex.Lock()
+	defer migrationMutex.Unlock()
+	if _, exists := migrations[mint]; exists {
+		return false
+	}
+	migrations[mint] = Migration{Pool: pool, Source: source, DetectedAt: time.Now()}
+	// 联合曲线已不再交易，快照作废
+	ForgetSnapshot(mint)
+	return true
+}
+
+// Migrated 查询代币是否已迁移
+func Migrated(mint string) (Migration, bool) {
+	migrationMutex.RLock()
+	defer migrationMutex.RUnlock()
+	m, ok := migrations[mint]
+	return m, ok
+}
+
+// ForgetMigration 移除代币的迁移记录
+func ForgetMigration(mint string) {
+	migrationMutex.Lock()
+	defer migrationMutex.Unlock()
+	delete(migrations, mint)
+}
+
+// Route 返回代币下单应使用的池子: 已迁移的代币原本走联合曲线的订单改走 migratedPool
+func Route(mint string, pool common.PoolType, migratedPool common.PoolType) common.PoolType {
+	if pool != common.PUMP {
+		return pool
+	}
+	if _, ok := Migrated(mint); ok {
+		return migratedPool
+	}
+	return pool
+}
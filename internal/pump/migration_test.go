@@ -0,0 +1,46 @@
+package pump
+
+import (
+	"pump_auto/internal/common"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	const mint = "route_test_mint"
+	defer ForgetMigration(mint)
+
+	if got := Route(mint, common.PUMP, common.PUMP_AMM); got != common.PUMP {
+		t.Errorf("迁移前 Route() = %s, want %s", got, common.PUMP)
+	}
+	if !MarkMigrated(mint, common.PUMP_AMM, MigrationFromEvent) {
+		t.Fatal("首次 MarkMigrated() 应返回 true")
+	}
+	if MarkMigrated(mint, "", MigrationFromCurve) {
+		t.Error("重复 MarkMigrated() 应返回 false")
+	}
+	if m, ok := Migrated(mint); !ok || m.Source != MigrationFromEvent || m.Pool != common.PUMP_AMM {
+		t.Errorf("Migrated() = %+v, %v", m, ok)
+	}
+
+	tests := []struct {
+		name string
+		pool common.PoolType
+		want common.PoolType
+	}{
+		{name: "联合曲线改走迁移后的池子", pool: common.PUMP, want: common.PUMP_AMM},
+		{name: "已指定其他池子时保持不变", pool: common.RAYDIUM, want: common.RAYDIUM},
+		{name: "auto 由 pumpportal 自行选择", pool: common.AUTO, want: common.AUTO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Route(mint, tt.pool, common.PUMP_AMM); got != tt.want {
+				t.Errorf("Route() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	ForgetMigration(mint)
+	if got := Route(mint, common.PUMP, common.PUMP_AMM); got != common.PUMP {
+		t.Errorf("移除记录后 Route() = %s, want %s", got, common.PUMP)
+	}
+}
@@ -0,0 +1,65 @@
+package pump
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// pump.fun 程序相关的固定地址
+var (
+	ProgramID      = solana.MustPublicKeyFromBase58("6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P")
+	GlobalAccount  = solana.MustPublicKeyFromBase58("4wTV1YmiEkRvAtNtsSGPtUrqRYQMe5SKy2uB4Jjaxnjf")
+	FeeRecipient   = solana.MustPublicKeyFromBase58("CebN5WGQ4jvEPvsVU4EoHEpgzq1VV7AbicfhtW4xC9iM")
+	EventAuthority = solana.MustPublicKeyFromBase58("Ce6TQqeHC9p8KetsN6JsjHK7UTZk7nasjjnr7XxXp9F1")
+)
+
+// 代币精度，pump.fun 发行的代币固定为6位
+const TokenDecimals = 6
+
+// PDA 种子
+var (
+	seedGlobal         = []byte("global")
+	seedBondingCurve   = []byte("bonding-curve")
+	seedCreatorVault   = []byte("creator-vault")
+	seedEventAuthority = []byte("__event_authority")
+)
+
+// DeriveGlobal 推导全局配置账户
+func DeriveGlobal() (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress([][]byte{seedGlobal}, ProgramID)
+	return addr, err
+}
+
+// DeriveEventAuthority 推导事件授权账户
+func DeriveEventAuthority() (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress([][]byte{seedEventAuthority}, ProgramID)
+	return addr, err
+}
+
+// DeriveBondingCurve 推导代币的联合曲线账户
+func DeriveBondingCurve(mint solana.PublicKey) (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress([][]byte{seedBondingCurve, mint.Bytes()}, ProgramID)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("推导联合曲线账户失败: %w", err)
+	}
+	return addr, nil
+}
+
+// DeriveAssociatedBondingCurve 推导联合曲线持有代币的关联账户
+func DeriveAssociatedBondingCurve(bondingCurve solana.PublicKey, mint solana.PublicKey) (solana.PublicKey, error) {
+	addr, _, err := solana.FindAssociatedTokenAddress(bondingCurve, mint)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("推导联合曲线代币账户失败: %w", err)
+	}
+	return addr, nil
+}
+
+// DeriveCreatorVault 推导代币创建者的手续费金库
+func DeriveCreatorVault(creator solana.PublicKey) (solana.PublicKey, error) {
+	addr, _, err := solana.FindProgramAddress([][]byte{seedCreatorVault, creator.Bytes()}, ProgramID)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("推导创建者金库失败: %w", err)
+	}
+	return addr, nil
+}
@@ -0,0 +1,147 @@
+package pump
+
+import (
+	"pump_auto/internal/common"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+)
+
+func TestDerivedAddresses(t *testing.T) {
+	global, err := DeriveGlobal()
+	if err != nil {
+		t.Fatalf("DeriveGlobal() error = %v", err)
+	}
+	if !global.Equals(GlobalAccount) {
+		t.Errorf("DeriveGlobal() = %s, want %s", global, GlobalAccount)
+	}
+
+	authority, err := DeriveEventAuthority()
+	if err != nil {
+		t.Fatalf("DeriveEventAuthority() error = %v", err)
+	}
+	if !authority.Equals(EventAuthority) {
+		t.Errorf("DeriveEventAuthority() = %s, want %s", authority, EventAuthority)
+	}
+}
+
+func TestTradeDataRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantAction common.TradeAction
+		wantAmount uint64
+		wantLimit  uint64
+		wantErr    bool
+	}{
+		{
+			name:       "买入",
+			data:       encodeTradeData(BuyDiscriminator, 1_000_000, 2_000_000),
+			wantAction: common.BUY,
+			wantAmount: 1_000_000,
+			wantLimit:  2_000_000,
+		},
+		{
+			name:       "卖出",
+			data:       encodeTradeData(SellDiscriminator, 5, 7),
+			wantAction: common.SELL,
+			wantAmount: 5,
+			wantLimit:  7,
+		},
+		{
+			name:    "未知鉴别码",
+			data:    encodeTradeData([]byte{1, 2, 3, 4, 5, 6, 7, 8}, 1, 1),
+			wantErr: true,
+		},
+		{
+			name:    "数据过短",
+			data:    BuyDiscriminator,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, amount, limit, err := DecodeTradeData(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeTradeData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if action != tt.wantAction || amount != tt.wantAmount || limit != tt.wantLimit {
+				t.Errorf("DecodeTradeData() = (%s, %d, %d), want (%s, %d, %d)",
+					action, amount, limit, tt.wantAction, tt.wantAmount, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestBuildTransactions(t *testing.T) {
+	user, _ := solana.NewRandomPrivateKey()
+	creator, _ := solana.NewRandomPrivateKey()
+	params := TradeParams{
+		Mint:             solana.MustPublicKeyFromBase58("7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump"),
+		Creator:          creator.PublicKey(),
+		User:             user.PublicKey(),
+		TokenAmount:      123_456,
+		SolLimit:         1_100_000,
+		ComputeUnitLimit: 100_000,
+		ComputeUnitPrice: PriorityFeeToMicroLamports(0.0005, 100_000),
+	}
+
+	t.Run("买入交易", func(t *testing.T) {
+		tx, err := BuildBuyTransaction(params, solana.Hash{})
+		if err != nil {
+			t.Fatalf("BuildBuyTransaction() error = %v", err)
+		}
+		wantPrograms := []solana.PublicKey{
+			computebudget.ProgramID,
+			computebudget.ProgramID,
+			solana.SPLAssociatedTokenAccountProgramID,
+			ProgramID,
+		}
+		assertPrograms(t, tx, wantPrograms)
+
+		buy := tx.Message.Instructions[3]
+		action, amount, limit, err := DecodeTradeData(buy.Data)
+		if err != nil || action != common.BUY || amount != params.TokenAmount || limit != params.SolLimit {
+			t.Errorf("买入指令数据错误: action=%s amount=%d limit=%d err=%v", action, amount, limit, err)
+		}
+		accounts, _ := NewBuyAccounts(params.Mint, params.Creator, params.User)
+		if got := tx.Message.AccountKeys[buy.Accounts[9]]; !got.Equals(accounts.CreatorVault) {
+			t.Errorf("买入指令第10个账户应为 creator_vault %s，实际为 %s", accounts.CreatorVault, got)
+		}
+		if !tx.Message.AccountKeys[0].Equals(params.User) {
+			t.Errorf("交易付款人应为用户")
+		}
+	})
+
+	t.Run("卖出交易", func(t *testing.T) {
+		tx, err := BuildSellTransaction(params, solana.Hash{})
+		if err != nil {
+			t.Fatalf("BuildSellTransaction() error = %v", err)
+		}
+		assertPrograms(t, tx, []solana.PublicKey{computebudget.ProgramID, computebudget.ProgramID, ProgramID})
+
+		sell := tx.Message.Instructions[2]
+		accounts, _ := NewSellAccounts(params.Mint, params.Creator, params.User)
+		if got := tx.Message.AccountKeys[sell.Accounts[8]]; !got.Equals(accounts.CreatorVault) {
+			t.Errorf("卖出指令第9个账户应为 creator_vault %s，实际为 %s", accounts.CreatorVault, got)
+		}
+	})
+}
+
+func assertPrograms(t *testing.T, tx *solana.Transaction, want []solana.PublicKey) {
+	t.Helper()
+	if len(tx.Message.Instructions) != len(want) {
+		t.Fatalf("指令数量 = %d, want %d", len(tx.Message.Instructions), len(want))
+	}
+	for i, inst := range tx.Message.Instructions {
+		got := tx.Message.AccountKeys[inst.ProgramIDIndex]
+		if !got.Equals(want[i]) {
+			t.Errorf("第 %d 条指令程序 = %s, want %s", i, got, want[i])
+		}
+	}
+}
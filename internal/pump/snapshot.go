@@ -0,0 +1,101 @@
+package pump
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// 超过该时长未更新的快照会在清理时被移除
+const snapshotTTL = 10 * time.Minute
+
+// 快照数量超过该值时触发清理
+const snapshotPruneThreshold = 2000
+
+// CurveSnapshot 联合曲线的最新已知状态，来自 pumpportal 推送的创建/交易事件
+type CurveSnapshot struct {
+	Creator              solana.PublicKey
+	VirtualSolReserves   uint64 // lamports
+	VirtualTokenReserves uint64 // 代币最小单位
+	UpdatedAt            time.Time
+}
+
+var (
+	snapshots     = make(map[string]*CurveSnapshot)
+	snapshotMutex sync.RWMutex
+)
+
+// ObserveCreate 记录代币创建事件中的创建者和初始储备
+func ObserveCreate(mint string, creator string, vSolInBondingCurve float64, vTokensInBondingCurve float64) error {
+	creatorKey, err := solana.PublicKeyFromBase58(creator)
+	if err != nil {
+		return fmt.Errorf("无效的创建者地址: %w", err)
+	}
+
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	if len(snapshots) >= snapshotPruneThreshold {
+		pruneLocked()
+	}
+	snapshots[mint] = &CurveSnapshot{
+		Creator:              creatorKey,
+		VirtualSolReserves:   solToLamports(vSolInBondingCurve),
+		VirtualTokenReserves: uiToRaw(vTokensInBondingCurve),
+		UpdatedAt:            time.Now(),
+	}
+	return nil
+}
+
+// ObserveReserves 使用交易事件中的虚拟储备更新快照，未记录创建者的代币会被忽略
+func ObserveReserves(mint string, vSolInBondingCurve float64, vTokensInBondingCurve float64) {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	snap, exists := snapshots[mint]
+	if !exists {
+		return
+	}
+	snap.VirtualSolReserves = solToLamports(vSolInBondingCurve)
+	snap.VirtualTokenReserves = uiToRaw(vTokensInBondingCurve)
+	snap.UpdatedAt = time.Now()
+}
+
+// GetSnapshot 返回代币的最新快照副本
+func GetSnapshot(mint string) (CurveSnapshot, bool) {
+	snapshotMutex.RLock()
+	defer snapshotMutex.RUnlock()
+
+	snap, exists := snapshots[mint]
+	if !exists {
+		return CurveSnapshot{}, false
+	}
+	return *snap, true
+}
+
+// ForgetSnapshot 移除代币快照
+func ForgetSnapshot(mint string) {
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+	delete(snapshots, mint)
+}
+
+func pruneLocked() {
+	deadline := time.Now().Add(-snapshotTTL)
+	for mint, snap := range snapshots {
+		if snap.UpdatedAt.Before(deadline) {
+			delete(snapshots, mint)
+		}
+	}
+}
+
+func solToLamports(sol float64) uint64 {
+	return uint64(math.Round(sol * float64(solana.LAMPORTS_PER_SOL)))
+}
+
+func uiToRaw(amount float64) uint64 {
+	return uint64(math.Round(amount * math.Pow10(TokenDecimals)))
+}
@@ -0,0 +1,115 @@
+package pump
+
+import (
+	"fmt"
+	"pump_auto/internal/common"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+)
+
+// DefaultComputeUnitLimit 买入/卖出交易默认的计算单元上限
+const DefaultComputeUnitLimit uint32 = 120_000
+
+// 关联代币账户程序的 CreateIdempotent 指令编号
+const ataCreateIdempotent byte = 1
+
+// TradeParams 构造 pump.fun 交易所需的参数
+type TradeParams struct {
+	Mint             solana.PublicKey
+	Creator          solana.PublicKey // 代币创建者，用于推导 creator_vault
+	User             solana.PublicKey
+	TokenAmount      uint64 // 代币数量(最小单位)
+	SolLimit         uint64 // 买入时为最大花费，卖出时为最少获得 (lamports)
+	ComputeUnitLimit uint32
+	ComputeUnitPrice uint64 // 每个计算单元的价格 (micro-lamports)
+}
+
+// PriorityFeeToMicroLamports 将以SOL计的总优先费换算为计算单元价格
+func PriorityFeeToMicroLamports(priorityFeeSol float64, computeUnitLimit uint32) uint64 {
+	if priorityFeeSol <= 0 || computeUnitLimit == 0 {
+		return 0
+	}
+	lamports := priorityFeeSol * float64(solana.LAMPORTS_PER_SOL)
+	return uint64(lamports * 1_000_000 / float64(computeUnitLimit))
+}
+
+// BuildBuyTransaction 组装买入交易: 计算预算 + 幂等创建ATA + pump买入
+func BuildBuyTransaction(p TradeParams, blockhash solana.Hash) (*solana.Transaction, error) {
+	accounts, err := NewBuyAccounts(p.Mint, p.Creator, p.User)
+	if err != nil {
+		return nil, err
+	}
+	accounts.Input = &common.BuyInstruction{
+		Amount:     p.TokenAmount,
+		MaxSolCost: p.SolLimit,
+	}
+	buy, err := BuildBuyInstruction(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := computeBudgetInstructions(p)
+	instructions = append(instructions,
+		newCreateIdempotentATAInstruction(p.User, accounts.AssociatedUser, p.User, p.Mint),
+		buy,
+	)
+	return newTransaction(instructions, blockhash, p.User)
+}
+
+// BuildSellTransaction 组装卖出交易: 计算预算 + pump卖出
+func BuildSellTransaction(p TradeParams, blockhash solana.Hash) (*solana.Transaction, error) {
+	accounts, err := NewSellAccounts(p.Mint, p.Creator, p.User)
+	if err != nil {
+		return nil, err
+	}
+	accounts.Input = &common.SellInstruction{
+		Amount:       p.TokenAmount,
+		MinSolOutput: p.SolLimit,
+	}
+	sell, err := BuildSellInstruction(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := append(computeBudgetInstructions(p), sell)
+	return newTransaction(instructions, blockhash, p.User)
+}
+
+func computeBudgetInstructions(p TradeParams) []solana.Instruction {
+	limit := p.ComputeUnitLimit
+	if limit == 0 {
+		limit = DefaultComputeUnitLimit
+	}
+	instructions := []solana.Instruction{
+		computebudget.NewSetComputeUnitLimitInstruction(limit).Build(),
+	}
+	if p.ComputeUnitPrice > 0 {
+		instructions = append(instructions, computebudget.NewSetComputeUnitPriceInstruction(p.ComputeUnitPrice).Build())
+	}
+	return instructions
+}
+
+// newCreateIdempotentATAInstruction 账户已存在时不会失败，避免重复买入时报错
+func newCreateIdempotentATAInstruction(payer, ata, owner, mint solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(
+		solana.SPLAssociatedTokenAccountProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(ata).WRITE(),
+			solana.Meta(owner),
+			solana.Meta(mint),
+			solana.Meta(solana.SystemProgramID),
+			solana.Meta(solana.TokenProgramID),
+		},
+		[]byte{ataCreateIdempotent},
+	)
+}
+
+func newTransaction(instructions []solana.Instruction, blockhash solana.Hash, payer solana.PublicKey) (*solana.Transaction, error) {
+	tx, err := solana.NewTransaction(instructions, blockhash, solana.TransactionPayer(payer))
+	if err != nil {
+		return nil, fmt.Errorf("组装交易失败: %w", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,149 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// BlockhashSource 区块哈希缓存依赖的RPC方法，*Client 实现了该接口
+type BlockhashSource interface {
+	GetLatestBlockhash() (*rpc.GetLatestBlockhashResult, error)
+	GetBlockHeight(commitment rpc.CommitmentType) (uint64, error)
+}
+
+const (
+	DefaultBlockhashInterval = 2 * time.Second
+	maxBlockhashAge          = 10 * time.Second // 超过该时长未刷新的缓存不再使用
+)
+
+// Blockhash 一个已确认的区块哈希及其有效期
+type Blockhash struct {
+	Hash                 solana.Hash
+	LastValidBlockHeight uint64
+	Slot                 uint64
+	FetchedAt            time.Time
+}
+
+// BlockhashCache 在后台定时刷新最新的 confirmed 区块哈希和区块高度
+// 签名时直接读取缓存，不需要等待网络；区块高度同时供确认服务判断交易是否过期
+type BlockhashCache struct {
+	source   BlockhashSource
+	interval time.Duration
+
+	mu       sync.RWMutex
+	latest   Blockhash
+	height   uint64
+	heightAt time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewBlockhashCache 创建区块哈希缓存并启动刷新，interval 为0时使用默认间隔
+func NewBlockhashCache(source BlockhashSource, interval time.Duration) *BlockhashCache {
+	if interval <= 0 {
+		interval = DefaultBlockhashInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &BlockhashCache{
+		source:   source,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	go c.run()
+	return c
+}
+
+// Close 停止刷新
+func (c *BlockhashCache) Close() {
+	c.cancel()
+}
+
+func (c *BlockhashCache) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Refresh(); err != nil {
+			log.Printf("刷新区块哈希失败: %v", err)
+		}
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 立即获取最新的区块哈希和区块高度
+func (c *BlockhashCache) Refresh() error {
+	out, err := c.source.GetLatestBlockhash()
+	if err != nil {
+		return fmt.Errorf("获取区块哈希失败: %w", err)
+	}
+	if out == nil || out.Value == nil {
+		return errors.New("获取区块哈希失败: 节点没有返回结果")
+	}
+	now := time.Now()
+	c.mu.Lock()
+	c.latest = Blockhash{
+		Hash:                 out.Value.Blockhash,
+		LastValidBlockHeight: out.Value.LastValidBlockHeight,
+		Slot:                 out.Context.Slot,
+		FetchedAt:            now,
+	}
+	c.mu.Unlock()
+
+	height, err := c.source.GetBlockHeight(rpc.CommitmentConfirmed)
+	if err != nil {
+		return fmt.Errorf("获取区块高度失败: %w", err)
+	}
+	c.mu.Lock()
+	c.height, c.heightAt = height, time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// Latest 返回缓存的区块哈希，不访问网络；缓存过旧或已接近失效时返回 false
+func (c *BlockhashCache) Latest() (Blockhash, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.latest.FetchedAt.IsZero() || time.Since(c.latest.FetchedAt) > maxBlockhashAge {
+		return Blockhash{}, false
+	}
+	if c.height != 0 && c.height >= c.latest.LastValidBlockHeight {
+		return Blockhash{}, false
+	}
+	return c.latest, true
+}
+
+// Get 返回缓存的区块哈希，缓存不可用(如刚启动或刷新持续失败)时同步获取一次
+func (c *BlockhashCache) Get() (Blockhash, error) {
+	if latest, ok := c.Latest(); ok {
+		return latest, nil
+	}
+	if err := c.Refresh(); err != nil {
+		return Blockhash{}, err
+	}
+	if latest, ok := c.Latest(); ok {
+		return latest, nil
+	}
+	return Blockhash{}, errors.New("获取到的区块哈希已失效")
+}
+
+// BlockHeight 返回缓存的 confirmed 区块高度，缓存过旧时返回 false
+func (c *BlockhashCache) BlockHeight() (uint64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.heightAt.IsZero() || time.Since(c.heightAt) > maxBlockhashAge {
+		return 0, false
+	}
+	return c.height, true
+}
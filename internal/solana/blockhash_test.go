@@ -0,0 +1,108 @@
+package solana
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// fakeBlockhashSource 每次调用返回递增的区块哈希
+type fakeBlockhashSource struct {
+	mu        sync.Mutex
+	calls     int
+	height    uint64
+	lastValid uint64
+}
+
+func (f *fakeBlockhashSource) GetLatestBlockhash() (*rpc.GetLatestBlockhashResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	out := &rpc.GetLatestBlockhashResult{Value: &rpc.LatestBlockhashResult{Blockhash: solana.Hash{byte(f.calls)}, LastValidBlockHeight: f.lastValid}}
+	out.Context.Slot = uint64(f.calls)
+	return out, nil
+}
+
+func (f *fakeBlockhashSource) GetBlockHeight(rpc.CommitmentType) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.height, nil
+}
+
+func (f *fakeBlockhashSource) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestBlockhashCache(t *testing.T) {
+	t.Run("签名时读取缓存", func(t *testing.T) {
+		source := &fakeBlockhashSource{height: 100, lastValid: 250}
+		cache := NewBlockhashCache(source, time.Hour)
+		defer cache.Close()
+		// 等待启动时的第一次刷新
+		deadline := time.Now().Add(2 * time.Second)
+		for _, ok := cache.BlockHeight(); !ok && time.Now().Before(deadline); _, ok = cache.BlockHeight() {
+			time.Sleep(time.Millisecond)
+		}
+		calls := source.count()
+		for i := 0; i < 3; i++ {
+			got, err := cache.Get()
+			if err != nil || got.LastValidBlockHeight != 250 {
+				t.Fatalf("Get() = %+v, %v", got, err)
+			}
+		}
+		if source.count() != calls {
+			t.Errorf("缓存有效时不应访问网络，调用次数 %d -> %d", calls, source.count())
+		}
+		if height, ok := cache.BlockHeight(); !ok || height != 100 {
+			t.Errorf("BlockHeight() = %d, %v", height, ok)
+		}
+	})
+
+	t.Run("定时刷新", func(t *testing.T) {
+		source := &fakeBlockhashSource{height: 100, lastValid: 250}
+		cache := NewBlockhashCache(source, 5*time.Millisecond)
+		defer cache.Close()
+		deadline := time.Now().Add(2 * time.Second)
+		for source.count() < 3 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if source.count() < 3 {
+			t.Fatalf("后台应定时刷新，实际调用 %d 次", source.count())
+		}
+	})
+
+	t.Run("区块哈希即将失效时重新获取", func(t *testing.T) {
+		source := &fakeBlockhashSource{height: 300, lastValid: 250}
+		cache := NewBlockhashCache(source, time.Hour)
+		defer cache.Close()
+		_ = cache.Refresh()
+		if _, ok := cache.Latest(); ok {
+			t.Fatal("区块高度已超过有效期，Latest() 不应返回缓存")
+		}
+		if _, err := cache.Get(); err == nil {
+			t.Fatal("节点返回的区块哈希仍然失效时 Get() 应返回错误")
+		}
+	})
+
+	t.Run("确认服务使用缓存的区块高度", func(t *testing.T) {
+		blockhashes := &fakeBlockhashSource{height: 1_000, lastValid: 2_000}
+		cache := NewBlockhashCache(blockhashes, time.Hour)
+		defer cache.Close()
+		_ = cache.Refresh()
+		// 状态源的区块高度未过期，缓存高度已超过交易有效期
+		statuses := &fakeSource{height: 10, statuses: map[solana.Signature]*rpc.SignatureStatusesResult{}}
+		c := newConfirmer(statuses, cache, "", rpc.CommitmentConfirmed, 5*time.Millisecond)
+		defer c.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if got, _ := c.Track(solana.Signature{9}, 999).Wait(ctx); got.Status != StatusExpired {
+			t.Errorf("Status = %s, want expired", got.Status)
+		}
+	})
+}
@@ -7,47 +7,110 @@ import (
 	"github.com/gagliardetto/solana-go/rpc"
 )
 
-// Client 包装Solana客户端功能
+// Client 包装Solana客户端功能，内部维护一组RPC节点
 type Client struct {
-	rpcClient *rpc.Client
+	endpoints []*endpoint
 	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
 // New 创建新的Solana客户端
 func New(endpoint string, ctx context.Context) *Client {
-	client := rpc.New(endpoint)
-	return &Client{
-		rpcClient: client,
-		ctx:       ctx,
-	}
+	return NewPool(ctx, Endpoint{URL: endpoint})
 }
 
 // Close 关闭客户端连接
 func (c *Client) Close() error {
-	return c.rpcClient.Close()
+	c.cancel()
+	var firstErr error
+	for _, e := range c.endpoints {
+		if err := e.rpc.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
 // GetLatestBlockhash 获取最新的区块哈希
-func (c *Client) GetLatestBlockhash() (*rpc.GetLatestBlockhashResult, error) {
-	return c.rpcClient.GetLatestBlockhash(c.ctx, rpc.CommitmentConfirmed)
+func (c *Client) GetLatestBlockhash() (out *rpc.GetLatestBlockhashResult, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+		return err
+	})
+	return out, err
 }
 
 // GetTokenAccountBalance 获取代币账户余额
-func (c *Client) GetTokenAccountBalance(account solana.PublicKey) (*rpc.GetTokenAccountBalanceResult, error) {
-	return c.rpcClient.GetTokenAccountBalance(c.ctx, account, rpc.CommitmentConfirmed)
+func (c *Client) GetTokenAccountBalance(account solana.PublicKey) (out *rpc.GetTokenAccountBalanceResult, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.GetTokenAccountBalance(ctx, account, rpc.CommitmentConfirmed)
+		return err
+	})
+	return out, err
 }
 
 // SimulateTransaction 模拟交易
-func (c *Client) SimulateTransaction(tx *solana.Transaction) (*rpc.SimulateTransactionResponse, error) {
-	return c.rpcClient.SimulateTransaction(c.ctx, tx)
+func (c *Client) SimulateTransaction(tx *solana.Transaction) (out *rpc.SimulateTransactionResponse, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.SimulateTransaction(ctx, tx)
+		return err
+	})
+	return out, err
+}
+
+// SimulateTransactionWithAccounts 模拟交易并返回指定账户执行后的状态，不校验签名
+func (c *Client) SimulateTransactionWithAccounts(tx *solana.Transaction, accounts []solana.PublicKey) (out *rpc.SimulateTransactionResponse, err error) {
+	opts := &rpc.SimulateTransactionOpts{Commitment: rpc.CommitmentConfirmed}
+	if len(accounts) > 0 {
+		opts.Accounts = &rpc.SimulateTransactionAccountsOpts{Encoding: solana.EncodingBase64, Addresses: accounts}
+	}
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.SimulateTransactionWithOpts(ctx, tx, opts)
+		return err
+	})
+	return out, err
+}
+
+// GetMultipleAccounts 批量获取账户，不存在的账户为 nil
+func (c *Client) GetMultipleAccounts(accounts ...solana.PublicKey) (out *rpc.GetMultipleAccountsResult, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.GetMultipleAccountsWithOpts(ctx, accounts, &rpc.GetMultipleAccountsOpts{
+			Encoding:   solana.EncodingBase64,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		return err
+	})
+	return out, err
+}
+
+// GetRecentPrioritizationFees 获取近期写锁这些账户的交易所付的优先费单价
+func (c *Client) GetRecentPrioritizationFees(accounts []solana.PublicKey) (out []rpc.PriorizationFeeResult, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.GetRecentPrioritizationFees(ctx, accounts)
+		return err
+	})
+	return out, err
 }
 
-// SendTransaction 发送交易
+// SendTransaction 发送交易，广播到所有节点
 func (c *Client) SendTransaction(tx *solana.Transaction) (solana.Signature, error) {
-	return c.rpcClient.SendTransaction(c.ctx, tx)
+	return c.broadcast(tx)
 }
 
 // GetSignatureStatuses 获取交易签名状态
-// func (c *Client) GetSignatureStatuses(signatures []solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
-// 	return c.rpcClient.GetSignatureStatuses(c.ctx, signatures, nil)
-// }
+func (c *Client) GetSignatureStatuses(searchHistory bool, signatures ...solana.Signature) (out *rpc.GetSignatureStatusesResult, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		out, err = client.GetSignatureStatuses(ctx, searchHistory, signatures...)
+		return err
+	})
+	return out, err
+}
+
+// GetBlockHeight 获取当前区块高度，用于判断区块哈希是否过期
+func (c *Client) GetBlockHeight(commitment rpc.CommitmentType) (height uint64, err error) {
+	err = c.Do(func(ctx context.Context, client *rpc.Client) error {
+		height, err = client.GetBlockHeight(ctx, commitment)
+		return err
+	})
+	return height, err
+}
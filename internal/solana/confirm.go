@@ -0,0 +1,398 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// 确认失败的原因，可通过 errors.Is 判断
+var (
+	ErrTransactionFailed = errors.New("交易已上链但执行失败")
+	ErrBlockhashExpired  = errors.New("区块哈希已过期，交易未上链")
+)
+
+// ConfirmStatus 交易确认状态
+type ConfirmStatus int
+
+const (
+	StatusPending ConfirmStatus = iota // 等待中
+	StatusLanded                       // 已上链且执行成功
+	StatusFailed                       // 已上链但执行失败
+	StatusExpired                      // 区块哈希过期仍未上链，不会再上链
+)
+
+func (s ConfirmStatus) String() string {
+	switch s {
+	case StatusLanded:
+		return "landed"
+	case StatusFailed:
+		return "failed"
+	case StatusExpired:
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+// Confirmation 一笔交易的确认结果
+type Confirmation struct {
+	Signature solana.Signature
+	Status    ConfirmStatus
+	Slot      uint64
+	Err       interface{}   // 链上执行错误，只在 StatusFailed 时有值
+	Latency   time.Duration // 从开始跟踪到得到结果的耗时
+	Source    string        // 结果来源: ws 订阅或 rpc 轮询
+}
+
+// Error 将失败和过期转换为错误，成功时返回 nil
+func (c Confirmation) Error() error {
+	switch c.Status {
+	case StatusFailed:
+		return fmt.Errorf("%w: %s %v", ErrTransactionFailed, c.Signature, c.Err)
+	case StatusExpired:
+		return fmt.Errorf("%w: %s", ErrBlockhashExpired, c.Signature)
+	default:
+		return nil
+	}
+}
+
+// Pending 一个被跟踪签名的确认结果，Done 关闭后 Result 可用
+type Pending struct {
+	Signature            solana.Signature
+	LastValidBlockHeight uint64 // 为0时按 maxPendingAge 判断过期
+
+	started    time.Time
+	done       chan struct{}
+	once       sync.Once
+	result     Confirmation
+	resolvedAt time.Time
+}
+
+// Done 结果确定后关闭
+func (p *Pending) Done() <-chan struct{} {
+	return p.done
+}
+
+// Result 返回确认结果，Done 关闭前状态为 StatusPending
+func (p *Pending) Result() Confirmation {
+	select {
+	case <-p.done:
+		return p.result
+	default:
+		return Confirmation{Signature: p.Signature, Status: StatusPending}
+	}
+}
+
+// Wait 等待确认结果，执行失败或过期时同时返回对应错误
+func (p *Pending) Wait(ctx context.Context) (Confirmation, error) {
+	select {
+	case <-p.done:
+		return p.result, p.result.Error()
+	case <-ctx.Done():
+		return Confirmation{Signature: p.Signature, Status: StatusPending}, fmt.Errorf("等待交易 %s 确认超时: %w", p.Signature, ctx.Err())
+	}
+}
+
+func (p *Pending) resolve(c Confirmation) bool {
+	resolved := false
+	p.once.Do(func() {
+		c.Signature = p.Signature
+		c.Latency = time.Since(p.started)
+		p.result = c
+		close(p.done)
+		resolved = true
+	})
+	return resolved
+}
+
+// StatusSource 确认服务依赖的RPC方法，*Client 实现了该接口
+type StatusSource interface {
+	GetSignatureStatuses(searchHistory bool, signatures ...solana.Signature) (*rpc.GetSignatureStatusesResult, error)
+	GetBlockHeight(commitment rpc.CommitmentType) (uint64, error)
+}
+
+const (
+	defaultPollInterval = 500 * time.Millisecond
+	maxStatusBatch      = 256              // getSignatureStatuses 单次最多查询的签名数
+	maxPendingAge       = 90 * time.Second // 未提供区块高度时的过期时间
+	resolvedRetention   = 5 * time.Minute  // 已确定的结果保留时间，供 Lookup 查询
+)
+
+// Confirmer 跟踪已发送交易的确认状态
+// 优先使用 signatureSubscribe 推送，同时以 getSignatureStatuses 轮询兜底，
+// 区块高度超过交易区块哈希的 lastValidBlockHeight 仍未上链时判定为过期；有区块哈希缓存时区块高度从缓存读取
+type Confirmer struct {
+	source       StatusSource
+	blockhashes  *BlockhashCache
+	wsURL        string
+	commitment   rpc.CommitmentType
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	pending  map[solana.Signature]*Pending
+	resolved map[solana.Signature]*Pending
+	wsClient *ws.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewConfirmer 创建确认服务并启动轮询，wsURL 为空时只使用轮询，blockhashes 可以为 nil
+func NewConfirmer(source StatusSource, blockhashes *BlockhashCache, wsURL string, commitment rpc.CommitmentType) *Confirmer {
+	return newConfirmer(source, blockhashes, wsURL, commitment, defaultPollInterval)
+}
+
+func newConfirmer(source StatusSource, blockhashes *BlockhashCache, wsURL string, commitment rpc.CommitmentType, pollInterval time.Duration) *Confirmer {
+	if commitment == "" {
+		commitment = rpc.CommitmentConfirmed
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Confirmer{
+		source:       source,
+		blockhashes:  blockhashes,
+		wsURL:        wsURL,
+		commitment:   commitment,
+		pollInterval: pollInterval,
+		pending:      make(map[solana.Signature]*Pending),
+		resolved:     make(map[solana.Signature]*Pending),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	go c.pollLoop()
+	return c
+}
+
+// Close 停止轮询和订阅，未确定的签名保持 StatusPending
+func (c *Confirmer) Close() {
+	c.cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wsClient != nil {
+		c.wsClient.Close()
+		c.wsClient = nil
+	}
+}
+
+// Track 开始跟踪一个签名，重复跟踪返回同一个 Pending
+func (c *Confirmer) Track(signature solana.Signature, lastValidBlockHeight uint64) *Pending {
+	c.mu.Lock()
+	if p, ok := c.pending[signature]; ok {
+		c.mu.Unlock()
+		return p
+	}
+	if p, ok := c.resolved[signature]; ok {
+		c.mu.Unlock()
+		return p
+	}
+	p := &Pending{
+		Signature:            signature,
+		LastValidBlockHeight: lastValidBlockHeight,
+		started:              time.Now(),
+		done:                 make(chan struct{}),
+	}
+	c.pending[signature] = p
+	c.mu.Unlock()
+
+	if c.wsURL != "" {
+		go c.subscribe(p)
+	}
+	return p
+}
+
+// Lookup 查询正在跟踪或近期已确定的签名
+func (c *Confirmer) Lookup(signature solana.Signature) (*Pending, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.pending[signature]; ok {
+		return p, true
+	}
+	p, ok := c.resolved[signature]
+	return p, ok
+}
+
+func (c *Confirmer) finish(p *Pending, result Confirmation) {
+	if !p.resolve(result) {
+		return
+	}
+	c.mu.Lock()
+	p.resolvedAt = time.Now()
+	delete(c.pending, p.Signature)
+	c.resolved[p.Signature] = p
+	c.mu.Unlock()
+	log.Printf("交易 %s 确认结果: %s (来源=%s 耗时=%v)", p.Signature, p.result.Status, p.result.Source, p.result.Latency)
+}
+
+// subscribe 通过 signatureSubscribe 等待结果，失败时静默交给轮询处理
+func (c *Confirmer) subscribe(p *Pending) {
+	client, err := c.websocket()
+	if err != nil {
+		log.Printf("连接签名订阅失败，使用轮询确认: %v", err)
+		return
+	}
+	sub, err := client.SignatureSubscribe(p.Signature, c.commitment)
+	if err != nil {
+		log.Printf("订阅交易 %s 失败，使用轮询确认: %v", p.Signature, err)
+		c.dropWebsocket(client)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	res, err := sub.Recv(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("交易 %s 订阅中断，使用轮询确认: %v", p.Signature, err)
+			c.dropWebsocket(client)
+		}
+		return
+	}
+	result := Confirmation{Status: StatusLanded, Slot: res.Context.Slot, Source: "ws"}
+	if res.Value.Err != nil {
+		result.Status = StatusFailed
+		result.Err = res.Value.Err
+	}
+	c.finish(p, result)
+}
+
+func (c *Confirmer) websocket() (*ws.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wsClient != nil {
+		return c.wsClient, nil
+	}
+	client, err := ws.Connect(c.ctx, c.wsURL)
+	if err != nil {
+		return nil, err
+	}
+	c.wsClient = client
+	return client, nil
+}
+
+// dropWebsocket 丢弃出错的连接，下次订阅时重连
+func (c *Confirmer) dropWebsocket(client *ws.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wsClient == client {
+		c.wsClient.Close()
+		c.wsClient = nil
+	}
+}
+
+func (c *Confirmer) pollLoop() {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.poll()
+		}
+	}
+}
+
+// poll 查询所有未确定签名的状态
+// 先取区块高度再查状态: 高度已超过 lastValidBlockHeight 而状态仍为空，说明交易不可能再上链
+func (c *Confirmer) poll() {
+	c.mu.Lock()
+	for sig, p := range c.resolved {
+		if time.Since(p.resolvedAt) > resolvedRetention {
+			delete(c.resolved, sig)
+		}
+	}
+	pending := make([]*Pending, 0, len(c.pending))
+	for _, p := range c.pending {
+		pending = append(pending, p)
+	}
+	c.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	height := c.blockHeight()
+
+	for start := 0; start < len(pending); start += maxStatusBatch {
+		end := start + maxStatusBatch
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+		signatures := make([]solana.Signature, len(batch))
+		for i, p := range batch {
+			signatures[i] = p.Signature
+		}
+		out, err := c.source.GetSignatureStatuses(false, signatures...)
+		if err != nil {
+			log.Printf("查询交易状态失败: %v", err)
+			continue
+		}
+		for i, p := range batch {
+			var status *rpc.SignatureStatusesResult
+			if i < len(out.Value) {
+				status = out.Value[i]
+			}
+			if status != nil && reached(status.ConfirmationStatus, c.commitment) {
+				result := Confirmation{Status: StatusLanded, Slot: status.Slot, Source: "rpc"}
+				if status.Err != nil {
+					result.Status = StatusFailed
+					result.Err = status.Err
+				}
+				c.finish(p, result)
+				continue
+			}
+			if status == nil && expired(p, height) {
+				c.finish(p, Confirmation{Status: StatusExpired, Source: "rpc"})
+			}
+		}
+	}
+}
+
+// blockHeight 优先读取区块哈希缓存中的高度，获取失败时返回0
+func (c *Confirmer) blockHeight() uint64 {
+	if c.blockhashes != nil {
+		if height, ok := c.blockhashes.BlockHeight(); ok {
+			return height
+		}
+	}
+	height, err := c.source.GetBlockHeight(c.commitment)
+	if err != nil {
+		log.Printf("获取区块高度失败: %v", err)
+		return 0
+	}
+	return height
+}
+
+// expired 判断未上链的交易是否已无法上链
+func expired(p *Pending, height uint64) bool {
+	if p.LastValidBlockHeight == 0 {
+		return time.Since(p.started) > maxPendingAge
+	}
+	return height > p.LastValidBlockHeight
+}
+
+// reached 判断交易的确认级别是否达到要求
+func reached(status rpc.ConfirmationStatusType, commitment rpc.CommitmentType) bool {
+	rank := map[string]int{
+		string(rpc.ConfirmationStatusProcessed): 1,
+		string(rpc.ConfirmationStatusConfirmed): 2,
+		string(rpc.ConfirmationStatusFinalized): 3,
+	}
+	return rank[string(status)] >= rank[string(commitment)] && rank[string(status)] > 0
+}
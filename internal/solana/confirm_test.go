@@ -0,0 +1,98 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// fakeSource 按签名返回预设状态的 StatusSource
+type fakeSource struct {
+	mu       sync.Mutex
+	height   uint64
+	statuses map[solana.Signature]*rpc.SignatureStatusesResult
+}
+
+func (f *fakeSource) GetSignatureStatuses(_ bool, signatures ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := &rpc.GetSignatureStatusesResult{}
+	for _, sig := range signatures {
+		out.Value = append(out.Value, f.statuses[sig])
+	}
+	return out, nil
+}
+
+func (f *fakeSource) GetBlockHeight(rpc.CommitmentType) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.height, nil
+}
+
+func TestConfirmer(t *testing.T) {
+	sig := func(b byte) solana.Signature { return solana.Signature{b} }
+	source := &fakeSource{
+		height: 1_000,
+		statuses: map[solana.Signature]*rpc.SignatureStatusesResult{
+			sig(1): {Slot: 7, ConfirmationStatus: rpc.ConfirmationStatusConfirmed},
+			sig(2): {Slot: 8, ConfirmationStatus: rpc.ConfirmationStatusFinalized, Err: map[string]interface{}{"InstructionError": []interface{}{3, "Custom"}}},
+			sig(4): {Slot: 9, ConfirmationStatus: rpc.ConfirmationStatusProcessed},
+		},
+	}
+	c := newConfirmer(source, nil, "", rpc.CommitmentConfirmed, 10*time.Millisecond)
+	defer c.Close()
+
+	tests := []struct {
+		name       string
+		sig        solana.Signature
+		lastValid  uint64
+		wantStatus ConfirmStatus
+		wantErr    error
+	}{
+		{name: "已上链", sig: sig(1), lastValid: 2_000, wantStatus: StatusLanded},
+		{name: "执行失败", sig: sig(2), lastValid: 2_000, wantStatus: StatusFailed, wantErr: ErrTransactionFailed},
+		{name: "区块哈希过期", sig: sig(3), lastValid: 999, wantStatus: StatusExpired, wantErr: ErrBlockhashExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			got, err := c.Track(tt.sig, tt.lastValid).Wait(ctx)
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if !errors.Is(err, tt.wantErr) && !(err == nil && tt.wantErr == nil) {
+				t.Errorf("Wait() error = %v, want %v", err, tt.wantErr)
+			}
+			if p, ok := c.Lookup(tt.sig); !ok || p.Result().Status != tt.wantStatus {
+				t.Errorf("Lookup() 应返回已确定的结果")
+			}
+		})
+	}
+
+	t.Run("未达到确认级别", func(t *testing.T) {
+		p := c.Track(sig(4), 2_000)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if _, err := p.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("processed 状态不应视为 confirmed, err = %v", err)
+		}
+
+		source.mu.Lock()
+		source.statuses[sig(4)] = &rpc.SignatureStatusesResult{Slot: 9, ConfirmationStatus: rpc.ConfirmationStatusConfirmed}
+		source.mu.Unlock()
+		select {
+		case <-p.Done():
+			if p.Result().Status != StatusLanded || p.Result().Slot != 9 {
+				t.Errorf("Result() = %+v", p.Result())
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("确认级别达到后应得到结果")
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"golang.org/x/time/rate"
+)
+
+// Endpoint RPC 节点地址及其限速
+type Endpoint struct {
+	URL       string
+	RateLimit float64       // 每秒请求数上限，0 表示不限速
+	Timeout   time.Duration // 单次请求超时，0 表示使用 DefaultRequestTimeout
+}
+
+// DefaultRequestTimeout 未配置时单次请求的超时时间
+const DefaultRequestTimeout = 5 * time.Second
+
+const (
+	healthCheckInterval = 10 * time.Second
+	healthAlpha         = 0.2                    // 延迟和错误率的指数移动平均系数
+	unmeasuredLatency   = time.Second            // 尚未测量时假定的延迟，排在已测量的健康节点之后
+	slotPenalty         = 400 * time.Millisecond // 每落后一个 slot 折算的延迟
+	errorPenalty        = 2 * time.Second        // 错误率为 100% 时折算的延迟
+)
+
+// EndpointHealth 节点的健康状况
+type EndpointHealth struct {
+	URL       string
+	Latency   time.Duration // 请求延迟的移动平均
+	ErrorRate float64       // 节点故障(网络错误、HTTP 错误状态)比例的移动平均
+	SlotLag   uint64        // 落后于最快节点的 slot 数
+}
+
+// endpoint 连接池中的一个节点
+type endpoint struct {
+	url     string
+	rpc     *rpc.Client
+	limiter *rate.Limiter
+	timeout time.Duration
+
+	mu        sync.Mutex
+	latency   time.Duration
+	errorRate float64
+	slot      uint64
+}
+
+func newEndpoint(e Endpoint) *endpoint {
+	limit := rate.Inf
+	burst := 1
+	if e.RateLimit > 0 {
+		limit = rate.Limit(e.RateLimit)
+		if burst = int(e.RateLimit); burst < 1 {
+			burst = 1
+		}
+	}
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return &endpoint{
+		url:     e.URL,
+		rpc:     rpc.New(e.URL),
+		limiter: rate.NewLimiter(limit, burst),
+		timeout: timeout,
+	}
+}
+
+// call 在单次请求超时内执行请求，并记录延迟和是否为节点故障(超时计为故障)
+func (e *endpoint) call(ctx context.Context, fn func(ctx context.Context, client *rpc.Client) error) error {
+	start := time.Now()
+	callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	err := fn(callCtx, e.rpc)
+	if ctx.Err() != nil {
+		// 调用方取消，不计入节点健康度
+		return err
+	}
+	fault := endpointFault(err)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	elapsed := time.Since(start)
+	if e.latency == 0 {
+		e.latency = elapsed
+	} else if !fault {
+		e.latency = time.Duration((1-healthAlpha)*float64(e.latency) + healthAlpha*float64(elapsed))
+	}
+	sample := 0.0
+	if fault {
+		sample = 1
+	}
+	e.errorRate = (1-healthAlpha)*e.errorRate + healthAlpha*sample
+	return err
+}
+
+// score 节点得分(折算为延迟)，越低越好
+func (e *endpoint) score(maxSlot uint64) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	latency := e.latency
+	if latency == 0 {
+		latency = unmeasuredLatency
+	}
+	score := latency + time.Duration(e.errorRate*float64(errorPenalty))
+	if maxSlot > e.slot && e.slot > 0 {
+		score += time.Duration(maxSlot-e.slot) * slotPenalty
+	}
+	return score
+}
+
+// endpointFault 判断错误是否由节点本身造成(网络错误、超时、HTTP 错误状态)
+// 节点正常返回的 JSON-RPC 错误(如模拟失败)不影响健康度
+func endpointFault(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr *jsonrpc.HTTPError
+	var netErr net.Error
+	return errors.As(err, &httpErr) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// NewPool 创建使用多个RPC节点的客户端
+// 读请求发往健康度最好的节点并在节点故障时切换，交易广播到所有节点
+func NewPool(ctx context.Context, endpoints ...Endpoint) *Client {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &Client{ctx: ctx, cancel: cancel}
+	for _, e := range endpoints {
+		c.endpoints = append(c.endpoints, newEndpoint(e))
+	}
+	if len(c.endpoints) > 1 {
+		go c.monitor(healthCheckInterval)
+	}
+	return c
+}
+
+// monitor 定期探测所有节点的延迟和 slot，直到客户端关闭
+func (c *Client) monitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.Probe()
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe 并发查询所有节点的 slot，更新延迟、错误率和 slot 落后情况
+func (c *Client) Probe() {
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			var slot uint64
+			err := e.call(c.ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+				slot, err = client.GetSlot(ctx, rpc.CommitmentProcessed)
+				return err
+			})
+			if err == nil {
+				e.mu.Lock()
+				e.slot = slot
+				e.mu.Unlock()
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+// Health 返回所有节点的健康状况，按得分从好到差排序
+func (c *Client) Health() []EndpointHealth {
+	ranked := c.ranked()
+	maxSlot := c.maxSlot()
+	out := make([]EndpointHealth, 0, len(ranked))
+	for _, e := range ranked {
+		e.mu.Lock()
+		h := EndpointHealth{URL: e.url, Latency: e.latency, ErrorRate: e.errorRate}
+		if maxSlot > e.slot && e.slot > 0 {
+			h.SlotLag = maxSlot - e.slot
+		}
+		e.mu.Unlock()
+		out = append(out, h)
+	}
+	return out
+}
+
+func (c *Client) maxSlot() uint64 {
+	var max uint64
+	for _, e := range c.endpoints {
+		e.mu.Lock()
+		if e.slot > max {
+			max = e.slot
+		}
+		e.mu.Unlock()
+	}
+	return max
+}
+
+// ranked 按得分从好到差返回节点
+func (c *Client) ranked() []*endpoint {
+	maxSlot := c.maxSlot()
+	scores := make(map[*endpoint]time.Duration, len(c.endpoints))
+	ranked := make([]*endpoint, len(c.endpoints))
+	copy(ranked, c.endpoints)
+	for _, e := range ranked {
+		scores[e] = e.score(maxSlot)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] < scores[ranked[j]] })
+	return ranked
+}
+
+// Do 在健康度最好且未超出限速的节点上执行读请求，节点故障时依次切换到下一个节点
+// 所有节点都已达到限速时等待最好的节点
+func (c *Client) Do(fn func(ctx context.Context, client *rpc.Client) error) error {
+	ranked := c.ranked()
+	if len(ranked) == 0 {
+		return errors.New("没有可用的RPC节点")
+	}
+	var lastErr error
+	tried := false
+	for _, e := range ranked {
+		if !e.limiter.Allow() {
+			continue
+		}
+		tried = true
+		lastErr = e.call(c.ctx, fn)
+		if !endpointFault(lastErr) {
+			return lastErr
+		}
+	}
+	if tried {
+		return lastErr
+	}
+	if err := ranked[0].limiter.Wait(c.ctx); err != nil {
+		return err
+	}
+	return ranked[0].call(c.ctx, fn)
+}
+
+// broadcast 将已签名的交易同时发送到所有未超出限速的节点，返回第一个成功的结果
+// 全部失败时优先返回节点给出的 JSON-RPC 错误(包含模拟日志)
+func (c *Client) broadcast(tx *solana.Transaction) (solana.Signature, error) {
+	ranked := c.ranked()
+	if len(ranked) == 0 {
+		return solana.Signature{}, errors.New("没有可用的RPC节点")
+	}
+	targets := make([]*endpoint, 0, len(ranked))
+	for _, e := range ranked {
+		if e.limiter.Allow() {
+			targets = append(targets, e)
+		}
+	}
+	if len(targets) == 0 {
+		if err := ranked[0].limiter.Wait(c.ctx); err != nil {
+			return solana.Signature{}, err
+		}
+		targets = ranked[:1]
+	}
+
+	type result struct {
+		sig solana.Signature
+		err error
+	}
+	results := make(chan result, len(targets))
+	for _, e := range targets {
+		go func(e *endpoint) {
+			var sig solana.Signature
+			err := e.call(c.ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+				sig, err = client.SendTransaction(ctx, tx)
+				return err
+			})
+			results <- result{sig: sig, err: err}
+		}(e)
+	}
+
+	var firstErr error
+	for range targets {
+		r := <-results
+		if r.err == nil {
+			return r.sig, nil
+		}
+		if firstErr == nil || (endpointFault(firstErr) && !endpointFault(r.err)) {
+			firstErr = r.err
+		}
+	}
+	return solana.Signature{}, firstErr
+}
@@ -0,0 +1,177 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// stubNode 模拟一个RPC节点，down 时所有请求返回 HTTP 503，delay 为每个请求的响应延迟
+type stubNode struct {
+	*httptest.Server
+	mu    sync.Mutex
+	slot  uint64
+	down  bool
+	delay time.Duration
+	calls map[string]int
+}
+
+func newStubNode(t *testing.T, slot uint64, down bool) *stubNode {
+	n := &stubNode{slot: slot, down: down, calls: make(map[string]int)}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     interface{} `json:"id"`
+			Method string      `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+			return
+		}
+		n.mu.Lock()
+		n.calls[req.Method]++
+		down, delay := n.down, n.delay
+		n.mu.Unlock()
+		time.Sleep(delay)
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "getSlot", "getBlockHeight":
+			result = n.slot
+		case "sendTransaction":
+			result = solana.Signature{1}.String()
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	return n
+}
+
+func (n *stubNode) count(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+func TestPool(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("x"))},
+		solana.Hash{}, solana.TransactionPayer(key.PublicKey()),
+	)
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	if _, err := tx.Sign(func(solana.PublicKey) *solana.PrivateKey { return &key }); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	t.Run("节点故障时切换并降低其排名", func(t *testing.T) {
+		bad, good := newStubNode(t, 100, true), newStubNode(t, 100, false)
+		defer bad.Close()
+		defer good.Close()
+		c := NewPool(context.Background(), Endpoint{URL: bad.URL}, Endpoint{URL: good.URL})
+		defer c.Close()
+
+		for i := 0; i < 3; i++ {
+			if _, err := c.GetBlockHeight(rpc.CommitmentConfirmed); err != nil {
+				t.Fatalf("GetBlockHeight() error = %v", err)
+			}
+		}
+		if health := c.Health(); health[0].URL != good.URL || health[1].ErrorRate == 0 {
+			t.Errorf("Health() = %+v，故障节点应排在后面", health)
+		}
+	})
+
+	t.Run("请求超时计为故障并切换节点", func(t *testing.T) {
+		slow, fast := newStubNode(t, 100, false), newStubNode(t, 100, false)
+		slow.delay = 300 * time.Millisecond
+		defer slow.Close()
+		defer fast.Close()
+		c := NewPool(context.Background(),
+			Endpoint{URL: slow.URL, Timeout: 50 * time.Millisecond},
+			Endpoint{URL: fast.URL, Timeout: 50 * time.Millisecond})
+		defer c.Close()
+
+		start := time.Now()
+		if _, err := c.GetBlockHeight(rpc.CommitmentConfirmed); err != nil {
+			t.Fatalf("GetBlockHeight() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= slow.delay {
+			t.Errorf("GetBlockHeight() 耗时 %v，应在超时后切换节点", elapsed)
+		}
+		if health := c.Health(); health[0].URL != fast.URL || health[1].ErrorRate == 0 {
+			t.Errorf("Health() = %+v，超时的节点应排在后面", health)
+		}
+	})
+
+	t.Run("未测量的节点排在健康节点之后", func(t *testing.T) {
+		measured, fresh := newEndpoint(Endpoint{URL: "http://measured"}), newEndpoint(Endpoint{URL: "http://fresh"})
+		measured.latency = 200 * time.Millisecond
+		if measured.score(0) >= fresh.score(0) {
+			t.Errorf("已测量节点得分 %v 应优于未测量节点 %v", measured.score(0), fresh.score(0))
+		}
+	})
+
+	t.Run("落后的节点排在后面", func(t *testing.T) {
+		behind, ahead := newStubNode(t, 90, false), newStubNode(t, 100, false)
+		defer behind.Close()
+		defer ahead.Close()
+		c := NewPool(context.Background(), Endpoint{URL: behind.URL}, Endpoint{URL: ahead.URL})
+		defer c.Close()
+
+		c.Probe()
+		health := c.Health()
+		if health[0].URL != ahead.URL || health[1].SlotLag != 10 {
+			t.Errorf("Health() = %+v", health)
+		}
+	})
+
+	t.Run("交易广播到所有节点", func(t *testing.T) {
+		a, b, down := newStubNode(t, 100, false), newStubNode(t, 100, false), newStubNode(t, 100, true)
+		defer a.Close()
+		defer b.Close()
+		defer down.Close()
+		c := NewPool(context.Background(), Endpoint{URL: a.URL}, Endpoint{URL: b.URL}, Endpoint{URL: down.URL})
+		defer c.Close()
+
+		if _, err := c.SendTransaction(tx); err != nil {
+			t.Fatalf("SendTransaction() error = %v", err)
+		}
+		// 第一个成功结果返回后其余请求可能仍在进行，关闭服务会等待它们结束
+		c.Close()
+		a.Close()
+		b.Close()
+		down.Close()
+		for _, n := range []*stubNode{a, b, down} {
+			if n.count("sendTransaction") != 1 {
+				t.Errorf("节点 %s 收到 %d 次发送，期望1次", n.URL, n.count("sendTransaction"))
+			}
+		}
+	})
+
+	t.Run("超出限速的节点被跳过", func(t *testing.T) {
+		limited, spare := newStubNode(t, 100, false), newStubNode(t, 100, false)
+		defer limited.Close()
+		defer spare.Close()
+		c := NewPool(context.Background(), Endpoint{URL: limited.URL, RateLimit: 1}, Endpoint{URL: spare.URL, RateLimit: 1})
+		defer c.Close()
+
+		for i := 0; i < 2; i++ {
+			if _, err := c.GetBlockHeight(rpc.CommitmentConfirmed); err != nil {
+				t.Fatalf("GetBlockHeight() error = %v", err)
+			}
+		}
+		if limited.count("getBlockHeight") != 1 || spare.count("getBlockHeight") != 1 {
+			t.Errorf("两个节点应各处理一次请求，实际 %d / %d", limited.count("getBlockHeight"), spare.count("getBlockHeight"))
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package trader
+
+import (
+	"pump_auto/internal/common"
+	"pump_auto/internal/fee"
+	"pump_auto/internal/model"
+)
+
+// Order 买入或卖出请求
+type Order struct {
+	Mint        string
+	Sol         model.Lamports    // 买入花费的SOL
+	Tokens      model.TokenAmount // 卖出的代币数量
+	SellPercent string            // 卖出时为 "100%" 表示全部卖出，此时忽略 Tokens
+	Slippage    int
+	PriorityFee float64 // 固定优先费(SOL)，开启动态优先费时作为没有样本时的兜底
+	Pool        common.PoolType
+	Urgency     fee.Urgency // 紧急程度，决定动态优先费取哪个分位数
+}
+
+// Fill 成交结果
+type Fill struct {
+	Signature string
+	Tokens    model.TokenAmount // 买入获得或卖出的代币数量
+	Sol       model.Lamports    // 买入花费或卖出获得的SOL，实盘卖出时未知则为0
+	Principal model.Lamports    // 买入进入联合曲线的本金，不含手续费、代币账户租金和网络费，未知则为0
+}
+
+// Executor 交易执行接口，Bot 和 TradeExecutor 只通过它下单
+type Executor interface {
+	Buy(order Order) (*Fill, error)
+	Sell(order Order) (*Fill, error)
+	SolBalance() (model.Lamports, error)
+	TokenBalance(mint string) (model.TokenAmount, error)
+}
@@ -0,0 +1,96 @@
+package trader
+
+import (
+	"fmt"
+	"pump_auto/internal/chainTx"
+	"pump_auto/internal/common"
+	"pump_auto/internal/model"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Live 通过 chainTx 在链上真实下单
+type Live struct{}
+
+// NewLive 创建实盘执行器，使用前需先调用 chainTx.Init
+func NewLive() *Live {
+	return &Live{}
+}
+
+// Buy 买入，等待交易确认后解析实际获得的代币数量和花费的SOL
+func (l *Live) Buy(order Order) (*Fill, error) {
+	priorityFee := chainTx.PriorityFee(order.Urgency, order.PriorityFee)
+	sign, err := chainTx.BuyToken(order.Mint, order.Sol, order.Slippage, priorityFee, order.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := chainTx.WaitConfirmation(sign); err != nil {
+		return nil, err
+	}
+	txSig, err := solana.SignatureFromBase58(sign)
+	if err != nil {
+		return nil, fmt.Errorf("无效的交易签名 %s: %v", sign, err)
+	}
+	result, err := chainTx.ParseTx(txSig)
+	if err != nil {
+		return &Fill{Signature: sign, Sol: order.Sol}, fmt.Errorf("解析买入交易失败: %w", err)
+	}
+	if sim, ok := chainTx.Simulated(sign); ok {
+		common.Log.WithFields(logrus.Fields{
+			"mint":      order.Mint,
+			"predicted": sim.TokenDelta,
+			"actual":    result.TokenChange,
+		}).Info("买入成交与预检对比")
+	}
+	fill := &Fill{
+		Signature: sign,
+		Tokens:    model.NewTokenAmount(result.TokensReceived(), result.Decimals),
+		Sol:       model.Lamports(result.SolSpent()),
+	}
+	if result.Event != nil {
+		fill.Principal = model.Lamports(result.Event.SolAmount)
+	}
+	return fill, nil
+}
+
+// Sell 卖出代币并等待交易确认
+func (l *Live) Sell(order Order) (*Fill, error) {
+	priorityFee := chainTx.PriorityFee(order.Urgency, order.PriorityFee)
+	sign, err := chainTx.SellToken(order.Mint, order.Tokens, order.SellPercent, order.Slippage, priorityFee, order.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := chainTx.WaitConfirmation(sign); err != nil {
+		return nil, err
+	}
+	if order.SellPercent == "100%" {
+		go reclaimRent(order.Mint)
+	}
+	return &Fill{Signature: sign, Tokens: order.Tokens}, nil
+}
+
+// reclaimRent 全部卖出后关闭代币账户，失败只记录日志，不影响卖出结果
+func reclaimRent(mint string) {
+	reclaimed, err := chainTx.ReclaimRent(mint)
+	if err != nil {
+		common.Log.WithError(err).WithField("mint", mint).Warn("关闭代币账户失败")
+		return
+	}
+	if reclaimed > 0 {
+		common.Log.WithFields(logrus.Fields{
+			"mint":      mint,
+			"reclaimed": reclaimed.String(),
+		}).Info("已关闭代币账户并回收租金")
+	}
+}
+
+// SolBalance 查询钱包的链上SOL余额
+func (l *Live) SolBalance() (model.Lamports, error) {
+	return chainTx.GetSolBalance()
+}
+
+// TokenBalance 查询钱包的链上代币余额
+func (l *Live) TokenBalance(mint string) (model.TokenAmount, error) {
+	return chainTx.GetTokenBalance(mint)
+}
@@ -0,0 +1,208 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"pump_auto/internal/chainTx"
+	"pump_auto/internal/common"
+	"pump_auto/internal/curve"
+	"pump_auto/internal/model"
+	"pump_auto/internal/pump"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sirupsen/logrus"
+)
+
+// CurveSource 提供代币当前的联合曲线状态
+type CurveSource func(mint string) (*curve.BondingCurve, error)
+
+// SnapshotCurve 使用 pumpportal 推送维护的快照作为曲线状态
+func SnapshotCurve(mint string) (*curve.BondingCurve, error) {
+	snap, ok := pump.GetSnapshot(mint)
+	if !ok {
+		return nil, fmt.Errorf("没有代币 %s 的联合曲线快照，无法模拟成交", mint)
+	}
+	return curve.FromVirtualReserves(snap.VirtualSolReserves, snap.VirtualTokenReserves, snap.Creator), nil
+}
+
+// Paper 模拟盘执行器，按联合曲线报价成交并维护虚拟的SOL和代币账本，
+// 已迁移的代币没有联合曲线可用，按最近已知价格成交
+type Paper struct {
+	source   CurveSource
+	sol      uint64             // lamports
+	tokens   map[string]uint64  // 代币最小单位
+	prices   map[string]float64 // 最近已知价格(SOL/代币)，供迁移后的池子成交使用
+	sequence int
+
+	mutex sync.Mutex
+}
+
+// NewPaper 创建模拟盘执行器，initialSol 为初始虚拟SOL余额
+func NewPaper(initialSol float64, source CurveSource) *Paper {
+	if source == nil {
+		source = SnapshotCurve
+	}
+	return &Paper{
+		source: source,
+		sol:    uint64(model.SOLToLamports(initialSol)),
+		tokens: make(map[string]uint64),
+		prices: make(map[string]float64),
+	}
+}
+
+// Buy 按曲线报价模拟买入，扣除花费和优先费
+func (p *Paper) Buy(order Order) (*Fill, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	quote, err := p.buyQuoteLocked(order)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := quote.SolAmount + uint64(model.SOLToLamports(order.PriorityFee))
+	if cost > p.sol {
+		return nil, fmt.Errorf("模拟盘%w: 需要 %d lamports，剩余 %d lamports", chainTx.ErrInsufficientSOL, cost, p.sol)
+	}
+	p.sol -= cost
+	p.tokens[order.Mint] += quote.TokenAmount
+
+	fill := &Fill{
+		Signature: p.nextSignatureLocked(),
+		Tokens:    model.NewTokenAmount(quote.TokenAmount, pump.TokenDecimals),
+		Sol:       model.Lamports(quote.SolAmount),
+		Principal: model.Lamports(quote.SolAmount - quote.Fee),
+	}
+	p.logFillLocked(common.BUY, order.Mint, fill)
+	return fill, nil
+}
+
+// Sell 按曲线报价模拟卖出，SellPercent 为 "100%" 时卖出全部持仓
+func (p *Paper) Sell(order Order) (*Fill, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	held := p.tokens[order.Mint]
+	amount := order.Tokens.Raw
+	if order.SellPercent == "100%" || amount > held {
+		amount = held
+	}
+	if amount == 0 {
+		return nil, fmt.Errorf("模拟盘没有代币 %s 的持仓: %w", order.Mint, chainTx.ErrInsufficientTokens)
+	}
+	quote, err := p.sellQuoteLocked(order, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := uint64(model.SOLToLamports(order.PriorityFee))
+	p.tokens[order.Mint] = held - amount
+	if p.tokens[order.Mint] == 0 {
+		delete(p.tokens, order.Mint)
+		delete(p.prices, order.Mint)
+	}
+	if quote.SolAmount > fee {
+		p.sol += quote.SolAmount - fee
+	} else if p.sol > fee-quote.SolAmount {
+		p.sol -= fee - quote.SolAmount
+	} else {
+		p.sol = 0
+	}
+
+	fill := &Fill{
+		Signature: p.nextSignatureLocked(),
+		Tokens:    model.NewTokenAmount(amount, pump.TokenDecimals),
+		Sol:       model.Lamports(quote.SolAmount),
+	}
+	p.logFillLocked(common.SELL, order.Mint, fill)
+	return fill, nil
+}
+
+// buyQuoteLocked 计算买入报价，pump 池按曲线报价，迁移后的池子按最近已知价格
+func (p *Paper) buyQuoteLocked(order Order) (curve.Quote, error) {
+	if order.Pool != common.PUMP {
+		price, err := p.priceLocked(order.Mint)
+		if err != nil {
+			return curve.Quote{}, err
+		}
+		sol := uint64(order.Sol)
+		fee := sol * curve.FeeBasisPoints / 10_000
+		tokens := float64(sol-fee) / float64(solana.LAMPORTS_PER_SOL) / price * math.Pow10(pump.TokenDecimals)
+		return curve.Quote{TokenAmount: uint64(tokens), SolAmount: sol, Fee: fee}, nil
+	}
+	c, err := p.source(order.Mint)
+	if err != nil {
+		return curve.Quote{}, err
+	}
+	quote, err := c.BuyQuote(uint64(order.Sol))
+	if err != nil {
+		return curve.Quote{}, fmt.Errorf("计算买入报价失败: %w", err)
+	}
+	p.prices[order.Mint] = c.Price()
+	return quote, nil
+}
+
+// sellQuoteLocked 计算卖出报价，pump 池按曲线报价，迁移后的池子按最近已知价格
+func (p *Paper) sellQuoteLocked(order Order, amount uint64) (curve.Quote, error) {
+	if order.Pool != common.PUMP {
+		price, err := p.priceLocked(order.Mint)
+		if err != nil {
+			return curve.Quote{}, err
+		}
+		gross := uint64(float64(amount) / math.Pow10(pump.TokenDecimals) * price * float64(solana.LAMPORTS_PER_SOL))
+		fee := gross * curve.FeeBasisPoints / 10_000
+		return curve.Quote{TokenAmount: amount, SolAmount: gross - fee, Fee: fee}, nil
+	}
+	c, err := p.source(order.Mint)
+	if err != nil {
+		return curve.Quote{}, err
+	}
+	quote, err := c.SellQuote(amount)
+	if err != nil {
+		return curve.Quote{}, fmt.Errorf("计算卖出报价失败: %w", err)
+	}
+	p.prices[order.Mint] = c.Price()
+	return quote, nil
+}
+
+// priceLocked 迁移后的池子使用的价格，优先取曲线源的最新价格，取不到时用最近一次成交时记录的价格
+func (p *Paper) priceLocked(mint string) (float64, error) {
+	if c, err := p.source(mint); err == nil && c.Price() > 0 {
+		p.prices[mint] = c.Price()
+	}
+	price, ok := p.prices[mint]
+	if !ok || price <= 0 {
+		return 0, fmt.Errorf("%w: 模拟盘没有代币 %s 的已知价格，无法在迁移后的池子成交", chainTx.ErrInvalidRequest, mint)
+	}
+	return price, nil
+}
+
+// SolBalance 返回虚拟账本中的SOL余额
+func (p *Paper) SolBalance() (model.Lamports, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return model.Lamports(p.sol), nil
+}
+
+// TokenBalance 返回虚拟账本中的代币余额
+func (p *Paper) TokenBalance(mint string) (model.TokenAmount, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return model.NewTokenAmount(p.tokens[mint], pump.TokenDecimals), nil
+}
+
+func (p *Paper) nextSignatureLocked() string {
+	p.sequence++
+	return fmt.Sprintf("paper-%d", p.sequence)
+}
+
+func (p *Paper) logFillLocked(action common.TradeAction, mint string, fill *Fill) {
+	common.Log.WithFields(logrus.Fields{
+		"action":      action,
+		"token":       mint,
+		"tokenAmount": fill.Tokens.String(),
+		"solAmount":   fill.Sol.String(),
+		"solBalance":  model.Lamports(p.sol).String(),
+	}).Info("模拟盘成交")
+}
@@ -0,0 +1,101 @@
+package trader
+
+import (
+	"pump_auto/internal/common"
+	"pump_auto/internal/curve"
+	"pump_auto/internal/model"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func initialCurve(string) (*curve.BondingCurve, error) {
+	return curve.FromVirtualReserves(curve.InitialVirtualSolReserves, curve.InitialVirtualTokenReserves, solana.PublicKey{}), nil
+}
+
+func TestPaperBuySell(t *testing.T) {
+	const mint = "7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump"
+	p := NewPaper(1, initialCurve)
+
+	buy, err := p.Buy(Order{Mint: mint, Sol: model.SOLToLamports(0.1), PriorityFee: 0.0005, Pool: common.PUMP})
+	if err != nil {
+		t.Fatalf("Buy() error = %v", err)
+	}
+	if buy.Tokens.IsZero() || buy.Sol > model.SOLToLamports(0.1) {
+		t.Errorf("Buy() = %+v", buy)
+	}
+	if buy.Principal == 0 || buy.Principal >= buy.Sol {
+		t.Errorf("买入本金 = %v, 应小于含手续费的花费 %v", buy.Principal, buy.Sol)
+	}
+
+	sol, _ := p.SolBalance()
+	if want := model.SOLToLamports(1) - buy.Sol - model.SOLToLamports(0.0005); sol != want {
+		t.Errorf("买入后SOL余额 = %v, want %v", sol, want)
+	}
+	tokens, _ := p.TokenBalance(mint)
+	if tokens != buy.Tokens {
+		t.Errorf("买入后代币余额 = %v, want %v", tokens, buy.Tokens)
+	}
+
+	// 部分卖出后再全部卖出
+	half := tokens.MulBps(model.MaxBps / 2)
+	sell, err := p.Sell(Order{Mint: mint, Tokens: half, Pool: common.PUMP})
+	if err != nil {
+		t.Fatalf("Sell() error = %v", err)
+	}
+	if sell.Tokens != half {
+		t.Errorf("部分卖出数量 = %v, want %v", sell.Tokens, half)
+	}
+	if left, _ := p.TokenBalance(mint); left != tokens.Sub(half) {
+		t.Errorf("部分卖出后代币余额 = %v, want %v", left, tokens.Sub(half))
+	}
+	if _, err := p.Sell(Order{Mint: mint, SellPercent: "100%", Pool: common.PUMP}); err != nil {
+		t.Fatalf("Sell(100%%) error = %v", err)
+	}
+	if left, _ := p.TokenBalance(mint); !left.IsZero() {
+		t.Errorf("全部卖出后代币余额 = %v, want 0", left)
+	}
+	if _, err := p.Sell(Order{Mint: mint, SellPercent: "100%", Pool: common.PUMP}); err == nil {
+		t.Error("没有持仓时卖出应返回错误")
+	}
+
+	if _, err := p.Buy(Order{Mint: mint, Sol: model.SOLToLamports(5), Pool: common.PUMP}); err == nil {
+		t.Error("余额不足时买入应返回错误")
+	}
+}
+
+func TestPaperMigratedPool(t *testing.T) {
+	const mint = "7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump"
+	migrated := false
+	source := func(m string) (*curve.BondingCurve, error) {
+		if migrated {
+			return nil, curve.ErrCurveComplete
+		}
+		return initialCurve(m)
+	}
+	p := NewPaper(1, source)
+
+	buy, err := p.Buy(Order{Mint: mint, Sol: model.SOLToLamports(0.1), Pool: common.PUMP})
+	if err != nil {
+		t.Fatalf("Buy() error = %v", err)
+	}
+
+	// 迁移后曲线不可用，按最近已知价格卖出
+	migrated = true
+	if _, err := p.Buy(Order{Mint: "unknown", Sol: model.SOLToLamports(0.1), Pool: common.PUMP_AMM}); err == nil {
+		t.Error("没有已知价格时迁移后的池子买入应返回错误")
+	}
+	sell, err := p.Sell(Order{Mint: mint, SellPercent: "100%", Pool: common.PUMP_AMM})
+	if err != nil {
+		t.Fatalf("迁移后 Sell() error = %v", err)
+	}
+	if sell.Tokens != buy.Tokens {
+		t.Errorf("迁移后卖出数量 = %v, want %v", sell.Tokens, buy.Tokens)
+	}
+	if sell.Sol == 0 || sell.Sol > buy.Sol {
+		t.Errorf("迁移后卖出所得 = %v, 买入花费 %v", sell.Sol, buy.Sol)
+	}
+	if left, _ := p.TokenBalance(mint); !left.IsZero() {
+		t.Errorf("全部卖出后代币余额 = %v, want 0", left)
+	}
+}
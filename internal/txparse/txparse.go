@@ -0,0 +1,238 @@
+package txparse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"pump_auto/internal/common"
+	"pump_auto/internal/pump"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ErrNoPumpTrade 交易中没有找到 pump 买卖指令或成交事件
+var ErrNoPumpTrade = errors.New("交易中没有 pump 买卖指令")
+
+// TradeInstruction 交易中的一条 pump 买卖指令
+type TradeInstruction struct {
+	Action   common.TradeAction
+	Amount   uint64 // 代币数量
+	SolLimit uint64 // 买入最大花费或卖出最少获得
+	Mint     solana.PublicKey
+	User     solana.PublicKey
+	Inner    bool   // 是否为 CPI 调用
+	Index    uint16 // 所在的顶层指令序号
+}
+
+// Result 一笔 pump 交易的结算结果
+type Result struct {
+	Action      common.TradeAction
+	Mint        solana.PublicKey
+	User        solana.PublicKey
+	Instruction *TradeInstruction
+	Event       *pump.TradeEvent // 程序记录的成交事件，旧交易可能没有
+	Failed      bool             // 交易已上链但执行失败
+	Err         interface{}      // 失败原因，来自交易元数据
+
+	Decimals    uint8
+	TokenChange int64  // 用户代币余额变化(最小单位)，买入为正
+	SolChange   int64  // 用户SOL余额变化(lamports)，已包含网络费
+	Fee         uint64 // 网络费(基础费+优先费)，lamports
+}
+
+// TokensReceived 买入获得的代币数量(最小单位)
+func (r *Result) TokensReceived() uint64 {
+	if r.TokenChange <= 0 {
+		return 0
+	}
+	return uint64(r.TokenChange)
+}
+
+// TokensSold 卖出的代币数量(最小单位)
+func (r *Result) TokensSold() uint64 {
+	if r.TokenChange >= 0 {
+		return 0
+	}
+	return uint64(-r.TokenChange)
+}
+
+// SolSpent 买入花费的SOL(lamports)，不含网络费，包含曲线手续费和新建账户的租金
+func (r *Result) SolSpent() uint64 {
+	spent := -r.SolChange - int64(r.Fee)
+	if spent <= 0 {
+		return 0
+	}
+	return uint64(spent)
+}
+
+// SolReceived 卖出获得的SOL(lamports)，不含网络费
+func (r *Result) SolReceived() uint64 {
+	received := r.SolChange + int64(r.Fee)
+	if received <= 0 {
+		return 0
+	}
+	return uint64(received)
+}
+
+// UIAmount 将代币最小单位换算为带精度的数量
+func (r *Result) UIAmount(raw uint64) float64 {
+	return float64(raw) / math.Pow10(int(r.Decimals))
+}
+
+// Parse 在交易的顶层指令和 CPI 中查找 pump 买卖指令和 TradeEvent，
+// 并根据前后余额计算用户实际的代币和SOL变化
+// user 为零值时使用交易付款人，mint 为零值时使用找到的第一条 pump 指令的代币
+func Parse(tx *solana.Transaction, meta *rpc.TransactionMeta, user, mint solana.PublicKey) (*Result, error) {
+	if meta == nil {
+		return nil, fmt.Errorf("交易元数据为空")
+	}
+	keys := accountKeys(tx, meta)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("交易没有账户")
+	}
+	if user.IsZero() {
+		user = keys[0]
+	}
+
+	r := &Result{User: user, Mint: mint, Fee: meta.Fee, Err: meta.Err, Failed: meta.Err != nil}
+
+	// 顶层指令
+	for i, inst := range tx.Message.Instructions {
+		r.observe(keys, inst, uint16(i), false)
+	}
+	// CPI 指令
+	for _, inner := range meta.InnerInstructions {
+		for _, inst := range inner.Instructions {
+			r.observe(keys, inst, inner.Index, true)
+		}
+	}
+
+	if r.Instruction == nil && r.Event == nil {
+		return r, ErrNoPumpTrade
+	}
+	if r.Mint.IsZero() {
+		if r.Instruction != nil {
+			r.Mint = r.Instruction.Mint
+		} else {
+			r.Mint = r.Event.Mint
+		}
+	}
+	switch {
+	case r.Instruction != nil:
+		r.Action = r.Instruction.Action
+	case r.Event.IsBuy:
+		r.Action = common.BUY
+	default:
+		r.Action = common.SELL
+	}
+
+	if err := r.applyBalances(keys, meta); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// observe 记录属于目标用户(和代币)的第一条 pump 指令和成交事件
+func (r *Result) observe(keys []solana.PublicKey, inst solana.CompiledInstruction, index uint16, inner bool) {
+	if int(inst.ProgramIDIndex) >= len(keys) || !keys[inst.ProgramIDIndex].Equals(pump.ProgramID) {
+		return
+	}
+
+	if pump.IsTradeEvent(inst.Data) {
+		if r.Event != nil {
+			return
+		}
+		event, err := pump.DecodeTradeEvent(inst.Data)
+		if err != nil || !event.User.Equals(r.User) || (!r.Mint.IsZero() && !event.Mint.Equals(r.Mint)) {
+			return
+		}
+		r.Event = event
+		return
+	}
+
+	if r.Instruction != nil {
+		return
+	}
+	action, amount, limit, err := pump.DecodeTradeData(inst.Data)
+	if err != nil || len(inst.Accounts) < 7 {
+		return
+	}
+	account := func(i int) solana.PublicKey {
+		if int(inst.Accounts[i]) >= len(keys) {
+			return solana.PublicKey{}
+		}
+		return keys[inst.Accounts[i]]
+	}
+	ti := &TradeInstruction{
+		Action:   action,
+		Amount:   amount,
+		SolLimit: limit,
+		Mint:     account(2),
+		User:     account(6),
+		Inner:    inner,
+		Index:    index,
+	}
+	if !ti.User.Equals(r.User) || (!r.Mint.IsZero() && !ti.Mint.Equals(r.Mint)) {
+		return
+	}
+	r.Instruction = ti
+}
+
+// applyBalances 使用前后余额计算代币和SOL变化
+func (r *Result) applyBalances(keys []solana.PublicKey, meta *rpc.TransactionMeta) error {
+	pre, decimals, err := sumTokenBalance(meta.PreTokenBalances, r.User, r.Mint)
+	if err != nil {
+		return err
+	}
+	post, postDecimals, err := sumTokenBalance(meta.PostTokenBalances, r.User, r.Mint)
+	if err != nil {
+		return err
+	}
+	if postDecimals != 0 {
+		decimals = postDecimals
+	}
+	if decimals == 0 {
+		decimals = pump.TokenDecimals
+	}
+	r.Decimals = decimals
+	r.TokenChange = int64(post) - int64(pre)
+
+	for i, key := range keys {
+		if key.Equals(r.User) {
+			if i >= len(meta.PreBalances) || i >= len(meta.PostBalances) {
+				return fmt.Errorf("交易元数据缺少账户 %s 的SOL余额", r.User)
+			}
+			r.SolChange = int64(meta.PostBalances[i]) - int64(meta.PreBalances[i])
+			return nil
+		}
+	}
+	return fmt.Errorf("交易中没有用户账户 %s", r.User)
+}
+
+func sumTokenBalance(balances []rpc.TokenBalance, owner, mint solana.PublicKey) (uint64, uint8, error) {
+	var total uint64
+	var decimals uint8
+	for _, b := range balances {
+		if b.Owner == nil || !b.Owner.Equals(owner) || !b.Mint.Equals(mint) || b.UiTokenAmount == nil {
+			continue
+		}
+		amount, err := strconv.ParseUint(b.UiTokenAmount.Amount, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("解析代币余额 %q 失败: %w", b.UiTokenAmount.Amount, err)
+		}
+		total += amount
+		decimals = b.UiTokenAmount.Decimals
+	}
+	return total, decimals, nil
+}
+
+// accountKeys 静态账户加上地址查找表加载的账户，顺序与指令中的索引一致
+func accountKeys(tx *solana.Transaction, meta *rpc.TransactionMeta) []solana.PublicKey {
+	keys := make([]solana.PublicKey, 0, len(tx.Message.AccountKeys)+len(meta.LoadedAddresses.Writable)+len(meta.LoadedAddresses.ReadOnly))
+	keys = append(keys, tx.Message.AccountKeys...)
+	keys = append(keys, meta.LoadedAddresses.Writable...)
+	keys = append(keys, meta.LoadedAddresses.ReadOnly...)
+	return keys
+}
@@ -0,0 +1,165 @@
+package txparse
+
+import (
+	"encoding/binary"
+	"errors"
+	"pump_auto/internal/common"
+	"pump_auto/internal/pump"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func encodeEvent(e pump.TradeEvent) []byte {
+	data := append(append([]byte{}, pump.EventInstructionTag...), pump.TradeEventDiscriminator...)
+	body := make([]byte, 121+32) // 附加新版程序的尾部字段
+	copy(body[0:], e.Mint[:])
+	binary.LittleEndian.PutUint64(body[32:], e.SolAmount)
+	binary.LittleEndian.PutUint64(body[40:], e.TokenAmount)
+	if e.IsBuy {
+		body[48] = 1
+	}
+	copy(body[49:], e.User[:])
+	binary.LittleEndian.PutUint64(body[81:], uint64(e.Timestamp))
+	binary.LittleEndian.PutUint64(body[89:], e.VirtualSolReserves)
+	binary.LittleEndian.PutUint64(body[97:], e.VirtualTokenReserves)
+	return append(data, body...)
+}
+
+func indexOf(keys []solana.PublicKey, key solana.PublicKey) uint16 {
+	for i, k := range keys {
+		if k.Equals(key) {
+			return uint16(i)
+		}
+	}
+	panic("账户不存在")
+}
+
+func tokenBalance(index uint16, owner, mint solana.PublicKey, amount string) rpc.TokenBalance {
+	return rpc.TokenBalance{
+		AccountIndex:  index,
+		Owner:         &owner,
+		Mint:          mint,
+		UiTokenAmount: &rpc.UiTokenAmount{Amount: amount, Decimals: 6},
+	}
+}
+
+func TestParse(t *testing.T) {
+	user, _ := solana.NewRandomPrivateKey()
+	creator, _ := solana.NewRandomPrivateKey()
+	params := pump.TradeParams{
+		Mint:        solana.MustPublicKeyFromBase58("7kXwmx81UteinNHkCBRfVdZfiwMG8oyak824zUPDpump"),
+		Creator:     creator.PublicKey(),
+		User:        user.PublicKey(),
+		TokenAmount: 5_000_000,
+		SolLimit:    1_100_000,
+	}
+	buyTx, err := pump.BuildBuyTransaction(params, solana.Hash{})
+	if err != nil {
+		t.Fatalf("BuildBuyTransaction() error = %v", err)
+	}
+	keys := buyTx.Message.AccountKeys
+	programIndex := indexOf(keys, pump.ProgramID)
+	event := solana.CompiledInstruction{
+		ProgramIDIndex: programIndex,
+		Data: encodeEvent(pump.TradeEvent{
+			Mint: params.Mint, User: params.User, IsBuy: true,
+			SolAmount: 1_000_000, TokenAmount: 4_900_000,
+		}),
+	}
+	buyIndex := len(buyTx.Message.Instructions) - 1
+	buyInstruction := buyTx.Message.Instructions[buyIndex]
+
+	balances := func(m *rpc.TransactionMeta) *rpc.TransactionMeta {
+		ata := indexOf(keys, func() solana.PublicKey {
+			a, _, _ := solana.FindAssociatedTokenAddress(params.User, params.Mint)
+			return a
+		}())
+		m.Fee = 505_000
+		m.PreBalances = make([]uint64, len(keys))
+		m.PostBalances = make([]uint64, len(keys))
+		m.PreBalances[0] = 10_000_000
+		m.PostBalances[0] = 10_000_000 - 505_000 - 1_012_500 - 2_039_280
+		m.PostTokenBalances = []rpc.TokenBalance{tokenBalance(ata, params.User, params.Mint, "4900000")}
+		return m
+	}
+
+	t.Run("顶层指令", func(t *testing.T) {
+		meta := balances(&rpc.TransactionMeta{
+			InnerInstructions: []rpc.InnerInstruction{{Index: uint16(buyIndex), Instructions: []solana.CompiledInstruction{event}}},
+		})
+		r, err := Parse(buyTx, meta, solana.PublicKey{}, solana.PublicKey{})
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if r.Action != common.BUY || !r.Mint.Equals(params.Mint) || r.Instruction == nil || r.Instruction.Inner {
+			t.Errorf("Parse() = %+v", r)
+		}
+		if r.Event == nil || r.Event.TokenAmount != 4_900_000 {
+			t.Errorf("未解析到 TradeEvent: %+v", r.Event)
+		}
+		if r.TokensReceived() != 4_900_000 {
+			t.Errorf("TokensReceived() = %d, want 4900000", r.TokensReceived())
+		}
+		if r.SolSpent() != 1_012_500+2_039_280 {
+			t.Errorf("SolSpent() = %d, want %d", r.SolSpent(), 1_012_500+2_039_280)
+		}
+		if r.UIAmount(r.TokensReceived()) != 4.9 {
+			t.Errorf("UIAmount() = %v, want 4.9", r.UIAmount(r.TokensReceived()))
+		}
+	})
+
+	t.Run("CPI调用", func(t *testing.T) {
+		// 顶层只保留计算预算，pump 买入和事件都作为第0条指令的 CPI
+		wrapped := *buyTx
+		wrapped.Message.Instructions = buyTx.Message.Instructions[:1]
+		meta := balances(&rpc.TransactionMeta{
+			InnerInstructions: []rpc.InnerInstruction{{Index: 0, Instructions: []solana.CompiledInstruction{buyInstruction, event}}},
+		})
+		r, err := Parse(&wrapped, meta, params.User, params.Mint)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if r.Instruction == nil || !r.Instruction.Inner || r.Instruction.Amount != params.TokenAmount {
+			t.Errorf("未在 CPI 中找到买入指令: %+v", r.Instruction)
+		}
+		if r.TokensReceived() != 4_900_000 {
+			t.Errorf("TokensReceived() = %d", r.TokensReceived())
+		}
+	})
+
+	t.Run("卖出", func(t *testing.T) {
+		sellTx, err := pump.BuildSellTransaction(params, solana.Hash{})
+		if err != nil {
+			t.Fatalf("BuildSellTransaction() error = %v", err)
+		}
+		sellKeys := sellTx.Message.AccountKeys
+		ata, _, _ := solana.FindAssociatedTokenAddress(params.User, params.Mint)
+		meta := &rpc.TransactionMeta{
+			Fee:               5_000,
+			PreBalances:       make([]uint64, len(sellKeys)),
+			PostBalances:      make([]uint64, len(sellKeys)),
+			PreTokenBalances:  []rpc.TokenBalance{tokenBalance(indexOf(sellKeys, ata), params.User, params.Mint, "4900000")},
+			PostTokenBalances: []rpc.TokenBalance{tokenBalance(indexOf(sellKeys, ata), params.User, params.Mint, "0")},
+		}
+		meta.PreBalances[0] = 1_000_000
+		meta.PostBalances[0] = 1_000_000 - 5_000 + 900_000
+		r, err := Parse(sellTx, meta, params.User, params.Mint)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if r.Action != common.SELL || r.TokensSold() != 4_900_000 || r.SolReceived() != 900_000 {
+			t.Errorf("Parse() 卖出结果错误: action=%s sold=%d received=%d", r.Action, r.TokensSold(), r.SolReceived())
+		}
+	})
+
+	t.Run("没有pump指令", func(t *testing.T) {
+		plain := *buyTx
+		plain.Message.Instructions = buyTx.Message.Instructions[:1]
+		_, err := Parse(&plain, balances(&rpc.TransactionMeta{}), params.User, params.Mint)
+		if !errors.Is(err, ErrNoPumpTrade) {
+			t.Errorf("Parse() error = %v, want ErrNoPumpTrade", err)
+		}
+	})
+}
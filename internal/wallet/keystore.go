@@ -0,0 +1,200 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+	kdfScrypt       = "scrypt"
+	cipherAESGCM    = "aes-256-gcm"
+
+	// 默认 scrypt 参数，约占用 64MB 内存
+	defaultScryptN = 1 << 16
+	defaultScryptR = 8
+	defaultScryptP = 1
+	scryptKeyLen   = 32
+	saltLen        = 32
+
+	// 解密时允许的 scrypt 参数上限，防止构造的文件在启动时耗尽内存或CPU
+	maxScryptN = defaultScryptN
+	maxScryptR = 32
+	maxScryptP = 16
+)
+
+// Keystore 口令加密的私钥文件格式
+type Keystore struct {
+	Version    int          `json:"version"`
+	Address    string       `json:"address"` // 明文公钥，仅用于识别和解密后校验
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfParams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`      // hex
+	Ciphertext string       `json:"ciphertext"` // hex，包含 GCM 认证标签
+}
+
+// ScryptParams scrypt 密钥派生参数
+type ScryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"` // hex
+}
+
+// EncryptKeystore 使用口令加密私钥，返回可直接写入文件的 JSON
+func EncryptKeystore(key solana.PrivateKey, passphrase string) ([]byte, error) {
+	w, err := FromPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptKeystoreFromWallet(w, passphrase)
+}
+
+// EncryptKeystoreFromWallet 使用口令加密已加载的钱包
+func EncryptKeystoreFromWallet(w *Wallet, passphrase string) ([]byte, error) {
+	return encryptKeystore(w, passphrase, defaultScryptN, defaultScryptR, defaultScryptP)
+}
+
+func encryptKeystore(w *Wallet, passphrase string, n, r, p int) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("keystore口令不能为空")
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成salt失败: %w", err)
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	gcm, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成nonce失败: %w", err)
+	}
+	address := w.PublicKey().String()
+	ciphertext := gcm.Seal(nil, nonce, w.privateKey, []byte(address))
+
+	ks := Keystore{
+		Version: keystoreVersion,
+		Address: address,
+		KDF:     kdfScrypt,
+		KDFParams: ScryptParams{
+			N:    n,
+			R:    r,
+			P:    p,
+			Salt: hex.EncodeToString(salt),
+		},
+		Cipher:     cipherAESGCM,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(ciphertext),
+	}
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// DecryptKeystore 使用口令解密 keystore 内容
+func DecryptKeystore(content []byte, passphrase string) (*Wallet, error) {
+	var ks Keystore
+	if err := json.Unmarshal(content, &ks); err != nil {
+		return nil, fmt.Errorf("解析keystore失败: %w", err)
+	}
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("不支持的keystore版本: %d", ks.Version)
+	}
+	if ks.KDF != kdfScrypt || ks.Cipher != cipherAESGCM {
+		return nil, fmt.Errorf("不支持的keystore算法: kdf=%s cipher=%s", ks.KDF, ks.Cipher)
+	}
+
+	if err := ks.KDFParams.check(); err != nil {
+		return nil, err
+	}
+
+	salt, err := hex.DecodeString(ks.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore salt格式错误: %w", err)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keystore nonce格式错误: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("keystore ciphertext格式错误: %w", err)
+	}
+
+	derived, err := scrypt.Key([]byte(passphrase), salt, ks.KDFParams.N, ks.KDFParams.R, ks.KDFParams.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	gcm, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("keystore nonce长度错误")
+	}
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(ks.Address))
+	if err != nil {
+		return nil, fmt.Errorf("解密keystore失败，口令错误或文件已损坏")
+	}
+
+	w, err := FromPrivateKey(solana.PrivateKey(plain))
+	if err != nil {
+		return nil, err
+	}
+	if w.PublicKey().String() != ks.Address {
+		return nil, fmt.Errorf("keystore地址 %s 与私钥推导的公钥 %s 不一致", ks.Address, w.PublicKey())
+	}
+	return w, nil
+}
+
+// LoadKeystore 读取并解密 keystore 文件
+func LoadKeystore(path string, passphrase string) (*Wallet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取keystore文件失败: %w", err)
+	}
+	return DecryptKeystore(content, passphrase)
+}
+
+// check 校验 scrypt 参数在允许范围内
+func (p ScryptParams) check() error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("keystore scrypt参数 n=%d 必须是大于1的2的幂", p.N)
+	}
+	if p.N > maxScryptN {
+		return fmt.Errorf("keystore scrypt参数 n=%d 超过上限 %d", p.N, maxScryptN)
+	}
+	if p.R <= 0 || p.R > maxScryptR {
+		return fmt.Errorf("keystore scrypt参数 r=%d 必须在 1-%d 之间", p.R, maxScryptR)
+	}
+	if p.P <= 0 || p.P > maxScryptP {
+		return fmt.Errorf("keystore scrypt参数 p=%d 必须在 1-%d 之间", p.P, maxScryptP)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建AES加密器失败: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建GCM失败: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Signer 交易签名者，chainTx 只通过该接口使用钱包
+type Signer interface {
+	PublicKey() solana.PublicKey
+	SignTransaction(tx *solana.Transaction) error
+}
+
+// Wallet 持有单个 ed25519 私钥的本地钱包
+type Wallet struct {
+	privateKey solana.PrivateKey
+	publicKey  solana.PublicKey
+}
+
+// FromPrivateKey 由私钥创建钱包，公钥始终从私钥种子重新推导
+func FromPrivateKey(key solana.PrivateKey) (*Wallet, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("私钥长度错误，期望 %d 字节，实际 %d 字节", ed25519.PrivateKeySize, len(key))
+	}
+
+	// Solana CLI 的 keypair 是 种子(32字节)+公钥(32字节)，后半部分可能被篡改或损坏
+	derived := ed25519.NewKeyFromSeed(key[:ed25519.SeedSize])
+	if !bytes.Equal(derived[ed25519.SeedSize:], key[ed25519.SeedSize:]) {
+		return nil, fmt.Errorf("私钥中的公钥部分与种子推导结果不一致")
+	}
+
+	var pub solana.PublicKey
+	copy(pub[:], derived[ed25519.SeedSize:])
+	return &Wallet{
+		privateKey: solana.PrivateKey(derived),
+		publicKey:  pub,
+	}, nil
+}
+
+// LoadKeypairFile 加载 Solana CLI 生成的 id.json 字节数组 keypair 文件
+func LoadKeypairFile(path string) (*Wallet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取keypair文件失败: %w", err)
+	}
+	key, err := solana.PrivateKeyFromSolanaKeygenFileBytes(content)
+	if err != nil {
+		return nil, fmt.Errorf("解析keypair文件 %s 失败: %w", path, err)
+	}
+	return FromPrivateKey(key)
+}
+
+// PublicKey 返回钱包公钥
+func (w *Wallet) PublicKey() solana.PublicKey {
+	return w.publicKey
+}
+
+// SignTransaction 使用钱包私钥签名交易，交易中若有其他签名者则返回错误
+func (w *Wallet) SignTransaction(tx *solana.Transaction) error {
+	_, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key.Equals(w.publicKey) {
+			return &w.privateKey
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("签名交易失败: %w", err)
+	}
+	return nil
+}
+
+// Load 加载钱包，keypair 文件与 keystore 二选一
+func Load(keypairPath, keystorePath, passphrase string) (*Wallet, error) {
+	switch {
+	case keypairPath != "":
+		return LoadKeypairFile(keypairPath)
+	case keystorePath != "":
+		return LoadKeystore(keystorePath, passphrase)
+	default:
+		return nil, fmt.Errorf("未配置钱包，请设置 wallet.keypairPath 或 wallet.keystorePath")
+	}
+}
@@ -0,0 +1,160 @@
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func writeKeypair(t *testing.T, key []byte) string {
+	t.Helper()
+	values := make([]int, len(key))
+	for i, b := range key {
+		values[i] = int(b)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		t.Fatalf("序列化keypair失败: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "id.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("写入keypair失败: %v", err)
+	}
+	return path
+}
+
+func TestLoadKeypairFile(t *testing.T) {
+	key, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+
+	// 公钥部分被替换成另一个钱包的公钥
+	other, _ := solana.NewRandomPrivateKey()
+	mismatched := make([]byte, 64)
+	copy(mismatched, key[:32])
+	copy(mismatched[32:], other[32:])
+
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{name: "正常keypair", key: key},
+		{name: "公钥与种子不一致", key: mismatched, wantErr: true},
+		{name: "长度错误", key: key[:32], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := LoadKeypairFile(writeKeypair(t, tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKeypairFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !w.PublicKey().Equals(key.PublicKey()) {
+				t.Errorf("PublicKey() = %s, want %s", w.PublicKey(), key.PublicKey())
+			}
+		})
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	key, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	w, err := FromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FromPrivateKey() error = %v", err)
+	}
+
+	// 测试中使用较小的 scrypt 参数
+	data, err := encryptKeystore(w, "correct horse", 1<<10, 8, 1)
+	if err != nil {
+		t.Fatalf("encryptKeystore() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{name: "口令正确", passphrase: "correct horse"},
+		{name: "口令错误", passphrase: "wrong", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptKeystore(data, tt.passphrase)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecryptKeystore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.PublicKey().Equals(w.PublicKey()) {
+				t.Errorf("解密后公钥 = %s, want %s", got.PublicKey(), w.PublicKey())
+			}
+		})
+	}
+}
+
+func TestDecryptKeystoreRejectsScryptParams(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	w, err := FromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FromPrivateKey() error = %v", err)
+	}
+	data, err := encryptKeystore(w, "pass", 1<<10, 8, 1)
+	if err != nil {
+		t.Fatalf("encryptKeystore() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params ScryptParams
+	}{
+		{name: "n超过上限", params: ScryptParams{N: 1 << 30, R: 8, P: 1}},
+		{name: "n不是2的幂", params: ScryptParams{N: 1000, R: 8, P: 1}},
+		{name: "r为0", params: ScryptParams{N: 1 << 10, R: 0, P: 1}},
+		{name: "p为负数", params: ScryptParams{N: 1 << 10, R: 8, P: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ks Keystore
+			if err := json.Unmarshal(data, &ks); err != nil {
+				t.Fatalf("解析keystore失败: %v", err)
+			}
+			tt.params.Salt = ks.KDFParams.Salt
+			ks.KDFParams = tt.params
+			tampered, _ := json.Marshal(ks)
+			if _, err := DecryptKeystore(tampered, "pass"); err == nil {
+				t.Errorf("DecryptKeystore() 应拒绝 scrypt 参数 %+v", tt.params)
+			}
+		})
+	}
+}
+
+func TestSignTransaction(t *testing.T) {
+	key, _ := solana.NewRandomPrivateKey()
+	w, err := FromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FromPrivateKey() error = %v", err)
+	}
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("hi"))},
+		solana.Hash{},
+		solana.TransactionPayer(w.PublicKey()),
+	)
+	if err != nil {
+		t.Fatalf("构造交易失败: %v", err)
+	}
+	if err := w.SignTransaction(tx); err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Errorf("签名校验失败: %v", err)
+	}
+}
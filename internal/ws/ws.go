@@ -61,7 +61,18 @@ func connectAndSubscribe() error {
 		return fmt.Errorf("发送订阅请求失败: %w", err)
 	}
 
-	log.Println("成功连接到pumpportal.fun WebSocket API并订阅新代币事件")
+	// 订阅代币迁移事件，持仓代币迁移出联合曲线后改走AMM池
+	data, err = json.Marshal(map[string]interface{}{
+		"method": "subscribeMigration",
+	})
+	if err != nil {
+		return fmt.Errorf("序列化订阅请求失败: %w", err)
+	}
+	if err := ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("发送迁移订阅请求失败: %w", err)
+	}
+
+	log.Println("成功连接到pumpportal.fun WebSocket API并订阅新代币和迁移事件")
 	return nil
 }
 